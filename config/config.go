@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -66,3 +69,103 @@ func (c *Config) IsDevEnv() bool {
 func (c *Config) IsTestEnv() bool {
 	return c.AppEnv() == AppEnvTest
 }
+
+// Override returns a copy of the [Config], where the settings of the target key are replaced by the settings of the
+// source key. For example, Override("modules.http.server", "modules.http.servers.admin") returns a [Config] where
+// modules.http.server resolves the modules.http.servers.admin settings, including their env var substitution
+// (MODULES_HTTP_SERVERS_ADMIN_*).
+func (c *Config) Override(target string, source string) (*Config, error) {
+	target = strings.ToLower(target)
+	source = strings.ToLower(source)
+
+	// settings are resolved from all the settings, since the env placeholders expansion overrides some keys
+	settings := c.AllSettings()
+
+	sourceSettings, ok := lookupSettings(settings, source)
+	if !ok {
+		return nil, fmt.Errorf("invalid configuration for key %s", source)
+	}
+
+	keys := strings.Split(target, ".")
+
+	current := settings
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+
+		current = next
+	}
+
+	current[keys[len(keys)-1]] = sourceSettings
+
+	v := viper.NewWithOptions(viper.EnvKeyReplacer(newOverrideEnvKeyReplacer(target, source)))
+	v.AutomaticEnv()
+
+	err := v.MergeConfigMap(settings)
+	if err != nil {
+		return nil, fmt.Errorf("cannot override configuration key %s with %s: %w", target, source, err)
+	}
+
+	return &Config{v}, nil
+}
+
+// SubKeys returns the sorted keys of the settings of a key, for example the names of the modules.http.servers entries
+// to [Config.Override] modules.http.server with. It returns nil if the key is not set or has no sub keys.
+func (c *Config) SubKeys(key string) []string {
+	// settings are resolved from all the settings, since the env placeholders expansion overrides some keys
+	settings, ok := lookupSettings(c.AllSettings(), strings.ToLower(key))
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(settings))
+	for subKey := range settings {
+		keys = append(keys, subKey)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// lookupSettings returns the settings map of a dotted key, and false if the key is not set or not a map.
+func lookupSettings(settings map[string]any, key string) (map[string]any, bool) {
+	var current any = settings
+	for _, k := range strings.Split(key, ".") {
+		currentMap, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current = currentMap[k]
+	}
+
+	currentMap, ok := current.(map[string]any)
+
+	return currentMap, ok
+}
+
+// overrideEnvKeyReplacer resolves the env vars of an overridden key from the env vars of its source key.
+type overrideEnvKeyReplacer struct {
+	target string
+	source string
+}
+
+func newOverrideEnvKeyReplacer(target string, source string) *overrideEnvKeyReplacer {
+	return &overrideEnvKeyReplacer{
+		target: strings.ToUpper(target) + ".",
+		source: strings.ToUpper(source) + ".",
+	}
+}
+
+// Replace returns the env var name of a configuration key.
+func (r *overrideEnvKeyReplacer) Replace(key string) string {
+	if strings.HasPrefix(key, r.target) {
+		key = r.source + strings.TrimPrefix(key, r.target)
+	}
+
+	return envKeyReplacer.Replace(key)
+}
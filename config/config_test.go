@@ -229,3 +229,74 @@ func createTestConfig() (*config.Config, error) {
 		config.WithFilePaths("./testdata/config/valid"),
 	)
 }
+
+func TestOverride(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	overriddenCfg, err := cfg.Override("config.target", "config.sources.foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "foo", overriddenCfg.GetString("config.target.value"))
+	assert.Equal(t, "other", overriddenCfg.GetString("config.target.other"))
+	assert.Equal(t, "default-app", overriddenCfg.AppName())
+
+	assert.Equal(t, "target", cfg.GetString("config.target.value"))
+	assert.Equal(t, "", cfg.GetString("config.target.other"))
+}
+
+func TestOverrideWithEnvVarPlaceholder(t *testing.T) {
+	t.Setenv("BAR", "bar")
+
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	overriddenCfg, err := cfg.Override("config.target", "config.sources.foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "foo-bar", overriddenCfg.GetString("config.target.placeholder"))
+	assert.Equal(t, "foo", overriddenCfg.GetString("config.target.value"))
+}
+
+func TestOverrideWithEnvVarSubstitution(t *testing.T) {
+	t.Setenv("CONFIG_SOURCES_FOO_VALUE", "bar")
+	t.Setenv("CONFIG_TARGET_OTHER", "ignored")
+
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	overriddenCfg, err := cfg.Override("config.target", "config.sources.foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "bar", overriddenCfg.GetString("config.target.value"))
+	assert.Equal(t, "other", overriddenCfg.GetString("config.target.other"))
+}
+
+func TestOverrideWithNewTarget(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	overriddenCfg, err := cfg.Override("other.target", "config.sources.foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "foo", overriddenCfg.GetString("other.target.value"))
+}
+
+func TestOverrideWithInvalidSource(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	_, err = cfg.Override("config.target", "config.sources.invalid")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid configuration for key config.sources.invalid", err.Error())
+}
+
+func TestSubKeys(t *testing.T) {
+	cfg, err := createTestConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"bar", "foo"}, cfg.SubKeys("config.sources"))
+	assert.Equal(t, []string{"bar", "foo"}, cfg.SubKeys("CONFIG.SOURCES"))
+	assert.Nil(t, cfg.SubKeys("config.target.value"))
+	assert.Nil(t, cfg.SubKeys("config.invalid"))
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/spf13/viper"
 )
 
+// envKeyReplacer resolves the env var name of a configuration key (ex: app.name => APP_NAME).
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
 // ConfigFactory is the interface for [Config] factories.
 type ConfigFactory interface {
 	Create(options ...ConfigOption) (*Config, error)
@@ -41,7 +44,7 @@ func (f *DefaultConfigFactory) Create(options ...ConfigOption) (*Config, error)
 
 	v := viper.New()
 
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.SetEnvKeyReplacer(envKeyReplacer)
 	v.AutomaticEnv()
 	v.SetConfigName(appliedOptions.FileName)
 	for _, path := range appliedOptions.FilePaths {
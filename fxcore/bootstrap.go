@@ -2,7 +2,9 @@ package fxcore
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/fxlog"
 	"go.uber.org/fx"
@@ -13,8 +15,29 @@ import (
 //
 //nolint:containedctx
 type Bootstrapper struct {
-	context context.Context
-	options []fx.Option
+	context     context.Context
+	options     []fx.Option
+	stopTimeout time.Duration
+}
+
+// fxShutdownDrain registers the [ShutdownOrchestrator] drain as the last lifecycle hook, to be executed first on stop.
+// The drain period must be lower than the application stop timeout, to leave time to the modules to stop.
+func fxShutdownDrain(stopTimeout time.Duration) fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle, orchestrator *ShutdownOrchestrator) error {
+		if orchestrator.Enabled() && orchestrator.DrainPeriod() >= stopTimeout {
+			return fmt.Errorf(
+				"shutdown drain period %s must be lower than the stop timeout %s",
+				orchestrator.DrainPeriod(),
+				stopTimeout,
+			)
+		}
+
+		lc.Append(fx.Hook{
+			OnStop: orchestrator.Shutdown,
+		})
+
+		return nil
+	})
 }
 
 // NewBootstrapper returns a new [Bootstrapper].
@@ -24,6 +47,7 @@ func NewBootstrapper() *Bootstrapper {
 		options: []fx.Option{
 			FxCoreModule,
 		},
+		stopTimeout: fx.DefaultTimeout,
 	}
 }
 
@@ -34,6 +58,13 @@ func (b *Bootstrapper) WithContext(ctx context.Context) *Bootstrapper {
 	return b
 }
 
+// WithStopTimeout is used to configure the application stop timeout (default [fx.DefaultTimeout]).
+func (b *Bootstrapper) WithStopTimeout(timeout time.Duration) *Bootstrapper {
+	b.stopTimeout = timeout
+
+	return b
+}
+
 // WithOptions is used to pass a list of [fx.Option].
 func (b *Bootstrapper) WithOptions(options ...fx.Option) *Bootstrapper {
 	b.options = append(b.options, options...)
@@ -48,6 +79,8 @@ func (b *Bootstrapper) BootstrapApp(options ...fx.Option) *fx.App {
 		fx.WithLogger(fxlog.NewFxEventLogger),
 		fx.Options(b.options...),
 		fx.Options(options...),
+		fx.StopTimeout(b.stopTimeout),
+		fxShutdownDrain(b.stopTimeout),
 	)
 }
 
@@ -63,6 +96,8 @@ func (b *Bootstrapper) BootstrapTestApp(tb testing.TB, options ...fx.Option) *fx
 		fx.NopLogger,
 		fx.Options(b.options...),
 		fx.Options(options...),
+		fx.StopTimeout(b.stopTimeout),
+		fxShutdownDrain(b.stopTimeout),
 	)
 }
 
@@ -3,6 +3,7 @@ package fxcore_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxcore"
@@ -272,3 +273,37 @@ func TestRunTestAppWithOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestBootstrapAppWithStopTimeout(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SHUTDOWN_ENABLED", "true")
+	t.Setenv("SHUTDOWN_DRAIN", "10ms")
+
+	var orchestrator *fxcore.ShutdownOrchestrator
+
+	app := fxcore.NewBootstrapper().WithStopTimeout(time.Second).BootstrapApp(fx.Populate(&orchestrator))
+
+	assert.Equal(t, time.Second, app.StopTimeout())
+
+	ctx := context.Background()
+
+	err := app.Start(ctx)
+	assert.NoError(t, err)
+
+	err = app.Stop(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 10*time.Millisecond, orchestrator.DrainPeriod())
+	assert.Equal(t, fxcore.ShutdownStopped, orchestrator.Phase())
+}
+
+func TestBootstrapAppWithDrainAboveStopTimeout(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SHUTDOWN_ENABLED", "true")
+	t.Setenv("SHUTDOWN_DRAIN", "10s")
+
+	app := fxcore.NewBootstrapper().WithStopTimeout(5 * time.Second).BootstrapApp()
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "shutdown drain period 10s must be lower than the stop timeout 5s")
+}
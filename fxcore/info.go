@@ -59,13 +59,15 @@ type FxCoreModuleInfo struct {
 	TraceProcessor string
 	TraceSampler   string
 	ExtraInfos     map[string]string
+	Maintenance    *Maintenance
 }
 
 // FxCoreModuleInfoParam allows injection of the required dependencies in [NewFxCoreModuleInfo].
 type FxCoreModuleInfoParam struct {
 	fx.In
-	Config     *config.Config
-	ExtraInfos []FxExtraInfo `group:"core-extra-infos"`
+	Config      *config.Config
+	ExtraInfos  []FxExtraInfo `group:"core-extra-infos"`
+	Maintenance *Maintenance  `optional:"true"`
 }
 
 // NewFxCoreModuleInfo returns a new [FxCoreModuleInfo].
@@ -103,6 +105,7 @@ func NewFxCoreModuleInfo(p FxCoreModuleInfoParam) *FxCoreModuleInfo {
 		TraceProcessor: traceProcessor,
 		TraceSampler:   traceSampler,
 		ExtraInfos:     extraInfos,
+		Maintenance:    p.Maintenance,
 	}
 }
 
@@ -113,7 +116,7 @@ func (i *FxCoreModuleInfo) Name() string {
 
 // Data return the data of the module info.
 func (i *FxCoreModuleInfo) Data() map[string]interface{} {
-	return map[string]interface{}{
+	data := map[string]interface{}{
 		"app": map[string]interface{}{
 			"name":        i.AppName,
 			"description": i.AppDescription,
@@ -131,6 +134,12 @@ func (i *FxCoreModuleInfo) Data() map[string]interface{} {
 		},
 		"extra": i.ExtraInfos,
 	}
+
+	if i.Maintenance != nil {
+		data["maintenance"] = i.Maintenance.Status()
+	}
+
+	return data
 }
 
 // FxModuleInfoRegistry is the registry collecting info about registered modules.
@@ -0,0 +1,324 @@
+package fxcore
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	MaintenanceProbeName          = "maintenance"
+	DefaultMaintenanceRetryAfter  = 60
+	DefaultMaintenanceMessage     = "service under maintenance"
+	DefaultMaintenanceContentType = echo.MIMEApplicationJSON
+)
+
+// MaintenanceStatus is the status of the [Maintenance] mode.
+type MaintenanceStatus struct {
+	Active bool       `json:"active"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// MaintenanceRequest is the request payload to toggle the [Maintenance] mode.
+// The Duration is in seconds, 0 meaning no expiry.
+type MaintenanceRequest struct {
+	Enabled  bool `form:"enabled" json:"enabled"`
+	Duration int  `form:"duration" json:"duration"`
+}
+
+// Maintenance holds the application maintenance mode state.
+type Maintenance struct {
+	mutex  sync.RWMutex
+	active bool
+	since  time.Time
+	until  time.Time
+}
+
+// NewMaintenance returns a new [Maintenance], inactive by default.
+func NewMaintenance() *Maintenance {
+	return &Maintenance{}
+}
+
+// FxMaintenanceParam allows injection of the required dependencies in [NewFxMaintenance].
+type FxMaintenanceParam struct {
+	fx.In
+	Config  *config.Config
+	Checker *healthcheck.Checker
+}
+
+// NewFxMaintenance returns a new [Maintenance], and registers its readiness probe if enabled.
+func NewFxMaintenance(p FxMaintenanceParam) *Maintenance {
+	maintenance := NewMaintenance()
+
+	if p.Config.GetBool("modules.core.maintenance.enabled") {
+		p.Checker.RegisterProbe(NewMaintenanceProbe(maintenance), healthcheck.Readiness)
+	}
+
+	return maintenance
+}
+
+// NewFxMaintenanceHttpMiddlewares returns the [MaintenanceMiddleware] if the maintenance mode is enabled, to be
+// applied by fxhttpserver on its http servers.
+func NewFxMaintenanceHttpMiddlewares(config *config.Config, maintenance *Maintenance) []any {
+	if !config.GetBool("modules.core.maintenance.enabled") {
+		return nil
+	}
+
+	return []any{NewMaintenanceMiddleware(config, maintenance)}
+}
+
+// NewFxMaintenanceGrpcInterceptors returns the [MaintenanceGrpcInterceptor] if the maintenance mode is enabled, to be
+// applied by fxgrpcserver on its gRPC server.
+func NewFxMaintenanceGrpcInterceptors(config *config.Config, maintenance *Maintenance) []any {
+	if !config.GetBool("modules.core.maintenance.enabled") {
+		return nil
+	}
+
+	return []any{NewMaintenanceGrpcInterceptor(maintenance)}
+}
+
+// Enable activates the maintenance mode, with an optional expiry duration (0 for no expiry).
+func (m *Maintenance) Enable(duration time.Duration) *Maintenance {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.active = true
+	m.since = time.Now()
+	m.until = time.Time{}
+
+	if duration > 0 {
+		m.until = m.since.Add(duration)
+	}
+
+	return m
+}
+
+// Disable deactivates the maintenance mode.
+func (m *Maintenance) Disable() *Maintenance {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.active = false
+	m.since = time.Time{}
+	m.until = time.Time{}
+
+	return m
+}
+
+// Active returns true if the maintenance mode is active and not expired.
+func (m *Maintenance) Active() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.isActive()
+}
+
+// Remaining returns the remaining duration of the maintenance mode, or 0 if inactive or without expiry.
+func (m *Maintenance) Remaining() time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if !m.isActive() || m.until.IsZero() {
+		return 0
+	}
+
+	return time.Until(m.until)
+}
+
+// Status returns the current [MaintenanceStatus].
+func (m *Maintenance) Status() *MaintenanceStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	maintenanceStatus := &MaintenanceStatus{
+		Active: m.isActive(),
+	}
+
+	if maintenanceStatus.Active {
+		since := m.since
+		maintenanceStatus.Since = &since
+
+		if !m.until.IsZero() {
+			until := m.until
+			maintenanceStatus.Until = &until
+		}
+	}
+
+	return maintenanceStatus
+}
+
+func (m *Maintenance) isActive() bool {
+	return m.active && (m.until.IsZero() || time.Now().Before(m.until))
+}
+
+// MaintenanceProbe is a readiness [healthcheck.CheckerProbe] failing while the maintenance mode is active.
+type MaintenanceProbe struct {
+	maintenance *Maintenance
+}
+
+// NewMaintenanceProbe returns a new [MaintenanceProbe].
+func NewMaintenanceProbe(maintenance *Maintenance) *MaintenanceProbe {
+	return &MaintenanceProbe{
+		maintenance: maintenance,
+	}
+}
+
+// Name returns the name of the [MaintenanceProbe].
+func (p *MaintenanceProbe) Name() string {
+	return MaintenanceProbeName
+}
+
+// Check returns a failing [healthcheck.CheckerProbeResult] if the maintenance mode is active.
+func (p *MaintenanceProbe) Check(context.Context) *healthcheck.CheckerProbeResult {
+	if p.maintenance.Active() {
+		return healthcheck.NewCheckerProbeResult(false, "maintenance mode active")
+	}
+
+	return healthcheck.NewCheckerProbeResult(true, "maintenance mode inactive")
+}
+
+// MaintenanceMiddleware is an HTTP server middleware answering 503 while the maintenance mode is active.
+// It is compatible with the fxhttpserver middlewares registration.
+type MaintenanceMiddleware struct {
+	config      *config.Config
+	maintenance *Maintenance
+}
+
+// NewMaintenanceMiddleware returns a new [MaintenanceMiddleware].
+func NewMaintenanceMiddleware(config *config.Config, maintenance *Maintenance) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		config:      config,
+		maintenance: maintenance,
+	}
+}
+
+// Handle returns the [MaintenanceMiddleware] [echo.MiddlewareFunc].
+func (m *MaintenanceMiddleware) Handle() echo.MiddlewareFunc {
+	body := m.config.GetString("modules.core.maintenance.response.body")
+	if body == "" {
+		body = `{"message":"` + DefaultMaintenanceMessage + `"}`
+	}
+
+	contentType := m.config.GetString("modules.core.maintenance.response.content_type")
+	if contentType == "" {
+		contentType = DefaultMaintenanceContentType
+	}
+
+	retryAfter := DefaultMaintenanceRetryAfter
+	if m.config.IsSet("modules.core.maintenance.response.retry_after") {
+		retryAfter = m.config.GetInt("modules.core.maintenance.response.retry_after")
+	}
+
+	allowedPaths := m.config.GetStringSlice("modules.core.maintenance.allow")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !m.maintenance.Active() {
+				return next(c)
+			}
+
+			if matchMaintenanceAllowedPath(allowedPaths, c.Request().URL.Path) {
+				return next(c)
+			}
+
+			if remaining := m.maintenance.Remaining(); remaining > 0 {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+			} else if retryAfter > 0 {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+
+			return c.Blob(http.StatusServiceUnavailable, contentType, []byte(body))
+		}
+	}
+}
+
+// MaintenanceGrpcInterceptor is a gRPC server interceptor returning Unavailable while the maintenance mode is active.
+// It is compatible with the fxgrpcserver interceptors registration.
+type MaintenanceGrpcInterceptor struct {
+	maintenance *Maintenance
+}
+
+// NewMaintenanceGrpcInterceptor returns a new [MaintenanceGrpcInterceptor].
+func NewMaintenanceGrpcInterceptor(maintenance *Maintenance) *MaintenanceGrpcInterceptor {
+	return &MaintenanceGrpcInterceptor{
+		maintenance: maintenance,
+	}
+}
+
+// HandleUnary returns the [MaintenanceGrpcInterceptor] [grpc.UnaryServerInterceptor].
+func (i *MaintenanceGrpcInterceptor) HandleUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if i.maintenance.Active() && !isGrpcHealthMethod(info.FullMethod) {
+			return nil, status.Error(codes.Unavailable, DefaultMaintenanceMessage)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// HandleStream returns the [MaintenanceGrpcInterceptor] [grpc.StreamServerInterceptor].
+func (i *MaintenanceGrpcInterceptor) HandleStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if i.maintenance.Active() && !isGrpcHealthMethod(info.FullMethod) {
+			return status.Error(codes.Unavailable, DefaultMaintenanceMessage)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// matchMaintenanceAllowedPath returns true if a path is one of the allowed paths, or is under one of them by whole
+// path segments (/health allows /health and /health/ready, but not /healthz).
+func matchMaintenanceAllowedPath(allowedPaths []string, path string) bool {
+	for _, allowedPath := range allowedPaths {
+		if path == allowedPath || strings.HasPrefix(path, strings.TrimSuffix(allowedPath, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isGrpcHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
+
+func maintenanceHandler(maintenance *Maintenance, logger *log.Logger) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodPost {
+			var request MaintenanceRequest
+			if err := c.Bind(&request); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid maintenance request")
+			}
+
+			if request.Enabled {
+				maintenance.Enable(time.Duration(request.Duration) * time.Second)
+			} else {
+				maintenance.Disable()
+			}
+
+			logger.
+				Info().
+				Str("module", ModuleName).
+				Bool("active", maintenance.Active()).
+				Int("duration", request.Duration).
+				Msg("maintenance mode toggled")
+		}
+
+		return c.JSON(http.StatusOK, maintenance.Status())
+	}
+}
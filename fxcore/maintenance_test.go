@@ -0,0 +1,192 @@
+package fxcore_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxcore"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMaintenance(t *testing.T) {
+	t.Parallel()
+
+	maintenance := fxcore.NewMaintenance()
+	probe := fxcore.NewMaintenanceProbe(maintenance)
+
+	assert.False(t, maintenance.Active())
+	assert.Equal(t, &fxcore.MaintenanceStatus{Active: false}, maintenance.Status())
+	assert.Equal(t, fxcore.MaintenanceProbeName, probe.Name())
+	assert.True(t, probe.Check(context.Background()).Success)
+
+	// without expiry
+	maintenance.Enable(0)
+
+	assert.True(t, maintenance.Active())
+	assert.Equal(t, time.Duration(0), maintenance.Remaining())
+	assert.NotNil(t, maintenance.Status().Since)
+	assert.Nil(t, maintenance.Status().Until)
+
+	result := probe.Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "maintenance mode active", result.Message)
+
+	maintenance.Disable()
+
+	assert.False(t, maintenance.Active())
+	assert.True(t, probe.Check(context.Background()).Success)
+
+	// with expiry
+	maintenance.Enable(time.Hour)
+
+	assert.True(t, maintenance.Active())
+	assert.Greater(t, maintenance.Remaining(), 59*time.Minute)
+	assert.NotNil(t, maintenance.Status().Until)
+
+	maintenance.Enable(time.Millisecond)
+
+	assert.Eventually(
+		t,
+		func() bool {
+			return !maintenance.Active()
+		},
+		time.Second,
+		time.Millisecond,
+	)
+
+	assert.Equal(t, time.Duration(0), maintenance.Remaining())
+	assert.True(t, probe.Check(context.Background()).Success)
+}
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	maintenance := fxcore.NewMaintenance()
+
+	server := echo.New()
+	server.Use(fxcore.NewMaintenanceMiddleware(cfg, maintenance).Handle())
+	server.GET("/*", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	// inactive
+	req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// active without expiry
+	maintenance.Enable(0)
+
+	req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "120", rec.Header().Get("Retry-After"))
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `{"error":"maintenance"}`, rec.Body.String())
+
+	// active with expiry
+	maintenance.Enable(10 * time.Second)
+
+	req = httptest.NewRequest(http.MethodGet, "/foo", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	// allowed paths, by whole path segments
+	for target, expectedCode := range map[string]int{
+		"/allowed":         http.StatusOK,
+		"/allowed/":        http.StatusOK,
+		"/allowed/foo":     http.StatusOK,
+		"/allowedfoo":      http.StatusServiceUnavailable,
+		"/allowed-foo/bar": http.StatusServiceUnavailable,
+	} {
+		req = httptest.NewRequest(http.MethodGet, target, nil)
+		rec = httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		assert.Equal(t, expectedCode, rec.Code, target)
+	}
+}
+
+func TestFxMaintenanceWiring(t *testing.T) {
+	t.Setenv("MAINTENANCE_ENABLED", "true")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	maintenance := fxcore.NewMaintenance()
+
+	middlewares := fxcore.NewFxMaintenanceHttpMiddlewares(cfg, maintenance)
+	assert.Len(t, middlewares, 1)
+	assert.IsType(t, &fxcore.MaintenanceMiddleware{}, middlewares[0])
+
+	interceptors := fxcore.NewFxMaintenanceGrpcInterceptors(cfg, maintenance)
+	assert.Len(t, interceptors, 1)
+	assert.IsType(t, &fxcore.MaintenanceGrpcInterceptor{}, interceptors[0])
+
+	cfg.Set("modules.core.maintenance.enabled", false)
+
+	assert.Empty(t, fxcore.NewFxMaintenanceHttpMiddlewares(cfg, maintenance))
+	assert.Empty(t, fxcore.NewFxMaintenanceGrpcInterceptors(cfg, maintenance))
+}
+
+func TestMaintenanceGrpcInterceptor(t *testing.T) {
+	t.Parallel()
+
+	maintenance := fxcore.NewMaintenance()
+	interceptor := fxcore.NewMaintenanceGrpcInterceptor(maintenance)
+
+	unaryHandler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+
+	streamHandler := func(srv any, stream grpc.ServerStream) error {
+		return nil
+	}
+
+	unaryInfo := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+
+	// inactive
+	res, err := interceptor.HandleUnary()(context.Background(), nil, unaryInfo, unaryHandler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+
+	err = interceptor.HandleStream()(nil, nil, streamInfo, streamHandler)
+	assert.NoError(t, err)
+
+	// active
+	maintenance.Enable(0)
+
+	_, err = interceptor.HandleUnary()(context.Background(), nil, unaryInfo, unaryHandler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	err = interceptor.HandleStream()(nil, nil, streamInfo, streamHandler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	res, err = interceptor.HandleUnary()(context.Background(), nil, healthInfo, unaryHandler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+}
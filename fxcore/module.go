@@ -38,6 +38,7 @@ const (
 	DefaultHealthCheckStartupPath   = "/healthz"
 	DefaultHealthCheckLivenessPath  = "/livez"
 	DefaultHealthCheckReadinessPath = "/readyz"
+	DefaultHealthCheckHistoryPath   = "/healthz/history"
 	DefaultTasksPath                = "/tasks"
 	DefaultDebugConfigPath          = "/debug/config"
 	DefaultDebugPProfPath           = "/debug/pprof"
@@ -45,6 +46,9 @@ const (
 	DefaultDebugRoutesPath          = "/debug/routes"
 	DefaultDebugStatsPath           = "/debug/stats"
 	DefaultDebugModulesPath         = "/debug/modules"
+	DefaultShutdownPath             = "/shutdown"
+	DefaultMaintenancePath          = "/maintenance"
+	DefaultOpenApiPath              = "/openapi"
 	ThemeLight                      = "light"
 	ThemeDark                       = "dark"
 )
@@ -66,7 +70,23 @@ var FxCoreModule = fx.Module(
 	fx.Provide(
 		NewFxModuleInfoRegistry,
 		NewTaskRegistry,
+		NewFxShutdownOrchestrator,
+		NewFxMaintenance,
 		NewFxCore,
+		fx.Annotate(
+			func(orchestrator *ShutdownOrchestrator) *ShutdownOrchestrator {
+				return orchestrator
+			},
+			fx.As(new(healthcheck.ShutdownRecorder)),
+		),
+		fx.Annotate(
+			NewFxMaintenanceHttpMiddlewares,
+			fx.ResultTags(`group:"core-http-middlewares,flatten"`),
+		),
+		fx.Annotate(
+			NewFxMaintenanceGrpcInterceptors,
+			fx.ResultTags(`group:"core-grpc-interceptors,flatten"`),
+		),
 		fx.Annotate(
 			NewFxCoreModuleInfo,
 			fx.As(new(interface{})),
@@ -93,11 +113,16 @@ type FxCoreParam struct {
 	Generator       uuid.UuidGenerator
 	TracerProvider  oteltrace.TracerProvider
 	Checker         *healthcheck.Checker
+	Monitor         *healthcheck.Monitor
 	Config          *config.Config
 	Logger          *log.Logger
 	InfoRegistry    *FxModuleInfoRegistry
 	TaskRegistry    *TaskRegistry
 	MetricsRegistry *prometheus.Registry
+	Shutdown        *ShutdownOrchestrator
+	Maintenance     *Maintenance
+	OpenApiSpecs    []any `group:"core-openapi-specs"`
+	Routes          []any `group:"core-routes"`
 }
 
 // NewFxCore returns a new [Core].
@@ -115,16 +140,19 @@ func NewFxCore(p FxCoreParam) (*Core, error) {
 
 		// server
 		coreServer, err = httpserver.NewDefaultHttpServerFactory().Create(
-			httpserver.WithDebug(appDebug),
-			httpserver.WithBanner(false),
-			httpserver.WithLogger(coreLogger),
-			httpserver.WithRenderer(NewDashboardRenderer(templatesFS, "templates/dashboard.html")),
-			httpserver.WithHttpErrorHandler(
-				httpserver.NewJsonErrorHandler(
-					p.Config.GetBool("modules.core.server.errors.obfuscate") || !appDebug,
-					p.Config.GetBool("modules.core.server.errors.stack") || appDebug,
-				).Handle(),
-			),
+			append(
+				serverOptions(p.Config),
+				httpserver.WithDebug(appDebug),
+				httpserver.WithBanner(false),
+				httpserver.WithLogger(coreLogger),
+				httpserver.WithRenderer(NewDashboardRenderer(templatesFS, "templates/*.html")),
+				httpserver.WithHttpErrorHandler(
+					httpserver.NewJsonErrorHandler(
+						p.Config.GetBool("modules.core.server.errors.obfuscate") || !appDebug,
+						p.Config.GetBool("modules.core.server.errors.stack") || appDebug,
+					).Handle(),
+				),
+			)...,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create core http server: %w", err)
@@ -153,9 +181,16 @@ func NewFxCore(p FxCoreParam) (*Core, error) {
 				return nil
 			},
 			OnStop: func(ctx context.Context) error {
+				//nolint:errcheck
+				defer p.Shutdown.Terminate(ctx)
+
 				return coreServer.Shutdown(ctx)
 			},
 		})
+	} else {
+		p.LifeCycle.Append(fx.Hook{
+			OnStop: p.Shutdown.Terminate,
+		})
 	}
 
 	return NewCore(p.Config, p.Checker, coreServer), nil
@@ -163,7 +198,14 @@ func NewFxCore(p FxCoreParam) (*Core, error) {
 
 func withMiddlewares(coreServer *echo.Echo, p FxCoreParam) *echo.Echo {
 	// CORS middleware
-	coreServer.Use(middleware.CORS())
+	coreServer.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:     p.Config.GetStringSlice("modules.core.server.security.cors.origins"),
+		AllowMethods:     p.Config.GetStringSlice("modules.core.server.security.cors.methods"),
+		AllowHeaders:     p.Config.GetStringSlice("modules.core.server.security.cors.headers"),
+		ExposeHeaders:    p.Config.GetStringSlice("modules.core.server.security.cors.expose"),
+		AllowCredentials: p.Config.GetBool("modules.core.server.security.cors.credentials"),
+		MaxAge:           p.Config.GetInt("modules.core.server.security.cors.max_age"),
+	}))
 
 	// request id middleware
 	coreServer.Use(httpservermiddleware.RequestIdMiddlewareWithConfig(
@@ -262,12 +304,19 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 	startupExpose := p.Config.GetBool("modules.core.server.healthcheck.startup.expose")
 	livenessExpose := p.Config.GetBool("modules.core.server.healthcheck.liveness.expose")
 	readinessExpose := p.Config.GetBool("modules.core.server.healthcheck.readiness.expose")
+	historyExpose := p.Config.GetBool("modules.healthcheck.monitor.enabled") &&
+		p.Config.GetBool("modules.core.server.healthcheck.history.expose")
 	configExpose := p.Config.GetBool("modules.core.server.debug.config.expose")
 	pprofExpose := p.Config.GetBool("modules.core.server.debug.pprof.expose")
 	routesExpose := p.Config.GetBool("modules.core.server.debug.routes.expose")
 	statsExpose := p.Config.GetBool("modules.core.server.debug.stats.expose")
 	buildExpose := p.Config.GetBool("modules.core.server.debug.build.expose")
 	modulesExpose := p.Config.GetBool("modules.core.server.debug.modules.expose")
+	shutdownExpose := p.Config.GetBool("modules.core.server.shutdown.expose")
+	maintenanceExpose := p.Config.GetBool("modules.core.maintenance.enabled") &&
+		p.Config.GetBool("modules.core.server.maintenance.expose")
+	openApiSpecProvider := ResolveOpenApiSpecProvider(p.OpenApiSpecs)
+	openApiExpose := p.Config.GetBool("modules.core.server.openapi.expose") && openApiSpecProvider != nil
 
 	// template paths
 	tasksPath := p.Config.GetString("modules.core.server.tasks.path")
@@ -275,12 +324,16 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 	startupPath := p.Config.GetString("modules.core.server.healthcheck.startup.path")
 	livenessPath := p.Config.GetString("modules.core.server.healthcheck.liveness.path")
 	readinessPath := p.Config.GetString("modules.core.server.healthcheck.readiness.path")
+	historyPath := p.Config.GetString("modules.core.server.healthcheck.history.path")
 	configPath := p.Config.GetString("modules.core.server.debug.config.path")
 	pprofPath := p.Config.GetString("modules.core.server.debug.pprof.path")
 	routesPath := p.Config.GetString("modules.core.server.debug.routes.path")
 	statsPath := p.Config.GetString("modules.core.server.debug.stats.path")
 	buildPath := p.Config.GetString("modules.core.server.debug.build.path")
 	modulesPath := p.Config.GetString("modules.core.server.debug.modules.path")
+	shutdownPath := p.Config.GetString("modules.core.server.shutdown.path")
+	maintenancePath := p.Config.GetString("modules.core.server.maintenance.path")
+	openApiPath := p.Config.GetString("modules.core.server.openapi.path")
 
 	// tasks
 	if tasksExpose {
@@ -368,6 +421,56 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 		coreServer.Logger.Debug("registered healthcheck readiness handler")
 	}
 
+	// healthcheck history
+	if historyExpose {
+		if historyPath == "" {
+			historyPath = DefaultHealthCheckHistoryPath
+		}
+
+		coreServer.GET(historyPath, func(c echo.Context) error {
+			return c.JSON(http.StatusOK, p.Monitor.Histories())
+		})
+
+		coreServer.Logger.Debug("registered healthcheck history handler")
+	}
+
+	// shutdown
+	if shutdownExpose {
+		if shutdownPath == "" {
+			shutdownPath = DefaultShutdownPath
+		}
+
+		coreServer.GET(shutdownPath, func(c echo.Context) error {
+			return c.JSON(http.StatusOK, p.Shutdown.Status())
+		})
+
+		coreServer.Logger.Debug("registered shutdown handler")
+	}
+
+	// maintenance
+	if maintenanceExpose {
+		if maintenancePath == "" {
+			maintenancePath = DefaultMaintenancePath
+		}
+
+		coreServer.GET(maintenancePath, maintenanceHandler(p.Maintenance, p.Logger))
+		coreServer.POST(maintenancePath, maintenanceHandler(p.Maintenance, p.Logger))
+
+		coreServer.Logger.Debug("registered maintenance handlers")
+	}
+
+	// openapi
+	if openApiExpose {
+		if openApiPath == "" {
+			openApiPath = DefaultOpenApiPath
+		}
+
+		coreServer.GET(openApiPath, openApiPageHandler(p.Config.AppName(), openApiPath+".json"))
+		coreServer.GET(openApiPath+".json", openApiSpecHandler(openApiSpecProvider))
+
+		coreServer.Logger.Debug("registered openapi handlers")
+	}
+
 	// debug config
 	if configExpose || appDebug {
 		if configPath == "" {
@@ -409,7 +512,11 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 			routesPath = DefaultDebugRoutesPath
 		}
 
-		coreServer.GET(routesPath, handler.DebugRoutesHandler(coreServer))
+		if routesProviders := ResolveRoutesProviders(p.Routes); len(routesProviders) > 0 {
+			coreServer.GET(routesPath, debugRoutesHandler(coreServer, routesProviders))
+		} else {
+			coreServer.GET(routesPath, handler.DebugRoutesHandler(coreServer))
+		}
 
 		coreServer.Logger.Debug("registered debug routes handler")
 	}
@@ -534,6 +641,15 @@ func withHandlers(coreServer *echo.Echo, p FxCoreParam) (*echo.Echo, error) {
 				"livenessPath":                 livenessPath,
 				"readinessExpose":              readinessExpose,
 				"readinessPath":                readinessPath,
+				"historyExpose":                historyExpose,
+				"historyPath":                  historyPath,
+				"shutdownExpose":               shutdownExpose,
+				"shutdownPath":                 shutdownPath,
+				"maintenanceExpose":            maintenanceExpose,
+				"maintenancePath":              maintenancePath,
+				"maintenanceActive":            p.Maintenance.Active(),
+				"openApiExpose":                openApiExpose,
+				"openApiPath":                  openApiPath,
 				"configExpose":                 configExpose || appDebug,
 				"configPath":                   configPath,
 				"pprofExpose":                  pprofExpose || appDebug,
@@ -2,19 +2,24 @@ package fxcore_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/fxcore"
+	"github.com/ankorstore/yokai/fxcore/testdata/openapi"
 	"github.com/ankorstore/yokai/fxcore/testdata/probes"
+	"github.com/ankorstore/yokai/fxcore/testdata/routes"
 	"github.com/ankorstore/yokai/fxcore/testdata/tasks"
 	"github.com/ankorstore/yokai/fxhealthcheck"
 	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -304,6 +309,72 @@ func TestModuleWithHealthcheckDisabled(t *testing.T) {
 	)
 }
 
+func TestModuleWithHealthcheckHistory(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MONITOR_ENABLED", "true")
+	t.Setenv("HISTORY_ENABLED", "true")
+
+	var core *fxcore.Core
+
+	app := fxcore.NewBootstrapper().BootstrapTestApp(
+		t,
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fxhealthcheck.AsCheckerProbe(probes.NewFailureProbe, healthcheck.Liveness),
+		fx.Populate(&core),
+	).RequireStart()
+
+	// [GET] /healthz/history
+	var histories map[string][]*healthcheck.MonitorRecord
+
+	assert.Eventually(
+		t,
+		func() bool {
+			req := httptest.NewRequest(http.MethodGet, "/healthz/history", nil)
+			rec := httptest.NewRecorder()
+			core.HttpServer().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &histories) != nil {
+				return false
+			}
+
+			return len(histories["successProbe"]) == 5 && len(histories["failureProbe"]) == 5
+		},
+		time.Second,
+		10*time.Millisecond,
+	)
+
+	assert.True(t, histories["successProbe"][0].Success)
+	assert.Equal(t, "success", histories["successProbe"][0].Message)
+	assert.False(t, histories["failureProbe"][0].Success)
+	assert.Equal(t, "failure", histories["failureProbe"][0].Message)
+
+	// [GET] / (dashboard)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `data-url="/healthz/history" data-type="history" data-view="history"`)
+
+	app.RequireStop()
+}
+
+func TestModuleWithHealthcheckHistoryAndMonitorDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MONITOR_ENABLED", "false")
+	t.Setenv("HISTORY_ENABLED", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz/history", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestModuleWithHealthcheckEnabled(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("STARTUP_ENABLED", "true")
@@ -627,6 +698,36 @@ func TestModuleWithDebugRoutesDisabled(t *testing.T) {
 	)
 }
 
+func TestModuleWithDebugRoutesProviders(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("ROUTES_ENABLED", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(
+		t,
+		fx.Provide(
+			fx.Annotate(
+				routes.NewTestRoutes,
+				fx.As(new(interface{})),
+				fx.ResultTags(`group:"core-routes"`),
+			),
+		),
+		fx.Populate(&core),
+	)
+
+	// [GET] /debug/routes
+	req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body := strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", "")
+	assert.Contains(t, body, `"path":"/debug/routes"`)
+	assert.Contains(t, body, `{"method":"GET","path":"/test","policies":["scopes=read"],"server":"test"}`)
+}
+
 func TestModuleWithDebugRoutesEnabled(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("ROUTES_ENABLED", "true")
@@ -1133,3 +1234,354 @@ func TestModuleDashboardTasks(t *testing.T) {
 	assert.Equal(t, "task invalid not found", res.Message)
 	assert.Nil(t, res.Details)
 }
+
+func TestModuleShutdown(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SHUTDOWN_ENABLED", "true")
+	t.Setenv("SHUTDOWN_EXPOSE", "true")
+	t.Setenv("READINESS_ENABLED", "true")
+
+	var core *fxcore.Core
+	var orchestrator *fxcore.ShutdownOrchestrator
+	var recorder healthcheck.ShutdownRecorder
+	var logBuffer logtest.TestLogBuffer
+
+	app := fxcore.NewBootstrapper().BootstrapTestApp(
+		t,
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fx.Populate(&core, &orchestrator, &recorder, &logBuffer),
+	)
+
+	app.RequireStart()
+
+	// the orchestrator records the modules shutdown steps
+	assert.Same(t, orchestrator, recorder)
+
+	// [GET] /readyz
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t,
+		`{"success":true,"probes":{"shutdown":{"success":true,"message":"running"},"successProbe":{"success":true,"message":"success"}}}`,
+		strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", ""),
+	)
+
+	// [GET] /shutdown
+	req = httptest.NewRequest(http.MethodGet, "/shutdown", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t,
+		`{"enabled":true,"phase":"running","drain":"0s","events":[]}`,
+		strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", ""),
+	)
+
+	app.RequireStop()
+
+	assert.Equal(t, fxcore.ShutdownStopped, orchestrator.Phase())
+	assert.False(t, core.Checker().Check(context.Background(), healthcheck.Readiness).Success)
+
+	status := orchestrator.Status()
+	assert.Len(t, status.Events, 3)
+	assert.Equal(t, "draining", status.Events[0].Phase)
+	assert.Equal(t, "stopping", status.Events[1].Phase)
+	assert.Equal(t, "stopped", status.Events[2].Phase)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"service": "core-app",
+		"module":  "core",
+		"phase":   "draining",
+		"message": "readiness flipped to failing, draining",
+	})
+}
+
+func TestModuleShutdownDisabled(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SHUTDOWN_ENABLED", "false")
+
+	var core *fxcore.Core
+	var orchestrator *fxcore.ShutdownOrchestrator
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core, &orchestrator))
+
+	assert.False(t, orchestrator.Enabled())
+	assert.Equal(t, fxcore.ShutdownStopped, orchestrator.Phase())
+	assert.Len(t, core.Checker().Probes(healthcheck.Readiness), 0)
+
+	// [GET] /shutdown
+	req := httptest.NewRequest(http.MethodGet, "/shutdown", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestModuleMaintenance(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MAINTENANCE_ENABLED", "true")
+	t.Setenv("MAINTENANCE_EXPOSE", "true")
+	t.Setenv("READINESS_ENABLED", "true")
+	t.Setenv("MODULES_ENABLED", "true")
+
+	var core *fxcore.Core
+	var maintenance *fxcore.Maintenance
+	var logBuffer logtest.TestLogBuffer
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core, &maintenance, &logBuffer))
+
+	// [GET] /maintenance
+	req := httptest.NewRequest(http.MethodGet, "/maintenance", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"active":false}`, strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", ""))
+
+	// [GET] /readyz
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// [POST] /maintenance
+	req = httptest.NewRequest(http.MethodPost, "/maintenance", bytes.NewBufferString(`{"enabled":true,"duration":60}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", ""), `"active":true`)
+	assert.Contains(t, rec.Body.String(), `"until"`)
+	assert.True(t, maintenance.Active())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":    "info",
+		"module":   "core",
+		"active":   true,
+		"duration": 60,
+		"message":  "maintenance mode toggled",
+	})
+
+	// [GET] /readyz
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"maintenance mode active"`)
+
+	// [GET] /debug/modules/core
+	req = httptest.NewRequest(http.MethodGet, "/debug/modules/core", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, strings.ReplaceAll(strings.ReplaceAll(rec.Body.String(), " ", ""), "\n", ""), `"maintenance":{"active":true`)
+
+	// [GET] / dashboard
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Maintenance on")
+
+	// [POST] /maintenance
+	req = httptest.NewRequest(http.MethodPost, "/maintenance", bytes.NewBufferString(`{"enabled":false}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, maintenance.Active())
+
+	// [POST] /maintenance with invalid payload
+	req = httptest.NewRequest(http.MethodPost, "/maintenance", bytes.NewBufferString(`invalid`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestModuleWithServerSettings(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_TIMEOUTS_READ", "10")
+	t.Setenv("MODULES_CORE_SERVER_TIMEOUTS_READ_HEADER", "5")
+	t.Setenv("MODULES_CORE_SERVER_TIMEOUTS_WRITE", "20")
+	t.Setenv("MODULES_CORE_SERVER_TIMEOUTS_IDLE", "60")
+	t.Setenv("MODULES_CORE_SERVER_MAX_HEADER_BYTES", "4096")
+	t.Setenv("MODULES_CORE_SERVER_BODY_LIMIT", "10B")
+	t.Setenv("MODULES_CORE_SERVER_H2C", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	server := core.HttpServer().Server
+	assert.Equal(t, 10*time.Second, server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 20*time.Second, server.WriteTimeout)
+	assert.Equal(t, 60*time.Second, server.IdleTimeout)
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+	assert.True(t, server.Protocols.UnencryptedHTTP2())
+
+	// [POST] / with too large body
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"enabled":true,"duration":60}`))
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestModuleWithCORS(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// default policy
+	req := httptest.NewRequest(http.MethodOptions, "/healthz", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://any.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestModuleWithCORSConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_SECURITY_CORS_ORIGINS", "https://allowed.example.com")
+	t.Setenv("MODULES_CORE_SERVER_SECURITY_CORS_METHODS", "GET")
+	t.Setenv("MODULES_CORE_SERVER_SECURITY_CORS_CREDENTIALS", "true")
+	t.Setenv("MODULES_CORE_SERVER_SECURITY_CORS_MAX_AGE", "600")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	// allowed origin
+	req := httptest.NewRequest(http.MethodOptions, "/healthz", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://allowed.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://allowed.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+
+	// not allowed origin
+	req = httptest.NewRequest(http.MethodOptions, "/healthz", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://other.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestModuleWithInvalidServerBodyLimit(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_BODY_LIMIT", "invalid")
+
+	err := fxcore.NewBootstrapper().BootstrapApp().Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create core http server: invalid body limit invalid")
+}
+
+func TestModuleWithOpenApi(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_OPENAPI_EXPOSE", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(
+		t,
+		fx.Provide(
+			fx.Annotate(
+				openapi.NewTestOpenApiSpec,
+				fx.As(new(interface{})),
+				fx.ResultTags(`group:"core-openapi-specs"`),
+			),
+		),
+		fx.Populate(&core),
+	)
+
+	// [GET] /openapi
+	req := httptest.NewRequest(http.MethodGet, "/openapi", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<redoc spec-url="/openapi.json"></redoc>`)
+	assert.Contains(t, rec.Body.String(), `https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles/redoc.standalone.js`)
+
+	// [GET] /openapi.json
+	req = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"openapi":"3.1.0","info":{"title":"test","version":"0.1.0"},"paths":{}}`, rec.Body.String())
+
+	// [GET] / dashboard
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "OpenAPI")
+}
+
+func TestModuleWithOpenApiFailure(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_OPENAPI_EXPOSE", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(
+		t,
+		fx.Provide(
+			fx.Annotate(
+				openapi.NewTestFailingOpenApiSpec,
+				fx.As(new(interface{})),
+				fx.ResultTags(`group:"core-openapi-specs"`),
+			),
+		),
+		fx.Populate(&core),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "cannot get test openapi spec: test error")
+}
+
+func TestModuleWithOpenApiWithoutSpec(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("MODULES_CORE_SERVER_OPENAPI_EXPOSE", "true")
+
+	var core *fxcore.Core
+
+	fxcore.NewBootstrapper().RunTestApp(t, fx.Populate(&core))
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi", nil)
+	rec := httptest.NewRecorder()
+	core.HttpServer().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,45 @@
+package fxcore
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OpenApiSpecProvider is the interface for the OpenAPI specifications providers, collected in the core-openapi-specs group.
+type OpenApiSpecProvider interface {
+	Name() string
+	OpenApiSpec() ([]byte, error)
+}
+
+// ResolveOpenApiSpecProvider returns the first [OpenApiSpecProvider] found in the provided list, nil if none.
+func ResolveOpenApiSpecProvider(specs []any) OpenApiSpecProvider {
+	for _, spec := range specs {
+		if provider, ok := spec.(OpenApiSpecProvider); ok {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+func openApiSpecHandler(provider OpenApiSpecProvider) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		spec, err := provider.OpenApiSpec()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("cannot get %s openapi spec: %v", provider.Name(), err))
+		}
+
+		return c.JSONBlob(http.StatusOK, spec)
+	}
+}
+
+func openApiPageHandler(title string, specPath string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.Render(http.StatusOK, "openapi.html", map[string]interface{}{
+			"title":    title,
+			"specPath": specPath,
+		})
+	}
+}
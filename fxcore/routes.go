@@ -0,0 +1,46 @@
+package fxcore
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RoutesProvider is the interface for the routes providers, collected in the core-routes group.
+type RoutesProvider interface {
+	Name() string
+	Routes() []map[string]any
+}
+
+// ResolveRoutesProviders returns the [RoutesProvider] found in the provided list.
+func ResolveRoutesProviders(providers []any) []RoutesProvider {
+	var routesProviders []RoutesProvider
+
+	for _, provider := range providers {
+		if routesProvider, ok := provider.(RoutesProvider); ok {
+			routesProviders = append(routesProviders, routesProvider)
+		}
+	}
+
+	return routesProviders
+}
+
+// debugRoutesHandler returns the core server routes, followed by the routes of the providers (for example the
+// application http server routes, with their authorization policies).
+func debugRoutesHandler(coreServer *echo.Echo, providers []RoutesProvider) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var routes []any
+
+		for _, route := range coreServer.Routes() {
+			routes = append(routes, route)
+		}
+
+		for _, provider := range providers {
+			for _, route := range provider.Routes() {
+				routes = append(routes, route)
+			}
+		}
+
+		return c.JSON(http.StatusOK, routes)
+	}
+}
@@ -0,0 +1,224 @@
+package fxcore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"go.uber.org/fx"
+)
+
+const ShutdownProbeName = "shutdown"
+
+// ShutdownPhase is an enum for the application shutdown phases.
+type ShutdownPhase int
+
+const (
+	ShutdownRunning ShutdownPhase = iota
+	ShutdownDraining
+	ShutdownStopping
+	ShutdownStopped
+)
+
+// String returns a string representation of the [ShutdownPhase].
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownDraining:
+		return "draining"
+	case ShutdownStopping:
+		return "stopping"
+	case ShutdownStopped:
+		return "stopped"
+	default:
+		return "running"
+	}
+}
+
+// ShutdownEvent is an event recorded by the [ShutdownOrchestrator] when entering a [ShutdownPhase], or reported by
+// a module while stopping.
+type ShutdownEvent struct {
+	Phase   string    `json:"phase"`
+	Module  string    `json:"module,omitempty"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// ShutdownStatus is the status of the [ShutdownOrchestrator].
+type ShutdownStatus struct {
+	Enabled bool             `json:"enabled"`
+	Phase   string           `json:"phase"`
+	Drain   string           `json:"drain"`
+	Events  []*ShutdownEvent `json:"events"`
+}
+
+// ShutdownOrchestrator orchestrates the application graceful shutdown.
+//
+// On stop, it first flips the readiness probe to failing, then waits for the drain period to let
+// load balancers stop routing traffic, before letting the other modules stop their servers, workers and jobs.
+type ShutdownOrchestrator struct {
+	mutex   sync.RWMutex
+	logger  *log.Logger
+	enabled bool
+	drain   time.Duration
+	phase   ShutdownPhase
+	events  []*ShutdownEvent
+}
+
+// NewShutdownOrchestrator returns a new [ShutdownOrchestrator].
+func NewShutdownOrchestrator(logger *log.Logger, enabled bool, drain time.Duration) *ShutdownOrchestrator {
+	return &ShutdownOrchestrator{
+		logger:  logger,
+		enabled: enabled,
+		drain:   drain,
+		phase:   ShutdownRunning,
+		events:  []*ShutdownEvent{},
+	}
+}
+
+// FxShutdownOrchestratorParam allows injection of the required dependencies in [NewFxShutdownOrchestrator].
+type FxShutdownOrchestratorParam struct {
+	fx.In
+	Config  *config.Config
+	Logger  *log.Logger
+	Checker *healthcheck.Checker
+}
+
+// NewFxShutdownOrchestrator returns a new [ShutdownOrchestrator], and registers its readiness probe if enabled.
+func NewFxShutdownOrchestrator(p FxShutdownOrchestratorParam) *ShutdownOrchestrator {
+	orchestrator := NewShutdownOrchestrator(
+		p.Logger,
+		p.Config.GetBool("modules.core.shutdown.enabled"),
+		p.Config.GetDuration("modules.core.shutdown.drain"),
+	)
+
+	if orchestrator.Enabled() {
+		p.Checker.RegisterProbe(NewShutdownProbe(orchestrator), healthcheck.Readiness)
+	}
+
+	return orchestrator
+}
+
+// Enabled returns true if the readiness drain is enabled.
+func (o *ShutdownOrchestrator) Enabled() bool {
+	return o.enabled
+}
+
+// DrainPeriod returns the drain period.
+func (o *ShutdownOrchestrator) DrainPeriod() time.Duration {
+	return o.drain
+}
+
+// Phase returns the current [ShutdownPhase].
+func (o *ShutdownOrchestrator) Phase() ShutdownPhase {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	return o.phase
+}
+
+// Status returns the current [ShutdownStatus].
+func (o *ShutdownOrchestrator) Status() *ShutdownStatus {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	events := make([]*ShutdownEvent, len(o.events))
+	copy(events, o.events)
+
+	return &ShutdownStatus{
+		Enabled: o.enabled,
+		Phase:   o.phase.String(),
+		Drain:   o.drain.String(),
+		Events:  events,
+	}
+}
+
+// Shutdown drains the application: it flips the readiness probe to failing, and waits for the drain period.
+// Once done, the application enters the stopping phase, where the other modules stop accepting new work and
+// wait for their in-flight requests and jobs, up to the application stop deadline.
+func (o *ShutdownOrchestrator) Shutdown(ctx context.Context) error {
+	if !o.enabled {
+		o.enter(ShutdownStopping, "stopping")
+
+		return nil
+	}
+
+	o.enter(ShutdownDraining, "readiness flipped to failing, draining")
+
+	timer := time.NewTimer(o.drain)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		o.enter(ShutdownStopping, "drain interrupted by stop deadline, stopping")
+	case <-timer.C:
+		o.enter(ShutdownStopping, "drain period elapsed, stopping")
+	}
+
+	return nil
+}
+
+// Terminate marks the application as stopped.
+func (o *ShutdownOrchestrator) Terminate(context.Context) error {
+	o.enter(ShutdownStopped, "stopped")
+
+	return nil
+}
+
+// Record records an event reported by a module while stopping (like the http server waiting for its in-flight
+// requests), in the current [ShutdownPhase].
+func (o *ShutdownOrchestrator) Record(module string, message string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.events = append(o.events, &ShutdownEvent{
+		Phase:   o.phase.String(),
+		Module:  module,
+		Message: message,
+		Time:    time.Now(),
+	})
+}
+
+func (o *ShutdownOrchestrator) enter(phase ShutdownPhase, message string) {
+	o.mutex.Lock()
+	o.phase = phase
+	o.events = append(o.events, &ShutdownEvent{
+		Phase:   phase.String(),
+		Message: message,
+		Time:    time.Now(),
+	})
+	o.mutex.Unlock()
+
+	o.logger.
+		Info().
+		Str("module", ModuleName).
+		Str("phase", phase.String()).
+		Str("drain", o.drain.String()).
+		Msg(message)
+}
+
+// ShutdownProbe is a readiness [healthcheck.CheckerProbe] failing as soon as the application shutdown starts.
+type ShutdownProbe struct {
+	orchestrator *ShutdownOrchestrator
+}
+
+// NewShutdownProbe returns a new [ShutdownProbe].
+func NewShutdownProbe(orchestrator *ShutdownOrchestrator) *ShutdownProbe {
+	return &ShutdownProbe{
+		orchestrator: orchestrator,
+	}
+}
+
+// Name returns the name of the [ShutdownProbe].
+func (p *ShutdownProbe) Name() string {
+	return ShutdownProbeName
+}
+
+// Check returns a successful [healthcheck.CheckerProbeResult] only if the application is running.
+func (p *ShutdownProbe) Check(context.Context) *healthcheck.CheckerProbeResult {
+	phase := p.orchestrator.Phase()
+
+	return healthcheck.NewCheckerProbeResult(phase == ShutdownRunning, phase.String())
+}
@@ -0,0 +1,140 @@
+package fxcore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxcore"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownPhaseAsString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		phase    fxcore.ShutdownPhase
+		expected string
+	}{
+		{fxcore.ShutdownRunning, "running"},
+		{fxcore.ShutdownDraining, "draining"},
+		{fxcore.ShutdownStopping, "stopping"},
+		{fxcore.ShutdownStopped, "stopped"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.phase.String())
+	}
+}
+
+func TestShutdownOrchestrator(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	orchestrator := fxcore.NewShutdownOrchestrator(logger, true, 50*time.Millisecond)
+	probe := fxcore.NewShutdownProbe(orchestrator)
+
+	assert.True(t, orchestrator.Enabled())
+	assert.Equal(t, 50*time.Millisecond, orchestrator.DrainPeriod())
+	assert.Equal(t, fxcore.ShutdownRunning, orchestrator.Phase())
+	assert.Equal(t, fxcore.ShutdownProbeName, probe.Name())
+	assert.True(t, probe.Check(context.Background()).Success)
+
+	done := make(chan struct{})
+
+	go func() {
+		err := orchestrator.Shutdown(context.Background())
+		assert.NoError(t, err)
+
+		close(done)
+	}()
+
+	assert.Eventually(
+		t,
+		func() bool {
+			return orchestrator.Phase() == fxcore.ShutdownDraining
+		},
+		time.Second,
+		time.Millisecond,
+	)
+
+	result := probe.Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "draining", result.Message)
+
+	<-done
+
+	assert.Equal(t, fxcore.ShutdownStopping, orchestrator.Phase())
+
+	err = orchestrator.Terminate(context.Background())
+	assert.NoError(t, err)
+
+	status := orchestrator.Status()
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "stopped", status.Phase)
+	assert.Equal(t, "50ms", status.Drain)
+	assert.Len(t, status.Events, 3)
+	assert.Equal(t, "draining", status.Events[0].Phase)
+	assert.Equal(t, "stopping", status.Events[1].Phase)
+	assert.Equal(t, "drain period elapsed, stopping", status.Events[1].Message)
+	assert.Equal(t, "stopped", status.Events[2].Phase)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"phase":   "draining",
+		"drain":   "50ms",
+		"message": "readiness flipped to failing, draining",
+	})
+}
+
+func TestShutdownOrchestratorWithCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	orchestrator := fxcore.NewShutdownOrchestrator(log.CtxLogger(context.Background()), true, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := orchestrator.Shutdown(ctx)
+	assert.NoError(t, err)
+
+	status := orchestrator.Status()
+	assert.Equal(t, "stopping", status.Phase)
+	assert.Equal(t, "drain interrupted by stop deadline, stopping", status.Events[1].Message)
+}
+
+func TestShutdownOrchestratorWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	orchestrator := fxcore.NewShutdownOrchestrator(log.CtxLogger(context.Background()), false, time.Hour)
+
+	err := orchestrator.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	status := orchestrator.Status()
+	assert.False(t, status.Enabled)
+	assert.Equal(t, "stopping", status.Phase)
+	assert.Len(t, status.Events, 1)
+}
+
+func TestShutdownOrchestratorRecord(t *testing.T) {
+	t.Parallel()
+
+	orchestrator := fxcore.NewShutdownOrchestrator(log.CtxLogger(context.Background()), true, 0)
+
+	err := orchestrator.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	orchestrator.Record("httpserver", "waiting for in-flight requests")
+
+	status := orchestrator.Status()
+	assert.Len(t, status.Events, 3)
+	assert.Equal(t, "stopping", status.Events[2].Phase)
+	assert.Equal(t, "httpserver", status.Events[2].Module)
+	assert.Equal(t, "waiting for in-flight requests", status.Events[2].Message)
+}
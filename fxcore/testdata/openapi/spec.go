@@ -0,0 +1,29 @@
+package openapi
+
+import "fmt"
+
+type TestOpenApiSpec struct {
+	err error
+}
+
+func NewTestOpenApiSpec() *TestOpenApiSpec {
+	return &TestOpenApiSpec{}
+}
+
+func NewTestFailingOpenApiSpec() *TestOpenApiSpec {
+	return &TestOpenApiSpec{
+		err: fmt.Errorf("test error"),
+	}
+}
+
+func (s *TestOpenApiSpec) Name() string {
+	return "test"
+}
+
+func (s *TestOpenApiSpec) OpenApiSpec() ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return []byte(`{"openapi":"3.1.0","info":{"title":"test","version":"0.1.0"},"paths":{}}`), nil
+}
@@ -0,0 +1,22 @@
+package routes
+
+type TestRoutes struct{}
+
+func NewTestRoutes() *TestRoutes {
+	return &TestRoutes{}
+}
+
+func (r *TestRoutes) Name() string {
+	return "test"
+}
+
+func (r *TestRoutes) Routes() []map[string]any {
+	return []map[string]any{
+		{
+			"server":   "test",
+			"method":   "GET",
+			"path":     "/test",
+			"policies": []string{"scopes=read"},
+		},
+	}
+}
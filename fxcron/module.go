@@ -8,6 +8,7 @@ import (
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/trace"
 	"github.com/go-co-op/gocron/v2"
@@ -44,14 +45,15 @@ var FxCronModule = fx.Module(
 // FxCronParam allows injection of the required dependencies in [NewFxCron].
 type FxCronParam struct {
 	fx.In
-	LifeCycle       fx.Lifecycle
-	Generator       uuid.UuidGenerator
-	TracerProvider  oteltrace.TracerProvider
-	Factory         CronSchedulerFactory
-	Config          *config.Config
-	Registry        *CronJobRegistry
-	Logger          *log.Logger
-	MetricsRegistry *prometheus.Registry
+	LifeCycle        fx.Lifecycle
+	Generator        uuid.UuidGenerator
+	TracerProvider   oteltrace.TracerProvider
+	Factory          CronSchedulerFactory
+	Config           *config.Config
+	Registry         *CronJobRegistry
+	Logger           *log.Logger
+	MetricsRegistry  *prometheus.Registry
+	ShutdownRecorder healthcheck.ShutdownRecorder `optional:"true"`
 }
 
 // NewFxCron returns a new [gocron.Scheduler].
@@ -219,9 +221,7 @@ func NewFxCron(p FxCronParam) (gocron.Scheduler, error) {
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			cronLogger.Debug().Msg("stopping cron scheduler")
-
-			return cronScheduler.Shutdown()
+			return shutdownCronScheduler(ctx, cronScheduler, cronLogger, p.ShutdownRecorder)
 		},
 	})
 
@@ -0,0 +1,39 @@
+package fxcron
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/go-co-op/gocron/v2"
+)
+
+// shutdownCronScheduler stops the cron scheduler from scheduling new jobs executions, and waits for the in-flight ones
+// up to the context deadline (or the modules.cron.scheduler.stop.timeout, if shorter).
+func shutdownCronScheduler(ctx context.Context, cronScheduler gocron.Scheduler, logger *log.Logger, recorder healthcheck.ShutdownRecorder) error {
+	healthcheck.RecordShutdown(logger, recorder, ModuleName, "cron scheduler stopped scheduling new jobs executions, waiting for in-flight executions")
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- cronScheduler.Shutdown()
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			healthcheck.RecordShutdown(logger, recorder, ModuleName, fmt.Sprintf("cron scheduler stopped with error: %v", err))
+
+			return err
+		}
+
+		healthcheck.RecordShutdown(logger, recorder, ModuleName, "cron scheduler in-flight executions completed, stopped")
+
+		return nil
+	case <-ctx.Done():
+		healthcheck.RecordShutdown(logger, recorder, ModuleName, "cron scheduler stop deadline reached, abandoning in-flight executions")
+
+		return nil
+	}
+}
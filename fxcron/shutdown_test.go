@@ -0,0 +1,133 @@
+package fxcron_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxcron"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/shutdown"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type sleepCron struct {
+	duration time.Duration
+}
+
+func (c *sleepCron) Name() string {
+	return "sleep"
+}
+
+func (c *sleepCron) Run(context.Context) error {
+	// ignores the context cancellation, to simulate an execution exceeding the stop timeouts
+	time.Sleep(c.duration)
+
+	return nil
+}
+
+func TestModuleShutdown(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("CRON_START_IMMEDIATELY", "true")
+	t.Setenv("MODULES_CRON_METRICS_COLLECT_ENABLED", "false")
+
+	tests := []struct {
+		name          string
+		duration      time.Duration
+		stopTimeout   string
+		expectedError string
+		expected      []string
+	}{
+		{
+			name:     "in-flight executions completed",
+			duration: 100 * time.Millisecond,
+			expected: []string{
+				"cron: cron scheduler stopped scheduling new jobs executions, waiting for in-flight executions",
+				"cron: cron scheduler in-flight executions completed, stopped",
+			},
+		},
+		{
+			name:          "stop deadline reached",
+			duration:      2 * time.Second,
+			expectedError: context.DeadlineExceeded.Error(),
+			expected: []string{
+				"cron: cron scheduler stopped scheduling new jobs executions, waiting for in-flight executions",
+				"cron: cron scheduler stop deadline reached, abandoning in-flight executions",
+			},
+		},
+		{
+			name:          "scheduler stop timeout reached",
+			duration:      time.Second,
+			stopTimeout:   "50ms",
+			expectedError: gocron.ErrStopJobsTimedOut.Error(),
+			expected: []string{
+				"cron: cron scheduler stopped scheduling new jobs executions, waiting for in-flight executions",
+				"cron: cron scheduler stopped with error: " + gocron.ErrStopJobsTimedOut.Error(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.stopTimeout != "" {
+				t.Setenv("MODULES_CRON_SCHEDULER_STOP_TIMEOUT", tt.stopTimeout)
+			}
+
+			recorder := shutdown.NewTestShutdownRecorder()
+
+			app := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxcron.FxCronModule,
+				fx.Provide(
+					fx.Annotate(
+						func() *shutdown.TestShutdownRecorder {
+							return recorder
+						},
+						fx.As(new(healthcheck.ShutdownRecorder)),
+					),
+				),
+				fxcron.AsCronJob(
+					func() *sleepCron {
+						return &sleepCron{duration: tt.duration}
+					},
+					`*/1 * * * * *`,
+					gocron.WithLimitedRuns(1),
+				),
+				fx.Invoke(func(gocron.Scheduler) {}),
+			)
+
+			assert.NoError(t, app.Start(context.Background()))
+
+			// time for the cron job to start its execution
+			time.Sleep(50 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			err := app.Stop(ctx)
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Eventually(t, func() bool {
+				return assert.ObjectsAreEqual(tt.expected, recorder.Messages())
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
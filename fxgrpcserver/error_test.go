@@ -0,0 +1,145 @@
+package fxgrpcserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxgrpcserver"
+	"github.com/ankorstore/yokai/fxgrpcserver/testdata/proto"
+	"github.com/ankorstore/yokai/fxgrpcserver/testdata/service"
+	"github.com/ankorstore/yokai/fxhealthcheck"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/ankorstore/yokai/grpcserver/grpcservertest"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var errTestNotFound = errors.New("test not found")
+
+type testErrorStatusResolver struct{}
+
+func (r *testErrorStatusResolver) ResolveStatus(err error) (int, string, bool) {
+	if errors.Is(err, errTestNotFound) {
+		return http.StatusNotFound, "Not Found", true
+	}
+
+	return 0, "", false
+}
+
+type testNotFoundUnaryInterceptor struct{}
+
+func (i *testNotFoundUnaryInterceptor) HandleUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if r, ok := req.(*proto.Request); ok && r.Message == "not found" {
+			return nil, errTestNotFound
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func TestModuleWithErrorStatusResolver(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	tests := []struct {
+		name     string
+		resolver bool
+		expected codes.Code
+	}{
+		{
+			name:     "with resolver",
+			resolver: true,
+			expected: codes.NotFound,
+		},
+		{
+			name:     "without resolver",
+			resolver: false,
+			expected: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grpcServer *grpc.Server
+			var connFactory grpcservertest.TestBufconnConnectionFactory
+			var logBuffer logtest.TestLogBuffer
+
+			options := []fx.Option{
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxgenerate.FxGenerateModule,
+				fxmetrics.FxMetricsModule,
+				fxhealthcheck.FxHealthcheckModule,
+				fxgrpcserver.FxGrpcServerModule,
+				fx.Provide(service.NewTestServiceDependency),
+				fxgrpcserver.AsGrpcServerUnaryInterceptor(func() *testNotFoundUnaryInterceptor {
+					return &testNotFoundUnaryInterceptor{}
+				}),
+				fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+				fx.Populate(&grpcServer, &connFactory, &logBuffer),
+			}
+
+			if tt.resolver {
+				options = append(
+					options,
+					fx.Provide(
+						fx.Annotate(
+							func() *testErrorStatusResolver {
+								return &testErrorStatusResolver{}
+							},
+							fx.As(fx.Self()),
+							fx.As(new(grpcserver.ErrorStatusResolver)),
+						),
+					),
+					fx.Invoke(func(*testErrorStatusResolver) {}),
+				)
+			}
+
+			fxtest.New(t, options...).RequireStart().RequireStop()
+
+			defer func() {
+				grpcServer.GracefulStop()
+			}()
+
+			conn, err := connFactory.Create(
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)
+			assert.NoError(t, err)
+
+			client := proto.NewServiceClient(conn)
+
+			// domain error mapped to a grpc status
+			_, err = client.Unary(context.Background(), &proto.Request{Message: "not found"})
+			assert.Error(t, err)
+			assert.Equal(t, tt.expected, status.Code(err))
+
+			// grpc status errors kept as is
+			_, err = client.Unary(context.Background(), &proto.Request{ShouldFail: true, Message: "test"})
+			assert.Error(t, err)
+			assert.Equal(t, codes.Internal, status.Code(err))
+
+			// the logger observes the mapped code
+			logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+				"level":      "error",
+				"grpcMethod": "/test.Service/Unary",
+				"grpcCode":   int(tt.expected),
+			})
+		})
+	}
+}
@@ -13,14 +13,14 @@ require (
 	github.com/ankorstore/yokai/fxmetrics v1.2.0
 	github.com/ankorstore/yokai/fxtrace v1.2.0
 	github.com/ankorstore/yokai/generate v1.2.0
-	github.com/ankorstore/yokai/grpcserver v1.2.0
-	github.com/ankorstore/yokai/healthcheck v1.1.0
+	github.com/ankorstore/yokai/grpcserver v1.5.0
+	github.com/ankorstore/yokai/healthcheck v1.4.0
 	github.com/ankorstore/yokai/log v1.2.0
 	github.com/ankorstore/yokai/trace v1.3.0
 	github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus v1.0.1
 	github.com/grpc-ecosystem/go-grpc-middleware/v2 v2.1.0
 	github.com/prometheus/client_golang v1.20.4
-	github.com/stretchr/testify v1.9.0
+	github.com/stretchr/testify v1.11.1
 	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.49.0
 	go.opentelemetry.io/otel/trace v1.24.0
 	go.uber.org/fx v1.22.2
@@ -82,16 +82,19 @@ func NewFxGrpcDefaultTestBufconnConnectionFactory(p FxGrpcTestBufconnConnectionF
 // FxGrpcServerParam allows injection of the required dependencies in [NewFxGrpcBufconnListener].
 type FxGrpcServerParam struct {
 	fx.In
-	LifeCycle       fx.Lifecycle
-	Factory         grpcserver.GrpcServerFactory
-	Generator       uuid.UuidGenerator
-	Listener        *bufconn.Listener
-	Registry        *GrpcServerRegistry
-	Config          *config.Config
-	Logger          *log.Logger
-	Checker         *healthcheck.Checker
-	TracerProvider  trace.TracerProvider
-	MetricsRegistry *prometheus.Registry
+	LifeCycle           fx.Lifecycle
+	Factory             grpcserver.GrpcServerFactory
+	Generator           uuid.UuidGenerator
+	Listener            *bufconn.Listener
+	Registry            *GrpcServerRegistry
+	Config              *config.Config
+	Logger              *log.Logger
+	Checker             *healthcheck.Checker
+	TracerProvider      trace.TracerProvider
+	MetricsRegistry     *prometheus.Registry
+	CoreInterceptors    []any                          `group:"core-grpc-interceptors"`
+	ErrorStatusResolver grpcserver.ErrorStatusResolver `optional:"true"`
+	ShutdownRecorder    healthcheck.ShutdownRecorder   `optional:"true"`
 }
 
 // NewFxGrpcServer returns a new [grpc.Server].
@@ -101,6 +104,17 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 	// server interceptors
 	unaryInterceptors, streamInterceptors := createInterceptors(p)
 
+	// core interceptors (like the fxcore maintenance mode one)
+	for _, coreInterceptor := range p.CoreInterceptors {
+		if unaryInterceptor, ok := coreInterceptor.(GrpcServerUnaryInterceptor); ok {
+			unaryInterceptors = append(unaryInterceptors, unaryInterceptor.HandleUnary())
+		}
+
+		if streamInterceptor, ok := coreInterceptor.(GrpcServerStreamInterceptor); ok {
+			streamInterceptors = append(streamInterceptors, streamInterceptor.HandleStream())
+		}
+	}
+
 	for _, unaryInterceptor := range p.Registry.ResolveGrpcServerUnaryInterceptors() {
 		unaryInterceptors = append(unaryInterceptors, unaryInterceptor.HandleUnary())
 	}
@@ -128,8 +142,14 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 	}
 
 	// server healthcheck registration
+	var healthCheckService *grpcserver.GrpcHealthCheckService
 	if p.Config.GetBool("modules.grpc.server.healthcheck.enabled") {
-		grpcServer.RegisterService(&grpc_health_v1.Health_ServiceDesc, grpcserver.NewGrpcHealthCheckService(p.Checker))
+		healthCheckService = grpcserver.NewGrpcHealthCheckService(
+			p.Checker,
+			grpcserver.WithWatchInterval(p.Config.GetDuration("modules.grpc.server.healthcheck.watch_interval")),
+		)
+
+		grpcServer.RegisterService(&grpc_health_v1.Health_ServiceDesc, healthCheckService)
 	}
 
 	// server services registration
@@ -172,7 +192,12 @@ func NewFxGrpcServer(p FxGrpcServerParam) (*grpc.Server, error) {
 		},
 		OnStop: func(ctx context.Context) error {
 			if !p.Config.IsTestEnv() {
-				grpcServer.GracefulStop()
+				// ends the health check watch streams, which would otherwise block the graceful stop
+				if healthCheckService != nil {
+					healthCheckService.Shutdown()
+				}
+
+				shutdownGrpcServer(ctx, grpcServer, p)
 			}
 
 			return nil
@@ -298,5 +323,13 @@ func createInterceptors(p FxGrpcServerParam) ([]grpc.UnaryServerInterceptor, []g
 		)
 	}
 
+	// errors statuses, mapped like the http server errors (after the logger and metrics, to observe the mapped codes)
+	if p.ErrorStatusResolver != nil {
+		errorStatusInterceptor := grpcserver.NewGrpcErrorStatusInterceptor(p.ErrorStatusResolver)
+
+		unaryInterceptors = append(unaryInterceptors, errorStatusInterceptor.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, errorStatusInterceptor.StreamInterceptor())
+	}
+
 	return unaryInterceptors, streamInterceptors
 }
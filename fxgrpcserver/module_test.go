@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxgenerate"
@@ -22,6 +23,7 @@ import (
 	"github.com/ankorstore/yokai/fxtrace"
 	"github.com/ankorstore/yokai/grpcserver/grpcservertest"
 	"github.com/ankorstore/yokai/healthcheck"
+	healthcheckprobes "github.com/ankorstore/yokai/healthcheck/testdata/probes"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/ankorstore/yokai/trace/tracetest"
 	"github.com/prometheus/client_golang/prometheus"
@@ -437,6 +439,64 @@ func TestModuleHealthCheck(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestModuleHealthCheckWatch(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GRPC_HEALTHCHECK_WATCH_INTERVAL", "10ms")
+
+	switchProbe := healthcheckprobes.NewSwitchProbe(true)
+
+	var grpcServer *grpc.Server
+	var connFactory grpcservertest.TestBufconnConnectionFactory
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fxhealthcheck.AsCheckerProbe(
+			func() *healthcheckprobes.SwitchProbe {
+				return switchProbe
+			},
+			healthcheck.Readiness,
+		),
+		fx.Populate(&grpcServer, &connFactory),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := connFactory.Create(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// the status change is streamed within the configured watch interval (default 5s), before the context timeout
+	switchProbe.Switch(false)
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+}
+
 func TestModuleDecoration(t *testing.T) {
 	t.Setenv("APP_CONFIG_PATH", "testdata/config")
 	t.Setenv("APP_ENV", "test")
@@ -467,3 +527,59 @@ func TestModuleDecoration(t *testing.T) {
 	assert.Contains(t, fmt.Sprintf("%+v", info), "grpc.health.v1.Health")
 	assert.NotContains(t, fmt.Sprintf("%+v", info), "grpc.reflection.v1alpha.ServerReflection")
 }
+
+func TestModuleWithCoreInterceptors(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	var grpcServer *grpc.Server
+	var connFactory grpcservertest.TestBufconnConnectionFactory
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(
+			service.NewTestServiceDependency,
+			fx.Annotate(
+				func(dependency *service.TestServiceDependency) []any {
+					// the non interceptor items of the group are ignored
+					return []any{interceptor.NewUnaryInterceptor(dependency), "invalid"}
+				},
+				fx.ResultTags(`group:"core-grpc-interceptors,flatten"`),
+			),
+		),
+		fxgrpcserver.AsGrpcServerService(service.NewTestServiceServer, &proto.Service_ServiceDesc),
+		fx.Populate(&grpcServer, &connFactory, &logBuffer),
+	).RequireStart().RequireStop()
+
+	defer func() {
+		grpcServer.GracefulStop()
+	}()
+
+	conn, err := connFactory.Create(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+
+	client := proto.NewServiceClient(conn)
+
+	response, err := client.Unary(context.Background(), &proto.Request{
+		ShouldFail: false,
+		Message:    "test",
+	})
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "in unary interceptor of test",
+	})
+}
@@ -0,0 +1,34 @@
+package fxgrpcserver
+
+import (
+	"context"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"google.golang.org/grpc"
+)
+
+// shutdownGrpcServer stops the grpc server from accepting new RPCs, and waits for the in-flight ones up to the
+// context deadline, after which the remaining connections are closed.
+func shutdownGrpcServer(ctx context.Context, grpcServer *grpc.Server, p FxGrpcServerParam) {
+	logger := log.FromZerolog(p.Logger.ToZerolog().With().Str("module", ModuleName).Logger())
+
+	healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, "grpc server stopped accepting new RPCs, waiting for in-flight RPCs")
+
+	stopped := make(chan struct{})
+
+	go func() {
+		grpcServer.GracefulStop()
+
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, "grpc server in-flight RPCs completed, stopped")
+	case <-ctx.Done():
+		healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, "grpc server stop deadline reached, closing in-flight RPCs")
+
+		grpcServer.Stop()
+	}
+}
@@ -0,0 +1,116 @@
+package fxgrpcserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxgrpcserver"
+	"github.com/ankorstore/yokai/fxhealthcheck"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/shutdown"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	address := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	return address
+}
+
+func TestModuleShutdownWithHealthCheckWatch(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("GRPC_HEALTHCHECK_WATCH_INTERVAL", "1h")
+
+	address := freeAddress(t)
+	t.Setenv("MODULES_GRPC_SERVER_ADDRESS", address)
+
+	recorder := shutdown.NewTestShutdownRecorder()
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxgenerate.FxGenerateModule,
+		fxmetrics.FxMetricsModule,
+		fxhealthcheck.FxHealthcheckModule,
+		fxgrpcserver.FxGrpcServerModule,
+		fx.Provide(
+			fx.Annotate(
+				func() *shutdown.TestShutdownRecorder {
+					return recorder
+				},
+				fx.As(new(healthcheck.ShutdownRecorder)),
+			),
+		),
+		fx.Invoke(func(*grpc.Server) {}),
+	)
+
+	assert.NoError(t, app.Start(context.Background()))
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	var stream grpc_health_v1.Health_WatchClient
+
+	ok := assert.Eventually(t, func() bool {
+		stream, err = client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+		if err != nil {
+			return false
+		}
+
+		response, err := stream.Recv()
+
+		return err == nil && response.Status == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	if !ok {
+		return
+	}
+
+	// the watch stream is ended on stop, and does not block the graceful stop up to the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	assert.NoError(t, app.Stop(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	assert.Equal(
+		t,
+		[]string{
+			"grpcserver: grpc server stopped accepting new RPCs, waiting for in-flight RPCs",
+			"grpcserver: grpc server in-flight RPCs completed, stopped",
+		},
+		recorder.Messages(),
+	)
+}
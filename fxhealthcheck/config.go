@@ -0,0 +1,110 @@
+package fxhealthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+)
+
+const DefaultRemoteProbeTimeout = 5
+
+// ResolveConfiguredProbesRegistrations resolves the remote dependencies probes declared in config under modules.healthcheck.probes.
+// The provided [http.Client] is used by the HTTP probes.
+//
+//nolint:cyclop
+func ResolveConfiguredProbesRegistrations(cfg *config.Config, client *http.Client) ([]*healthcheck.CheckerProbeRegistration, error) {
+	registrations := []*healthcheck.CheckerProbeRegistration{}
+
+	if cfg == nil {
+		return registrations, nil
+	}
+
+	names := []string{}
+	for name := range cfg.GetStringMap("modules.healthcheck.probes") {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := fmt.Sprintf("modules.healthcheck.probes.%s", name)
+
+		target := cfg.GetString(key + ".target")
+		if target == "" {
+			return nil, fmt.Errorf("missing target for probe %s", name)
+		}
+
+		timeout := DefaultRemoteProbeTimeout
+		if cfg.IsSet(key + ".timeout") {
+			timeout = cfg.GetInt(key + ".timeout")
+		}
+
+		critical := true
+		if cfg.IsSet(key + ".critical") {
+			critical = cfg.GetBool(key + ".critical")
+		}
+
+		options := RemoteProbeOptions{
+			Timeout:  time.Duration(timeout) * time.Second,
+			Critical: critical,
+		}
+
+		kinds, err := resolveProbeKinds(cfg.GetStringSlice(key + ".kinds"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid kinds for probe %s: %w", name, err)
+		}
+
+		var probe healthcheck.CheckerProbe
+
+		switch strings.ToLower(cfg.GetString(key + ".type")) {
+		case RemoteProbeTypeHttp:
+			probe = NewHttpProbe(name, client, target, HttpProbeOptions{
+				RemoteProbeOptions: options,
+				Method:             strings.ToUpper(cfg.GetString(key + ".http.method")),
+				ExpectedStatus:     cfg.GetInt(key + ".http.status"),
+				ExpectedBody:       cfg.GetString(key + ".http.body"),
+			})
+		case RemoteProbeTypeGrpc:
+			probe = NewGrpcProbe(name, target, GrpcProbeOptions{
+				RemoteProbeOptions: options,
+				Service:            cfg.GetString(key + ".grpc.service"),
+			})
+		case RemoteProbeTypeTcp:
+			probe = NewTcpProbe(name, target, options)
+		default:
+			return nil, fmt.Errorf("invalid type %q for probe %s", cfg.GetString(key+".type"), name)
+		}
+
+		registrations = append(registrations, healthcheck.NewCheckerProbeRegistration(probe, kinds...))
+	}
+
+	return registrations, nil
+}
+
+func resolveProbeKinds(kinds []string) ([]healthcheck.ProbeKind, error) {
+	if len(kinds) == 0 {
+		return []healthcheck.ProbeKind{healthcheck.Readiness}, nil
+	}
+
+	resolved := []healthcheck.ProbeKind{}
+
+	for _, kind := range kinds {
+		switch strings.ToLower(kind) {
+		case healthcheck.Startup.String():
+			resolved = append(resolved, healthcheck.Startup)
+		case healthcheck.Liveness.String():
+			resolved = append(resolved, healthcheck.Liveness)
+		case healthcheck.Readiness.String():
+			resolved = append(resolved, healthcheck.Readiness)
+		default:
+			return nil, fmt.Errorf("unknown probe kind %q", kind)
+		}
+	}
+
+	return resolved, nil
+}
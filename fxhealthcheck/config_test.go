@@ -0,0 +1,86 @@
+package fxhealthcheck_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxhealthcheck"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveConfiguredProbesRegistrations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	t.Setenv("HTTP_PROBE_TARGET", server.URL)
+	t.Setenv("GRPC_PROBE_TARGET", "127.0.0.1:1")
+	t.Setenv("TCP_PROBE_TARGET", lis.Addr().String())
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	registrations, err := fxhealthcheck.ResolveConfiguredProbesRegistrations(cfg, server.Client())
+	assert.NoError(t, err)
+	assert.Len(t, registrations, 3)
+
+	assert.IsType(t, &fxhealthcheck.HttpProbe{}, registrations[0].Probe())
+	assert.Equal(t, "partner-api", registrations[0].Probe().Name())
+	assert.Equal(t, []healthcheck.ProbeKind{healthcheck.Startup, healthcheck.Readiness}, registrations[0].Kinds())
+
+	assert.IsType(t, &fxhealthcheck.GrpcProbe{}, registrations[1].Probe())
+	assert.Equal(t, "partner-grpc", registrations[1].Probe().Name())
+	assert.Equal(t, []healthcheck.ProbeKind{healthcheck.Readiness}, registrations[1].Kinds())
+
+	assert.IsType(t, &fxhealthcheck.TcpProbe{}, registrations[2].Probe())
+	assert.Equal(t, "partner-tcp", registrations[2].Probe().Name())
+	assert.Equal(t, []healthcheck.ProbeKind{healthcheck.Readiness}, registrations[2].Kinds())
+}
+
+func TestResolveConfiguredProbesRegistrationsWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	registrations, err := fxhealthcheck.ResolveConfiguredProbesRegistrations(nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, registrations, 0)
+}
+
+func TestResolveConfiguredProbesRegistrationsWithInvalidConfig(t *testing.T) {
+	tests := []struct {
+		probeType   string
+		probeTarget string
+		probeKind   string
+		expectedErr string
+	}{
+		{"tcp", "", "readiness", "missing target for probe invalid"},
+		{"invalid", "localhost:80", "readiness", `invalid type "invalid" for probe invalid`},
+		{"tcp", "localhost:80", "invalid", `invalid kinds for probe invalid: unknown probe kind "invalid"`},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("PROBE_TYPE", tt.probeType)
+		t.Setenv("PROBE_TARGET", tt.probeTarget)
+		t.Setenv("PROBE_KIND", tt.probeKind)
+
+		cfg, err := config.NewDefaultConfigFactory().Create(
+			config.WithFilePaths("./testdata/config/invalid"),
+		)
+		assert.NoError(t, err)
+
+		_, err = fxhealthcheck.ResolveConfiguredProbesRegistrations(cfg, nil)
+		assert.Error(t, err)
+		assert.Equal(t, tt.expectedErr, err.Error())
+	}
+}
@@ -1,6 +1,9 @@
 package fxhealthcheck
 
 import (
+	"net/http"
+
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/healthcheck"
 	"go.uber.org/fx"
 )
@@ -17,14 +20,19 @@ var FxHealthcheckModule = fx.Module(
 		healthcheck.NewDefaultCheckerFactory,
 		NewFxCheckerProbeRegistry,
 		NewFxChecker,
+		NewFxMonitor,
 	),
+	// the monitor is started with the application, if enabled
+	fx.Invoke(func(*healthcheck.Monitor) {}),
 )
 
 // FxCheckerParam allows injection of the required dependencies in [NewFxChecker].
 type FxCheckerParam struct {
 	fx.In
-	Factory  healthcheck.CheckerFactory
-	Registry *CheckerProbeRegistry
+	Factory    healthcheck.CheckerFactory
+	Registry   *CheckerProbeRegistry
+	Config     *config.Config `optional:"true"`
+	HttpClient *http.Client   `optional:"true"`
 }
 
 // NewFxChecker returns a new [healthcheck.Checker].
@@ -34,6 +42,13 @@ func NewFxChecker(p FxCheckerParam) (*healthcheck.Checker, error) {
 		return nil, err
 	}
 
+	configuredRegistrations, err := ResolveConfiguredProbesRegistrations(p.Config, p.HttpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	registrations = append(registrations, configuredRegistrations...)
+
 	options := []healthcheck.CheckerOption{}
 	for _, registration := range registrations {
 		options = append(options, healthcheck.WithProbe(registration.Probe(), registration.Kinds()...))
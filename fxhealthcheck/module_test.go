@@ -3,8 +3,10 @@ package fxhealthcheck_test
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"testing"
 
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxhealthcheck"
 	"github.com/ankorstore/yokai/fxhealthcheck/testdata/factory"
 	"github.com/ankorstore/yokai/fxhealthcheck/testdata/probes"
@@ -86,3 +88,42 @@ func TestModuleDecoration(t *testing.T) {
 	assert.True(t, checker.Check(ctx, healthcheck.Liveness).Success)
 	assert.False(t, checker.Check(ctx, healthcheck.Readiness).Success)
 }
+
+func TestModuleWithConfiguredProbes(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	t.Setenv("HTTP_PROBE_TARGET", "http://127.0.0.1:1")
+	t.Setenv("GRPC_PROBE_TARGET", "127.0.0.1:1")
+	t.Setenv("TCP_PROBE_TARGET", lis.Addr().String())
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config"),
+	)
+	assert.NoError(t, err)
+
+	var checker *healthcheck.Checker
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxhealthcheck.FxHealthcheckModule,
+		fx.Supply(cfg),
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fx.Populate(&checker),
+	).RequireStart().RequireStop()
+
+	assert.Len(t, checker.Probes(healthcheck.Liveness), 1)
+	assert.Len(t, checker.Probes(healthcheck.Startup), 2)
+	assert.Len(t, checker.Probes(healthcheck.Readiness), 4)
+
+	result := checker.Check(context.Background(), healthcheck.Readiness)
+	assert.False(t, result.Success)
+
+	assert.True(t, result.ProbesResults["successProbe"].Success)
+	assert.True(t, result.ProbesResults["partner-tcp"].Success)
+	assert.False(t, result.ProbesResults["partner-api"].Success)
+	assert.True(t, result.ProbesResults["partner-grpc"].Success)
+	assert.Contains(t, result.ProbesResults["partner-grpc"].Message, "non critical failure: grpc health check error")
+}
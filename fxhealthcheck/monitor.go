@@ -0,0 +1,79 @@
+package fxhealthcheck
+
+import (
+	"context"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+// FxMonitorParam allows injection of the required dependencies in [NewFxMonitor].
+type FxMonitorParam struct {
+	fx.In
+	LifeCycle         fx.Lifecycle
+	Checker           *healthcheck.Checker
+	Config            *config.Config                        `optional:"true"`
+	Logger            *log.Logger                           `optional:"true"`
+	MetricsRegistry   *prometheus.Registry                  `optional:"true"`
+	StatusChangeFuncs []healthcheck.MonitorStatusChangeFunc `group:"healthcheck-monitor-status-change-funcs"`
+}
+
+// NewFxMonitor returns a new [healthcheck.Monitor], executing the probes in background with the application
+// if modules.healthcheck.monitor.enabled=true.
+func NewFxMonitor(p FxMonitorParam) (*healthcheck.Monitor, error) {
+	if p.Config == nil || !p.Config.GetBool("modules.healthcheck.monitor.enabled") {
+		return healthcheck.NewMonitor(p.Checker, healthcheck.WithMonitorMetrics(nil)), nil
+	}
+
+	options := []healthcheck.MonitorOption{
+		healthcheck.WithMonitorInterval(p.Config.GetDuration("modules.healthcheck.monitor.interval")),
+		healthcheck.WithMonitorHistorySize(p.Config.GetInt("modules.healthcheck.monitor.history")),
+	}
+
+	if p.Config.IsSet("modules.healthcheck.monitor.timeout") {
+		options = append(options, healthcheck.WithMonitorTimeout(p.Config.GetDuration("modules.healthcheck.monitor.timeout")))
+	}
+
+	// metrics
+	if p.MetricsRegistry != nil && p.Config.GetBool("modules.healthcheck.monitor.metrics.enabled") {
+		metrics := healthcheck.NewMonitorMetrics(
+			p.Config.GetString("modules.healthcheck.monitor.metrics.namespace"),
+			p.Config.GetString("modules.healthcheck.monitor.metrics.subsystem"),
+		)
+
+		if err := metrics.Register(p.MetricsRegistry); err != nil {
+			return nil, err
+		}
+
+		options = append(options, healthcheck.WithMonitorMetrics(metrics))
+	} else {
+		options = append(options, healthcheck.WithMonitorMetrics(nil))
+	}
+
+	for _, fn := range p.StatusChangeFuncs {
+		options = append(options, healthcheck.WithMonitorStatusChangeFunc(fn))
+	}
+
+	monitor := healthcheck.NewMonitor(p.Checker, options...)
+
+	// the monitor runs with the application, and not with the start context
+	monitorCtx := context.Background()
+	if p.Logger != nil {
+		monitorCtx = log.FromZerolog(p.Logger.ToZerolog().With().Str("module", ModuleName).Logger()).
+			WithContext(monitorCtx)
+	}
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return monitor.Start(monitorCtx)
+		},
+		OnStop: func(context.Context) error {
+			return monitor.Stop()
+		},
+	})
+
+	return monitor, nil
+}
@@ -0,0 +1,99 @@
+package fxhealthcheck_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxhealthcheck"
+	"github.com/ankorstore/yokai/fxhealthcheck/testdata/probes"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithMonitor(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.NewDefaultConfigFactory().Create(
+		config.WithFilePaths("./testdata/config/monitor"),
+	)
+	assert.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+
+	var mutex sync.Mutex
+	var events []*healthcheck.MonitorEvent
+
+	var monitor *healthcheck.Monitor
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxhealthcheck.FxHealthcheckModule,
+		fx.Supply(cfg, registry),
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fxhealthcheck.AsCheckerProbe(probes.NewFailureProbe),
+		fxhealthcheck.AsMonitorStatusChangeFunc(func(ctx context.Context, event *healthcheck.MonitorEvent) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			events = append(events, event)
+		}),
+		fx.Populate(&monitor),
+	).RequireStart()
+
+	assert.Equal(t, 10*time.Millisecond, monitor.Options().Interval)
+	assert.Equal(t, time.Second, monitor.Options().Timeout)
+	assert.Equal(t, 3, monitor.Options().HistorySize)
+
+	assert.Eventually(
+		t,
+		func() bool {
+			return len(monitor.History("successProbe")) == 3 && len(monitor.History("failureProbe")) == 3
+		},
+		time.Second,
+		10*time.Millisecond,
+	)
+
+	app.RequireStop()
+
+	mutex.Lock()
+	assert.Len(t, events, 1)
+	assert.Nil(t, events[0].Previous)
+	assert.Equal(t, "failureProbe", events[0].Current.Probe)
+	mutex.Unlock()
+
+	expectedMetric := `
+		# HELP foo_bar_healthcheck_probe_status Status of the last background probe execution (1 for success, 0 for failure)
+		# TYPE foo_bar_healthcheck_probe_status gauge
+		foo_bar_healthcheck_probe_status{probe="failureProbe"} 0
+		foo_bar_healthcheck_probe_status{probe="successProbe"} 1
+	`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_healthcheck_probe_status")
+	assert.NoError(t, err)
+}
+
+func TestModuleWithDisabledMonitor(t *testing.T) {
+	t.Parallel()
+
+	var monitor *healthcheck.Monitor
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxhealthcheck.FxHealthcheckModule,
+		fxhealthcheck.AsCheckerProbe(probes.NewSuccessProbe),
+		fx.Populate(&monitor),
+	).RequireStart().RequireStop()
+
+	assert.Empty(t, monitor.Histories())
+	assert.Nil(t, monitor.Options().Metrics)
+}
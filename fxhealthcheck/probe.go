@@ -0,0 +1,226 @@
+package fxhealthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	RemoteProbeTypeHttp = "http"
+	RemoteProbeTypeGrpc = "grpc"
+	RemoteProbeTypeTcp  = "tcp"
+)
+
+// RemoteProbeOptions are the options common to the remote dependencies probes.
+type RemoteProbeOptions struct {
+	Timeout  time.Duration
+	Critical bool
+}
+
+// HttpProbeOptions are the options of the [HttpProbe].
+type HttpProbeOptions struct {
+	RemoteProbeOptions
+	Method         string
+	ExpectedStatus int
+	ExpectedBody   string
+}
+
+// HttpProbe is a [healthcheck.CheckerProbe] calling a remote HTTP dependency.
+type HttpProbe struct {
+	name    string
+	client  *http.Client
+	url     string
+	options HttpProbeOptions
+}
+
+// NewHttpProbe returns a new [HttpProbe].
+func NewHttpProbe(name string, client *http.Client, url string, options HttpProbeOptions) *HttpProbe {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if options.Method == "" {
+		options.Method = http.MethodGet
+	}
+
+	if options.ExpectedStatus == 0 {
+		options.ExpectedStatus = http.StatusOK
+	}
+
+	return &HttpProbe{
+		name:    name,
+		client:  client,
+		url:     url,
+		options: options,
+	}
+}
+
+// Name returns the name of the [HttpProbe].
+func (p *HttpProbe) Name() string {
+	return p.name
+}
+
+// Check returns a successful [healthcheck.CheckerProbeResult] if the remote HTTP dependency responds with the expected status and body.
+func (p *HttpProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	ctx, cancel := withTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, p.options.Method, p.url, nil)
+	if err != nil {
+		return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("http request creation error: %v", err))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("http request error: %v", err))
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != p.options.ExpectedStatus {
+		return newRemoteProbeResult(
+			p.options.RemoteProbeOptions,
+			false,
+			fmt.Sprintf("http response status %d, expected %d", resp.StatusCode, p.options.ExpectedStatus),
+		)
+	}
+
+	if p.options.ExpectedBody != "" {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("http response body read error: %v", err))
+		}
+
+		if !strings.Contains(string(body), p.options.ExpectedBody) {
+			return newRemoteProbeResult(p.options.RemoteProbeOptions, false, "http response body does not contain expected content")
+		}
+	}
+
+	return newRemoteProbeResult(p.options.RemoteProbeOptions, true, fmt.Sprintf("http response status %d", resp.StatusCode))
+}
+
+// GrpcProbeOptions are the options of the [GrpcProbe].
+type GrpcProbeOptions struct {
+	RemoteProbeOptions
+	Service     string
+	DialOptions []grpc.DialOption
+}
+
+// GrpcProbe is a [healthcheck.CheckerProbe] calling a remote gRPC dependency with the gRPC health check protocol.
+type GrpcProbe struct {
+	name    string
+	target  string
+	options GrpcProbeOptions
+}
+
+// NewGrpcProbe returns a new [GrpcProbe].
+func NewGrpcProbe(name string, target string, options GrpcProbeOptions) *GrpcProbe {
+	if len(options.DialOptions) == 0 {
+		options.DialOptions = []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}
+	}
+
+	return &GrpcProbe{
+		name:    name,
+		target:  target,
+		options: options,
+	}
+}
+
+// Name returns the name of the [GrpcProbe].
+func (p *GrpcProbe) Name() string {
+	return p.name
+}
+
+// Check returns a successful [healthcheck.CheckerProbeResult] if the remote gRPC dependency health check responds with SERVING.
+func (p *GrpcProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	ctx, cancel := withTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(p.target, p.options.DialOptions...)
+	if err != nil {
+		return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("grpc connection error: %v", err))
+	}
+
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{
+		Service: p.options.Service,
+	})
+	if err != nil {
+		return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("grpc health check error: %v", err))
+	}
+
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return newRemoteProbeResult(p.options.RemoteProbeOptions, false, fmt.Sprintf("grpc health check status %s", resp.GetStatus()))
+	}
+
+	return newRemoteProbeResult(p.options.RemoteProbeOptions, true, fmt.Sprintf("grpc health check status %s", resp.GetStatus()))
+}
+
+// TcpProbe is a [healthcheck.CheckerProbe] dialing a remote TCP dependency.
+type TcpProbe struct {
+	name    string
+	address string
+	options RemoteProbeOptions
+}
+
+// NewTcpProbe returns a new [TcpProbe].
+func NewTcpProbe(name string, address string, options RemoteProbeOptions) *TcpProbe {
+	return &TcpProbe{
+		name:    name,
+		address: address,
+		options: options,
+	}
+}
+
+// Name returns the name of the [TcpProbe].
+func (p *TcpProbe) Name() string {
+	return p.name
+}
+
+// Check returns a successful [healthcheck.CheckerProbeResult] if the remote TCP dependency can be dialed.
+func (p *TcpProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	ctx, cancel := withTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return newRemoteProbeResult(p.options, false, fmt.Sprintf("tcp dial error: %v", err))
+	}
+
+	//nolint:errcheck
+	conn.Close()
+
+	return newRemoteProbeResult(p.options, true, "tcp dial success")
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// newRemoteProbeResult returns a [healthcheck.CheckerProbeResult]: failures of non critical probes are reported without failing the check.
+func newRemoteProbeResult(options RemoteProbeOptions, success bool, message string) *healthcheck.CheckerProbeResult {
+	if !success && !options.Critical {
+		return healthcheck.NewCheckerProbeResult(true, fmt.Sprintf("non critical failure: %s", message))
+	}
+
+	return healthcheck.NewCheckerProbeResult(success, message)
+}
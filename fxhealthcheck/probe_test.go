@@ -0,0 +1,140 @@
+package fxhealthcheck_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxhealthcheck"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHttpProbe(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+			//nolint:errcheck
+			w.Write([]byte("status: ok"))
+		case "/ko":
+			w.WriteHeader(http.StatusOK)
+			//nolint:errcheck
+			w.Write([]byte("status: ko"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	options := fxhealthcheck.HttpProbeOptions{
+		RemoteProbeOptions: fxhealthcheck.RemoteProbeOptions{
+			Timeout:  time.Second,
+			Critical: true,
+		},
+		ExpectedBody: "ok",
+	}
+
+	probe := fxhealthcheck.NewHttpProbe("http", server.Client(), server.URL+"/ok", options)
+	assert.Equal(t, "http", probe.Name())
+
+	result := probe.Check(context.Background())
+	assert.True(t, result.Success)
+	assert.Equal(t, "http response status 200", result.Message)
+
+	result = fxhealthcheck.NewHttpProbe("http", server.Client(), server.URL+"/ko", options).Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "http response body does not contain expected content", result.Message)
+
+	result = fxhealthcheck.NewHttpProbe("http", server.Client(), server.URL+"/unavailable", options).Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "http response status 503, expected 200", result.Message)
+
+	options.Critical = false
+
+	result = fxhealthcheck.NewHttpProbe("http", nil, server.URL+"/unavailable", options).Check(context.Background())
+	assert.True(t, result.Success)
+	assert.Equal(t, "non critical failure: http response status 503, expected 200", result.Message)
+}
+
+func TestGrpcProbe(t *testing.T) {
+	t.Parallel()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("not-serving", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		//nolint:errcheck
+		server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	options := fxhealthcheck.GrpcProbeOptions{
+		RemoteProbeOptions: fxhealthcheck.RemoteProbeOptions{
+			Timeout:  time.Second,
+			Critical: true,
+		},
+		Service: "serving",
+	}
+
+	probe := fxhealthcheck.NewGrpcProbe("grpc", lis.Addr().String(), options)
+	assert.Equal(t, "grpc", probe.Name())
+
+	result := probe.Check(context.Background())
+	assert.True(t, result.Success)
+	assert.Equal(t, "grpc health check status SERVING", result.Message)
+
+	options.Service = "not-serving"
+
+	result = fxhealthcheck.NewGrpcProbe("grpc", lis.Addr().String(), options).Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Equal(t, "grpc health check status NOT_SERVING", result.Message)
+
+	options.Service = "unknown"
+
+	result = fxhealthcheck.NewGrpcProbe("grpc", lis.Addr().String(), options).Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Message, "grpc health check error")
+}
+
+func TestTcpProbe(t *testing.T) {
+	t.Parallel()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	address := lis.Addr().String()
+
+	options := fxhealthcheck.RemoteProbeOptions{
+		Timeout:  time.Second,
+		Critical: true,
+	}
+
+	probe := fxhealthcheck.NewTcpProbe("tcp", address, options)
+	assert.Equal(t, "tcp", probe.Name())
+
+	result := probe.Check(context.Background())
+	assert.True(t, result.Success)
+	assert.Equal(t, "tcp dial success", result.Message)
+
+	err = lis.Close()
+	assert.NoError(t, err)
+
+	result = probe.Check(context.Background())
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Message, "tcp dial error")
+}
@@ -24,3 +24,16 @@ func AsCheckerProbe(p any, kinds ...healthcheck.ProbeKind) fx.Option {
 		),
 	)
 }
+
+// AsMonitorStatusChangeFunc registers a [healthcheck.MonitorStatusChangeFunc] into Fx, invoked by the monitor on
+// probes status changes.
+func AsMonitorStatusChangeFunc(fn healthcheck.MonitorStatusChangeFunc) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func() healthcheck.MonitorStatusChangeFunc {
+				return fn
+			},
+			fx.ResultTags(`group:"healthcheck-monitor-status-change-funcs"`),
+		),
+	)
+}
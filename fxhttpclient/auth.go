@@ -0,0 +1,205 @@
+package fxhttpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/httpclient/transport"
+)
+
+const (
+	DefaultApiKeyHeader      = "X-Api-Key"
+	DefaultOAuth2ExpiryDelta = 10
+	HMACAlgorithmSHA256      = "sha256"
+	HMACAlgorithmSHA512      = "sha512"
+)
+
+// SecretResolver is the interface for the http clients auth secrets resolvers, resolving at request time the secrets
+// configured by name with secret_name, for example from a secrets manager.
+type SecretResolver interface {
+	Resolve(ctx context.Context, name string) (string, error)
+}
+
+// FetchHMACHash returns the hash function of a HMAC algorithm (sha256 by default).
+func FetchHMACHash(algorithm string) (func() hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "", HMACAlgorithmSHA256:
+		return sha256.New, nil
+	case HMACAlgorithmSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("invalid hmac algorithm %s, must be sha256 or sha512", algorithm)
+	}
+}
+
+// withAuthTransports decorates the provided [http.RoundTripper] with the auth transports enabled in
+// modules.http.client.auth. The OAuth2 tokens are requested with the provided client.
+//
+//nolint:cyclop,funlen
+func withAuthTransports(roundTripper http.RoundTripper, p FxHttpClientTransportParam, tokenClient *http.Client) (http.RoundTripper, error) {
+	// hmac signature, applied last to sign the other auth headers
+	if p.Config.GetBool("modules.http.client.auth.hmac.enabled") {
+		secret, err := authSecret(p, "hmac", "secret")
+		if err != nil {
+			return nil, err
+		}
+
+		hashFunc, err := FetchHMACHash(p.Config.GetString("modules.http.client.auth.hmac.algorithm"))
+		if err != nil {
+			return nil, err
+		}
+
+		components := transport.DefaultHMACComponents
+		if configComponents := p.Config.GetStringSlice("modules.http.client.auth.hmac.components"); len(configComponents) > 0 {
+			components = make([]string, len(configComponents))
+			for i, component := range configComponents {
+				components[i] = strings.ToLower(component)
+			}
+		}
+
+		canonicalizer, err := transport.NewHMACCanonicalizer(components, p.Config.GetStringSlice("modules.http.client.auth.hmac.signed_headers"))
+		if err != nil {
+			return nil, err
+		}
+
+		roundTripper = transport.NewHMACTransportWithConfig(
+			roundTripper,
+			&transport.HMACTransportConfig{
+				KeyID:           p.Config.GetString("modules.http.client.auth.hmac.key_id"),
+				Secret:          secret,
+				Hash:            hashFunc,
+				Canonicalizer:   canonicalizer,
+				SignatureHeader: p.Config.GetString("modules.http.client.auth.hmac.signature_header"),
+				TimestampHeader: p.Config.GetString("modules.http.client.auth.hmac.timestamp_header"),
+				KeyIDHeader:     p.Config.GetString("modules.http.client.auth.hmac.key_id_header"),
+			},
+		)
+
+		p.Logger.Debug().Msg("http client: enabled hmac auth")
+	}
+
+	// oauth2 client credentials
+	if p.Config.GetBool("modules.http.client.auth.oauth2.enabled") {
+		tokenURL := p.Config.GetString("modules.http.client.auth.oauth2.token_url")
+		if tokenURL == "" {
+			return nil, errors.New("missing oauth2 token url")
+		}
+
+		secret, err := authSecret(p, "oauth2", "client_secret")
+		if err != nil {
+			return nil, err
+		}
+
+		authStyle := strings.ToLower(p.Config.GetString("modules.http.client.auth.oauth2.auth_style"))
+		if authStyle != "" && authStyle != transport.OAuth2AuthStyleHeader && authStyle != transport.OAuth2AuthStyleParams {
+			return nil, fmt.Errorf("invalid oauth2 auth style %s, must be header or params", authStyle)
+		}
+
+		params := url.Values{}
+		for name, value := range p.Config.GetStringMapString("modules.http.client.auth.oauth2.params") {
+			params.Set(name, value)
+		}
+
+		expiryDelta := DefaultOAuth2ExpiryDelta
+		if p.Config.IsSet("modules.http.client.auth.oauth2.expiry_delta") {
+			expiryDelta = p.Config.GetInt("modules.http.client.auth.oauth2.expiry_delta")
+		}
+
+		roundTripper = transport.NewOAuth2TransportWithConfig(
+			roundTripper,
+			&transport.OAuth2TransportConfig{
+				TokenURL:     tokenURL,
+				ClientID:     p.Config.GetString("modules.http.client.auth.oauth2.client_id"),
+				ClientSecret: secret,
+				Scopes:       p.Config.GetStringSlice("modules.http.client.auth.oauth2.scopes"),
+				Params:       params,
+				AuthStyle:    authStyle,
+				ExpiryDelta:  time.Duration(expiryDelta) * time.Second,
+				Client:       tokenClient,
+			},
+		)
+
+		p.Logger.Debug().Msg("http client: enabled oauth2 auth")
+	}
+
+	// api key
+	if p.Config.GetBool("modules.http.client.auth.api_key.enabled") {
+		secret, err := authSecret(p, "api_key", "key")
+		if err != nil {
+			return nil, err
+		}
+
+		header := p.Config.GetString("modules.http.client.auth.api_key.header")
+		if header == "" {
+			header = DefaultApiKeyHeader
+		}
+
+		roundTripper = transport.NewBearerTransportWithConfig(
+			roundTripper,
+			&transport.BearerTransportConfig{
+				Secret: secret,
+				Header: header,
+			},
+		)
+
+		p.Logger.Debug().Msg("http client: enabled api key auth")
+	}
+
+	// bearer token
+	if p.Config.GetBool("modules.http.client.auth.bearer.enabled") {
+		secret, err := authSecret(p, "bearer", "token")
+		if err != nil {
+			return nil, err
+		}
+
+		roundTripper = transport.NewBearerTransportWithConfig(
+			roundTripper,
+			&transport.BearerTransportConfig{
+				Secret: secret,
+				Header: transport.HeaderAuthorization,
+				Scheme: transport.DefaultBearerScheme,
+			},
+		)
+
+		p.Logger.Debug().Msg("http client: enabled bearer auth")
+	}
+
+	return roundTripper, nil
+}
+
+// authEnabled returns true if an auth transport is enabled in modules.http.client.auth.
+func authEnabled(cfg *config.Config) bool {
+	for _, auth := range []string{"bearer", "api_key", "oauth2", "hmac"} {
+		if cfg.GetBool(fmt.Sprintf("modules.http.client.auth.%s.enabled", auth)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authSecret returns the [transport.SecretFunc] of an auth transport, resolving its secret_name with the [SecretResolver] if
+// configured, or returning its static secret configured in the provided key otherwise.
+func authSecret(p FxHttpClientTransportParam, auth string, key string) (transport.SecretFunc, error) {
+	name := p.Config.GetString(fmt.Sprintf("modules.http.client.auth.%s.secret_name", auth))
+	if name == "" {
+		return transport.StaticSecret(p.Config.GetString(fmt.Sprintf("modules.http.client.auth.%s.%s", auth, key))), nil
+	}
+
+	if p.SecretResolver == nil {
+		return nil, fmt.Errorf("missing secret resolver for %s auth secret %s", auth, name)
+	}
+
+	return func(ctx context.Context) (string, error) {
+		return p.SecretResolver.Resolve(ctx, name)
+	}, nil
+}
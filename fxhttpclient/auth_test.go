@@ -0,0 +1,187 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testSecretResolver struct {
+	secrets map[string]string
+}
+
+func (r *testSecretResolver) Resolve(_ context.Context, name string) (string, error) {
+	secret, ok := r.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown secret %s", name)
+	}
+
+	return secret, nil
+}
+
+func newTestSecretResolver() fxhttpclient.SecretResolver {
+	return &testSecretResolver{
+		secrets: map[string]string{
+			"partner-api-key": "key",
+		},
+	}
+}
+
+//nolint:maintidx
+func TestModuleWithAuth(t *testing.T) {
+	var tokenRequests atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests.Add(1)
+
+			clientID, clientSecret, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "client", clientID)
+			assert.Equal(t, "secret", clientSecret)
+
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "read write", r.PostForm.Get("scope"))
+			assert.Equal(t, "partner", r.PostForm.Get("audience"))
+
+			_, err := w.Write([]byte(`{"access_token":"oauth2-token","token_type":"Bearer","expires_in":3600}`))
+			assert.NoError(t, err)
+		case "/bearer":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		case "/apikey":
+			assert.Equal(t, "key", r.Header.Get(fxhttpclient.DefaultApiKeyHeader))
+		case "/oauth2":
+			assert.Equal(t, "Bearer oauth2-token", r.Header.Get("Authorization"))
+		case "/hmac":
+			assert.Equal(t, "key-id", r.Header.Get(transport.DefaultHMACKeyIDHeader))
+
+			canonical := fmt.Sprintf("GET\n/hmac\nx-signature-timestamp:%s", r.Header.Get(transport.DefaultHMACTimestampHeader))
+
+			mac := hmac.New(sha512.New, []byte("secret"))
+			mac.Write([]byte(canonical))
+
+			assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), r.Header.Get(transport.DefaultHMACSignatureHeader))
+		}
+	}))
+	defer httpServer.Close()
+
+	t.Setenv("APP_ENV", "auth")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("AUTH_SERVER_URL", httpServer.URL)
+	t.Setenv("AUTH_BEARER_TOKEN", "token")
+	t.Setenv("AUTH_API_KEY_SECRET_NAME", "partner-api-key")
+	t.Setenv("AUTH_HMAC_ALGORITHM", "sha512")
+
+	var pool *fxhttpclient.HttpClientPool
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Provide(newTestSecretResolver),
+		fx.Populate(&pool),
+	).RequireStart().RequireStop()
+
+	for _, name := range []string{"bearer", "apikey", "oauth2", "oauth2", "hmac"} {
+		client, err := pool.Get(name)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/"+name, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// cached oauth2 token
+	assert.Equal(t, int32(1), tokenRequests.Load())
+}
+
+func TestModuleWithInvalidAuthConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		options  []fx.Option
+		expected string
+	}{
+		{
+			name: "missing secret resolver",
+			env: map[string]string{
+				"AUTH_API_KEY_SECRET_NAME": "partner-api-key",
+			},
+			expected: "failed to create http client apikey transport: missing secret resolver for api_key auth secret partner-api-key",
+		},
+		{
+			name: "invalid hmac algorithm",
+			env: map[string]string{
+				"AUTH_HMAC_ALGORITHM": "md5",
+			},
+			options: []fx.Option{
+				fx.Provide(newTestSecretResolver),
+			},
+			expected: "failed to create http client hmac transport: invalid hmac algorithm md5, must be sha256 or sha512",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", "auth")
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("AUTH_SERVER_URL", "https://example.com")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			err := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxmetrics.FxMetricsModule,
+				fxtrace.FxTraceModule,
+				fxhttpclient.FxHttpClientModule,
+				fx.Options(tt.options...),
+				fx.Invoke(func(*fxhttpclient.HttpClientPool) {}),
+			).Err()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestFetchHMACHash(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []string{"", "sha256", "SHA512"} {
+		hashFunc, err := fxhttpclient.FetchHMACHash(algorithm)
+		assert.NoError(t, err)
+		assert.NotNil(t, hashFunc)
+	}
+
+	_, err := fxhttpclient.FetchHMACHash("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package fxhttpclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BaseURLTransport is a [http.RoundTripper] resolving the relative requests URLs against a base URL.
+type BaseURLTransport struct {
+	transport http.RoundTripper
+	baseURL   *url.URL
+}
+
+// NewBaseURLTransport returns a [BaseURLTransport] instance, wrapping the provided [http.RoundTripper].
+func NewBaseURLTransport(base http.RoundTripper, baseURL *url.URL) *BaseURLTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &BaseURLTransport{
+		transport: base,
+		baseURL:   baseURL,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *BaseURLTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// If the request URL has no host (for example /users?page=2), its path is appended to the base URL path, and the
+// request is sent to the base URL scheme and host. Absolute requests URLs are left untouched.
+func (t *BaseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+
+	resolved := *req.URL
+	resolved.Scheme = t.baseURL.Scheme
+	resolved.Host = t.baseURL.Host
+	resolved.User = t.baseURL.User
+	resolved.Path = joinPath(t.baseURL.Path, req.URL.Path)
+	resolved.RawPath = ""
+
+	req.URL = &resolved
+	req.Host = t.baseURL.Host
+
+	return t.transport.RoundTrip(req)
+}
+
+func joinPath(basePath string, path string) string {
+	if path == "" {
+		return basePath
+	}
+
+	return strings.TrimSuffix(basePath, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,52 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseURLTransport(t *testing.T) {
+	t.Parallel()
+
+	var requestURL string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURL = r.URL.String()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	baseURL, err := url.Parse(httpServer.URL + "/api")
+	assert.NoError(t, err)
+
+	transport := fxhttpclient.NewBaseURLTransport(nil, baseURL)
+	assert.Equal(t, http.DefaultTransport, transport.Base())
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"/users?page=2", "/api/users?page=2"},
+		{"users", "/api/users"},
+		{"", "/api"},
+		{httpServer.URL + "/other", "/other"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.target, nil)
+		assert.NoError(t, err)
+
+		resp, err := transport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, tt.expected, requestURL)
+		assert.Equal(t, tt.target, req.URL.String())
+	}
+}
@@ -0,0 +1,337 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testCacheStore struct {
+	mutex  sync.Mutex
+	values map[string][]byte
+}
+
+func (s *testCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+func (s *testCacheStore) Set(_ context.Context, key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = value
+
+	return nil
+}
+
+func (s *testCacheStore) Delete(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.values, key)
+
+	return nil
+}
+
+func TestModuleWithCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   string
+		options []fx.Option
+	}{
+		{
+			name:  "memory store",
+			store: fxhttpclient.CacheStoreMemory,
+		},
+		{
+			name:  "file store",
+			store: fxhttpclient.CacheStoreFile,
+		},
+		{
+			name: "provided store",
+			options: []fx.Option{
+				fx.Provide(
+					fx.Annotate(
+						func() *testCacheStore {
+							return &testCacheStore{values: map[string][]byte{}}
+						},
+						fx.As(new(transport.CacheStore)),
+					),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", "cache")
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("CACHE_STORE", tt.store)
+			t.Setenv("CACHE_PATH", t.TempDir())
+
+			var httpClient *http.Client
+			var logger *log.Logger
+			var logBuffer logtest.TestLogBuffer
+			var metricsRegistry *prometheus.Registry
+
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxmetrics.FxMetricsModule,
+				fxtrace.FxTraceModule,
+				fxhttpclient.FxHttpClientModule,
+				fx.Options(tt.options...),
+				fx.Populate(&httpClient, &logger, &logBuffer, &metricsRegistry),
+			).RequireStart().RequireStop()
+
+			var calls atomic.Int32
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("ETag", `"v1"`)
+
+				if r.URL.Path == "/fresh" {
+					w.Header().Set("Cache-Control", "max-age=60")
+				}
+
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+
+					return
+				}
+
+				_, err := w.Write([]byte("body " + r.URL.Path))
+				assert.NoError(t, err)
+			}))
+			defer httpServer.Close()
+
+			roundTrip := func(path string) *http.Response {
+				t.Helper()
+
+				req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, httpServer.URL+path, nil)
+				assert.NoError(t, err)
+
+				resp, err := httpClient.Do(req)
+				assert.NoError(t, err)
+
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, resp.Body.Close())
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "body "+path, string(body))
+
+				return resp
+			}
+
+			// miss then hit
+			resp := roundTrip("/fresh")
+			assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+
+			resp = roundTrip("/fresh")
+			assert.Equal(t, transport.CacheStatusHit, resp.Header.Get(transport.HeaderCacheStatus))
+			assert.Equal(t, int32(1), calls.Load())
+
+			// miss then revalidation
+			resp = roundTrip("/revalidate")
+			assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+
+			resp = roundTrip("/revalidate")
+			assert.Equal(t, transport.CacheStatusRevalidated, resp.Header.Get(transport.HeaderCacheStatus))
+			assert.Equal(t, int32(3), calls.Load())
+
+			// file store
+			if tt.store == fxhttpclient.CacheStoreFile {
+				files, err := os.ReadDir(os.Getenv("CACHE_PATH"))
+				assert.NoError(t, err)
+				assert.Len(t, files, 2)
+			}
+
+			// logs
+			logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+				"level":   "debug",
+				"method":  "GET",
+				"url":     httpServer.URL + "/fresh",
+				"cache":   "hit",
+				"message": "http client cache",
+			})
+
+			logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+				"level":   "debug",
+				"method":  "GET",
+				"url":     httpServer.URL + "/revalidate",
+				"cache":   "revalidated",
+				"message": "http client cache",
+			})
+
+			// metrics
+			expectedMetric := fmt.Sprintf(
+				`
+					# HELP foo_bar_http_client_requests_total Number of performed HTTP requests
+					# TYPE foo_bar_http_client_requests_total counter
+					foo_bar_http_client_requests_total{cache="hit",host="%s",method="GET",path="/fresh",status="2xx"} 1
+					foo_bar_http_client_requests_total{cache="miss",host="%s",method="GET",path="/fresh",status="2xx"} 1
+					foo_bar_http_client_requests_total{cache="miss",host="%s",method="GET",path="/revalidate",status="2xx"} 1
+					foo_bar_http_client_requests_total{cache="revalidated",host="%s",method="GET",path="/revalidate",status="2xx"} 1
+				`,
+				httpServer.URL,
+				httpServer.URL,
+				httpServer.URL,
+				httpServer.URL,
+			)
+
+			err := testutil.GatherAndCompare(
+				metricsRegistry,
+				strings.NewReader(expectedMetric),
+				"foo_bar_http_client_requests_total",
+			)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestModuleWithInvalidCacheConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    string
+		path     string
+		expected string
+	}{
+		{
+			name:     "invalid store",
+			store:    "invalid",
+			expected: "invalid cache store invalid, must be memory or file",
+		},
+		{
+			name:     "missing file store path",
+			store:    fxhttpclient.CacheStoreFile,
+			expected: "missing cache path for file cache store",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", "cache")
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			t.Setenv("CACHE_STORE", tt.store)
+			t.Setenv("CACHE_PATH", tt.path)
+
+			err := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxmetrics.FxMetricsModule,
+				fxtrace.FxTraceModule,
+				fxhttpclient.FxHttpClientModule,
+				fx.Invoke(func(*http.Client) {}),
+			).Err()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestModuleWithCacheClientsIsolation(t *testing.T) {
+	t.Setenv("APP_ENV", "cacheclients")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	store := &testCacheStore{values: map[string][]byte{}}
+
+	var httpClient *http.Client
+	var pool *fxhttpclient.HttpClientPool
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Supply(fx.Annotate(store, fx.As(new(transport.CacheStore)))),
+		fx.Populate(&httpClient, &pool, &logBuffer),
+	).RequireStart().RequireStop()
+
+	var calls atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		_, err := w.Write([]byte("body " + r.Header.Get("Authorization")))
+		assert.NoError(t, err)
+	}))
+	defer httpServer.Close()
+
+	roundTrip := func(client *http.Client) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		return resp
+	}
+
+	partnerClient, err := pool.Get("partner")
+	assert.NoError(t, err)
+
+	securedClient, err := pool.Get("secured")
+	assert.NoError(t, err)
+
+	// each client caches in its own namespace
+	assert.Equal(t, transport.CacheStatusMiss, roundTrip(httpClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusHit, roundTrip(httpClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusMiss, roundTrip(partnerClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusHit, roundTrip(partnerClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(2), calls.Load())
+
+	assert.Contains(t, store.values, "default:GET "+httpServer.URL)
+	assert.Contains(t, store.values, "partner:GET "+httpServer.URL)
+
+	// authenticated client does not use the cache
+	assert.Empty(t, roundTrip(securedClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Empty(t, roundTrip(securedClient).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Len(t, store.values, 2)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"client":  "secured",
+		"message": "http client: cache disabled, since auth is enabled",
+	})
+}
@@ -0,0 +1,113 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithCircuitBreaker(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RETRY_ENABLED", "true")
+	t.Setenv("CIRCUIT_BREAKER_ENABLED", "true")
+
+	var httpClient *http.Client
+	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &traceExporter, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	var calls atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer httpServer.Close()
+
+	// failures opening the circuit, with the attempts checked by the circuit breaker
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = httpClient.Do(req)
+	assert.ErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// rejected request
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = httpClient.Do(req)
+	assert.ErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+	assert.Contains(t, err.Error(), fmt.Sprintf("circuit breaker is open for host %s", httpServer.URL))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// span events
+	assert.True(t, traceExporter.ContainSpan("HTTP GET"))
+
+	var events []string
+	for _, span := range traceExporter.Spans() {
+		for _, event := range span.Events {
+			events = append(events, event.Name)
+
+			if event.Name == "http client circuit breaker transition" {
+				assert.Contains(t, event.Attributes, attribute.String("http.circuit_breaker.to", "open"))
+			}
+		}
+	}
+
+	assert.Contains(t, events, "http client retry")
+	assert.Contains(t, events, "http client circuit breaker transition")
+	assert.Contains(t, events, "http client circuit breaker rejection")
+
+	// metrics
+	expectedMetric := fmt.Sprintf(
+		`
+			# HELP foo_bar_http_client_circuit_breaker_rejections_total Number of HTTP requests rejected by open circuit breakers
+			# TYPE foo_bar_http_client_circuit_breaker_rejections_total counter
+			foo_bar_http_client_circuit_breaker_rejections_total{host="%s"} 2
+			# HELP foo_bar_http_client_circuit_breaker_state State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)
+			# TYPE foo_bar_http_client_circuit_breaker_state gauge
+			foo_bar_http_client_circuit_breaker_state{host="%s"} 2
+		`,
+		httpServer.URL,
+		httpServer.URL,
+	)
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_circuit_breaker_rejections_total",
+		"foo_bar_http_client_circuit_breaker_state",
+	)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,173 @@
+package fxhttpclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/httpclient"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const (
+	DefaultClientMetricsLabel = "default"
+	ClientMetricsLabel        = "client"
+)
+
+// HttpClientPool holds the named http clients, configured in modules.http.clients.<name>.
+type HttpClientPool struct {
+	clients map[string]*http.Client
+}
+
+// NewHttpClientPool returns a new [HttpClientPool], for the provided named http clients.
+func NewHttpClientPool(clients map[string]*http.Client) *HttpClientPool {
+	pool := &HttpClientPool{
+		clients: make(map[string]*http.Client, len(clients)),
+	}
+
+	for name, client := range clients {
+		pool.clients[strings.ToLower(name)] = client
+	}
+
+	return pool
+}
+
+// Get returns the named http client. The name is lowercased, as the configuration keys.
+func (p *HttpClientPool) Get(name string) (*http.Client, error) {
+	client, ok := p.clients[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("http client %s is not configured", name)
+	}
+
+	return client, nil
+}
+
+// Names returns the sorted names of the http clients.
+func (p *HttpClientPool) Names() []string {
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// FxHttpClientPoolParam allows injection of the required dependencies in [NewFxHttpClientPool].
+type FxHttpClientPoolParam struct {
+	fx.In
+	Factory         httpclient.HttpClientFactory
+	TracerProvider  trace.TracerProvider
+	Config          *config.Config
+	Logger          *log.Logger
+	MetricsRegistry *prometheus.Registry
+	SecretResolver  SecretResolver       `optional:"true"`
+	CacheStore      transport.CacheStore `optional:"true"`
+}
+
+// NewFxHttpClientPool returns a new [HttpClientPool], creating an http client for each modules.http.clients.<name>
+// configuration entry.
+//
+// Each named http client is configured like the default one (with the same keys as modules.http.client), and labels
+// its metrics with its name (the default http client metrics being then labelled with default).
+func NewFxHttpClientPool(p FxHttpClientPoolParam) (*HttpClientPool, error) {
+	clients := map[string]*http.Client{}
+	cacheStatus := collectCacheStatus(p.Config)
+
+	for _, name := range p.Config.SubKeys("modules.http.clients") {
+		if name == DefaultClientMetricsLabel {
+			return nil, fmt.Errorf("http client name %s is reserved", name)
+		}
+
+		clientConfig, err := newClientConfig(p.Config, name)
+		if err != nil {
+			return nil, err
+		}
+
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{ClientMetricsLabel: name}, p.MetricsRegistry)
+
+		roundTripper, err := newHttpClientTransport(
+			FxHttpClientTransportParam{
+				TracerProvider:  p.TracerProvider,
+				Config:          clientConfig,
+				Logger:          p.Logger,
+				MetricsRegistry: p.MetricsRegistry,
+				SecretResolver:  p.SecretResolver,
+				CacheStore:      p.CacheStore,
+			},
+			name,
+			registerer,
+			cacheStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http client %s transport: %w", name, err)
+		}
+
+		client, err := newHttpClient(p.Factory, roundTripper, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http client %s: %w", name, err)
+		}
+
+		clients[name] = client
+	}
+
+	return NewHttpClientPool(clients), nil
+}
+
+// ProvideNamedHttpClient provides the named http client configured in modules.http.clients.<name> to Fx, to be
+// injected with the name:"<name>" tag. The name is lowercased, as the configuration keys.
+func ProvideNamedHttpClient(name string) fx.Option {
+	name = strings.ToLower(name)
+
+	return fx.Provide(
+		fx.Annotate(
+			func(pool *HttpClientPool) (*http.Client, error) {
+				return pool.Get(name)
+			},
+			fx.ResultTags(fmt.Sprintf(`name:"%s"`, name)),
+		),
+	)
+}
+
+// clientMetricsRegisterer returns the [prometheus.Registerer] of the default http client, labelling its metrics if
+// named http clients are configured.
+func clientMetricsRegisterer(cfg *config.Config, registry *prometheus.Registry) prometheus.Registerer {
+	if len(cfg.SubKeys("modules.http.clients")) == 0 {
+		return registry
+	}
+
+	return prometheus.WrapRegistererWith(prometheus.Labels{ClientMetricsLabel: DefaultClientMetricsLabel}, registry)
+}
+
+// collectCacheStatus returns true if the cache of the default or of a named http client is enabled, to label the
+// requests metrics of all the http clients by cache status.
+func collectCacheStatus(cfg *config.Config) bool {
+	if cfg.GetBool("modules.http.client.cache.enabled") {
+		return true
+	}
+
+	for _, name := range cfg.SubKeys("modules.http.clients") {
+		if cfg.GetBool(fmt.Sprintf("modules.http.clients.%s.cache.enabled", name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newClientConfig returns a [config.Config] where modules.http.client is the modules.http.clients.<name> entry.
+func newClientConfig(cfg *config.Config, name string) (*config.Config, error) {
+	clientConfig, err := cfg.Override("modules.http.client", fmt.Sprintf("modules.http.clients.%s", name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration for http client %s: %w", name, err)
+	}
+
+	return clientConfig, nil
+}
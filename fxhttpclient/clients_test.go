@@ -0,0 +1,169 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithNamedClients(t *testing.T) {
+	partnerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(fmt.Sprintf("partner %s?%s", r.URL.Path, r.URL.RawQuery)))
+		assert.NoError(t, err)
+	}))
+	defer partnerServer.Close()
+
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer otherServer.Close()
+
+	t.Setenv("APP_ENV", "clients")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("CLIENTS_PARTNER_BASE_URL", partnerServer.URL+"/api/v1/")
+	t.Setenv("CLIENTS_OTHER_BASE_URL", otherServer.URL)
+
+	type namedClients struct {
+		fx.In
+		Partner *http.Client `name:"partner"`
+		Other   *http.Client `name:"other"`
+	}
+
+	var httpClient *http.Client
+	var pool *fxhttpclient.HttpClientPool
+	var clients namedClients
+	var logger *log.Logger
+	var logBuffer logtest.TestLogBuffer
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.ProvideNamedHttpClient("partner"),
+		fxhttpclient.ProvideNamedHttpClient("Other"),
+		fx.Populate(&httpClient, &pool, &clients, &logger, &logBuffer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	// pool
+	assert.Equal(t, []string{"other", "partner"}, pool.Names())
+
+	partnerClient, err := pool.Get("Partner")
+	assert.NoError(t, err)
+	assert.Same(t, clients.Partner, partnerClient)
+	assert.NotSame(t, httpClient, partnerClient)
+	assert.Equal(t, 5*time.Second, partnerClient.Timeout)
+
+	otherClient, err := pool.Get("other")
+	assert.NoError(t, err)
+	assert.Same(t, clients.Other, otherClient)
+	assert.Equal(t, time.Duration(fxhttpclient.DefaultTimeout)*time.Second, otherClient.Timeout)
+
+	_, err = pool.Get("invalid")
+	assert.Error(t, err)
+	assert.Equal(t, "http client invalid is not configured", err.Error())
+
+	// partner client, with base url
+	req, err := http.NewRequestWithContext(logger.WithContext(context.Background()), http.MethodGet, "/users?page=2", nil)
+	assert.NoError(t, err)
+
+	resp, err := clients.Partner.Do(req)
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, "partner /api/v1/users?page=2", string(body))
+
+	logtest.AssertContainLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"method":  "GET",
+		"url":     partnerServer.URL + "/api/v1/users?page=2",
+		"message": "http client request",
+	})
+
+	// other client, with base url
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+
+	resp, err = clients.Other.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// default client, without base url
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, otherServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err = httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// metrics
+	partnerURL, err := url.Parse(partnerServer.URL)
+	assert.NoError(t, err)
+
+	expectedMetric := fmt.Sprintf(
+		`
+			# HELP foo_bar_http_client_requests_total Number of performed HTTP requests
+			# TYPE foo_bar_http_client_requests_total counter
+			foo_bar_http_client_requests_total{client="default",host="%s",method="GET",path="",status="2xx"} 1
+			foo_bar_http_client_requests_total{client="partner",host="%s",method="GET",path="/api/v1/users?page=2",status="2xx"} 1
+		`,
+		otherServer.URL,
+		partnerURL.Scheme+"://"+partnerURL.Host,
+	)
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithInvalidNamedClientBaseURL(t *testing.T) {
+	t.Setenv("APP_ENV", "clients")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("CLIENTS_PARTNER_BASE_URL", "https://example.com")
+	t.Setenv("CLIENTS_OTHER_BASE_URL", "invalid")
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fxhttpclient.ProvideNamedHttpClient("partner"),
+		fx.Invoke(func(*http.Client) {}),
+		fx.Invoke(fx.Annotate(func(*http.Client) {}, fx.ParamTags(`name:"partner"`))),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create http client other transport: invalid base url invalid")
+}
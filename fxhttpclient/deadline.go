@@ -0,0 +1,53 @@
+package fxhttpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HeaderRequestTimeout is the header used to propagate the remaining request deadline, in milliseconds.
+const HeaderRequestTimeout = "X-Request-Timeout"
+
+// DeadlineTransport is a [http.RoundTripper] propagating the request context deadline to the called services.
+type DeadlineTransport struct {
+	transport http.RoundTripper
+}
+
+// NewDeadlineTransport returns a [DeadlineTransport] instance, wrapping the provided [http.RoundTripper].
+func NewDeadlineTransport(base http.RoundTripper) *DeadlineTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &DeadlineTransport{
+		transport: base,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *DeadlineTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// If the request context has a deadline, the remaining time is sent in the X-Request-Timeout header (in
+// milliseconds), and the request fails without being sent if the deadline is already exceeded.
+func (t *DeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return t.transport.RoundTrip(req)
+	}
+
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return nil, context.DeadlineExceeded
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderRequestTimeout, strconv.FormatInt(remaining, 10))
+
+	return t.transport.RoundTrip(req)
+}
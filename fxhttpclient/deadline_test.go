@@ -0,0 +1,102 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithDeadlinePropagation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpClient *http.Client
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient),
+	).RequireStart().RequireStop()
+
+	var requestTimeout string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimeout = r.Header.Get(fxhttpclient.HeaderRequestTimeout)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	// client timeout deadline
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	remaining, err := strconv.Atoi(requestTimeout)
+	assert.NoError(t, err)
+	assert.Greater(t, remaining, 29000)
+	assert.LessOrEqual(t, remaining, 30000)
+
+	// with deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err = httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	remaining, err = strconv.Atoi(requestTimeout)
+	assert.NoError(t, err)
+	assert.Greater(t, remaining, 4000)
+	assert.LessOrEqual(t, remaining, 5000)
+}
+
+func TestDeadlineTransport(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	transport := fxhttpclient.NewDeadlineTransport(nil)
+	assert.Equal(t, http.DefaultTransport, transport.Base())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, req.Header.Get(fxhttpclient.HeaderRequestTimeout))
+}
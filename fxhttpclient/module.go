@@ -1,7 +1,10 @@
 package fxhttpclient
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +25,19 @@ const (
 	DefaultMaxIdleConnections        = 100
 	DefaultMaxConnectionsPerHost     = 100
 	DefaultMaxIdleConnectionsPerHost = 100
+	DefaultRetryMaxAttempts          = 3
+	DefaultRetryInitialBackoff       = 100
+	DefaultRetryMaxBackoff           = 5000
+	DefaultRetryMultiplier           = 2
+	DefaultRetryJitter               = 0.2
+	DefaultRetryMaxRetryAfter        = 30
+	DefaultRetryMaxBodySize          = 1048576
+	DefaultCircuitBreakerThreshold   = 5
+	DefaultCircuitBreakerOpenTimeout = 30
+	DefaultCacheMaxEntries           = 1000
+	DefaultCacheMaxBodySize          = 1048576
+	CacheStoreMemory                 = "memory"
+	CacheStoreFile                   = "file"
 )
 
 // FxHttpClientModule is the [Fx] http client module.
@@ -36,6 +52,7 @@ var FxHttpClientModule = fx.Module(
 		),
 		httpclient.NewDefaultHttpClientFactory,
 		NewFxHttpClient,
+		NewFxHttpClientPool,
 	),
 )
 
@@ -46,10 +63,27 @@ type FxHttpClientTransportParam struct {
 	Config          *config.Config
 	Logger          *log.Logger
 	MetricsRegistry *prometheus.Registry
+	SecretResolver  SecretResolver       `optional:"true"`
+	CacheStore      transport.CacheStore `optional:"true"`
 }
 
-// NewFxHttpClientTransport returns a new [http.RoundTripper].
-func NewFxHttpClientTransport(p FxHttpClientTransportParam) http.RoundTripper {
+// NewFxHttpClientTransport returns a new [http.RoundTripper], for the default http client.
+func NewFxHttpClientTransport(p FxHttpClientTransportParam) (http.RoundTripper, error) {
+	return newHttpClientTransport(
+		p,
+		DefaultClientMetricsLabel,
+		clientMetricsRegisterer(p.Config, p.MetricsRegistry),
+		collectCacheStatus(p.Config),
+	)
+}
+
+//nolint:cyclop,funlen
+func newHttpClientTransport(
+	p FxHttpClientTransportParam,
+	name string,
+	registerer prometheus.Registerer,
+	cacheStatus bool,
+) (http.RoundTripper, error) {
 	// base round tripper config
 	maxIdleConnections := p.Config.GetInt("modules.http.client.transport.max_idle_connections")
 	if maxIdleConnections == 0 {
@@ -85,10 +119,59 @@ func NewFxHttpClientTransport(p FxHttpClientTransportParam) http.RoundTripper {
 
 	// round tripper
 	var roundTripper http.RoundTripper
-	roundTripper = transport.NewLoggerTransportWithConfig(
-		transport.NewBaseTransportWithConfig(baseTransportConfig),
-		loggerTransportConfig,
+	roundTripper = transport.NewBaseTransportWithConfig(baseTransportConfig)
+
+	// round tripper auth extensions, decorated by the logger to not log the credentials
+	roundTripper, err := withAuthTransports(
+		roundTripper,
+		p,
+		&http.Client{
+			Transport: roundTripper,
+			Timeout:   clientTimeout(p.Config),
+		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTripper = transport.NewLoggerTransportWithConfig(roundTripper, loggerTransportConfig)
+
+	// round tripper circuit breaker extension
+	if p.Config.GetBool("modules.http.client.circuit_breaker.enabled") {
+		roundTripper = transport.NewCircuitBreakerTransportWithConfig(roundTripper, circuitBreakerTransportConfigFromConfig(p, registerer))
+
+		p.Logger.Debug().Msg("http client: enabled circuit breaker")
+	}
+
+	// round tripper retry extension
+	if p.Config.GetBool("modules.http.client.retry.enabled") {
+		roundTripper = transport.NewRetryTransportWithConfig(roundTripper, retryTransportConfigFromConfig(p, registerer))
+
+		p.Logger.Debug().Msg("http client: enabled retry")
+	}
+
+	// round tripper deadline propagation extension
+	if p.Config.GetBool("modules.http.client.deadline.propagate") {
+		roundTripper = NewDeadlineTransport(roundTripper)
+
+		p.Logger.Debug().Msg("http client: enabled deadline propagation")
+	}
+
+	// round tripper cache extension, not shared with authenticated requests
+	if p.Config.GetBool("modules.http.client.cache.enabled") {
+		if authEnabled(p.Config) {
+			p.Logger.Warn().Str("client", name).Msg("http client: cache disabled, since auth is enabled")
+		} else {
+			cacheConfig, err := cacheTransportConfigFromConfig(p, name)
+			if err != nil {
+				return nil, err
+			}
+
+			roundTripper = transport.NewCacheTransportWithConfig(roundTripper, cacheConfig)
+
+			p.Logger.Debug().Msg("http client: enabled cache")
+		}
+	}
 
 	// round tripper tracing extension
 	if p.Config.GetBool("modules.http.client.trace.enabled") {
@@ -115,20 +198,182 @@ func NewFxHttpClientTransport(p FxHttpClientTransportParam) http.RoundTripper {
 		roundTripper = transport.NewMetricsTransportWithConfig(
 			roundTripper,
 			&transport.MetricsTransportConfig{
-				Registry:                  p.MetricsRegistry,
+				Registry:                  registerer,
 				Namespace:                 Sanitize(namespace),
 				Subsystem:                 Sanitize(subsystem),
 				Buckets:                   buckets,
 				NormalizeRequestPath:      p.Config.GetBool("modules.http.client.metrics.normalize.request_path"),
 				NormalizeRequestPathMasks: Flip(p.Config.GetStringMapString("modules.http.client.metrics.normalize.request_path_masks")),
 				NormalizeResponseStatus:   p.Config.GetBool("modules.http.client.metrics.normalize.response_status"),
+				CollectCacheStatus:        cacheStatus,
 			},
 		)
 
 		p.Logger.Debug().Msg("http client: enabled metrics")
 	}
 
-	return roundTripper
+	// round tripper base url extension
+	if baseURLConfig := p.Config.GetString("modules.http.client.base_url"); baseURLConfig != "" {
+		baseURL, err := url.Parse(baseURLConfig)
+		if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+			return nil, fmt.Errorf("invalid base url %s", baseURLConfig)
+		}
+
+		roundTripper = NewBaseURLTransport(roundTripper, baseURL)
+
+		p.Logger.Debug().Msg("http client: enabled base url")
+	}
+
+	return roundTripper, nil
+}
+
+func retryTransportConfigFromConfig(p FxHttpClientTransportParam, registerer prometheus.Registerer) *transport.RetryTransportConfig {
+	maxAttempts := p.Config.GetInt("modules.http.client.retry.max_attempts")
+	if maxAttempts == 0 {
+		maxAttempts = DefaultRetryMaxAttempts
+	}
+
+	methods := transport.DefaultRetryMethods
+	if configMethods := p.Config.GetStringSlice("modules.http.client.retry.methods"); len(configMethods) > 0 {
+		methods = make([]string, len(configMethods))
+		for i, method := range configMethods {
+			methods[i] = strings.ToUpper(method)
+		}
+	}
+
+	statusCodes := p.Config.GetIntSlice("modules.http.client.retry.status_codes")
+	if len(statusCodes) == 0 {
+		statusCodes = transport.DefaultRetryStatusCodes
+	}
+
+	initialBackoff := p.Config.GetInt("modules.http.client.retry.backoff.initial")
+	if initialBackoff == 0 {
+		initialBackoff = DefaultRetryInitialBackoff
+	}
+
+	maxBackoff := p.Config.GetInt("modules.http.client.retry.backoff.max")
+	if maxBackoff == 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	multiplier := p.Config.GetFloat64("modules.http.client.retry.backoff.multiplier")
+	if multiplier == 0 {
+		multiplier = DefaultRetryMultiplier
+	}
+
+	jitter := DefaultRetryJitter
+	if p.Config.IsSet("modules.http.client.retry.backoff.jitter") {
+		jitter = p.Config.GetFloat64("modules.http.client.retry.backoff.jitter")
+	}
+
+	maxRetryAfter := p.Config.GetInt("modules.http.client.retry.retry_after.max")
+	if maxRetryAfter == 0 {
+		maxRetryAfter = DefaultRetryMaxRetryAfter
+	}
+
+	maxBodySize := p.Config.GetInt64("modules.http.client.retry.max_body_size")
+	if maxBodySize == 0 {
+		maxBodySize = DefaultRetryMaxBodySize
+	}
+
+	config := &transport.RetryTransportConfig{
+		MaxAttempts:       maxAttempts,
+		Methods:           methods,
+		StatusCodes:       statusCodes,
+		InitialBackoff:    time.Duration(initialBackoff) * time.Millisecond,
+		MaxBackoff:        time.Duration(maxBackoff) * time.Millisecond,
+		Multiplier:        multiplier,
+		Jitter:            jitter,
+		RespectRetryAfter: !p.Config.GetBool("modules.http.client.retry.retry_after.ignore"),
+		MaxRetryAfter:     time.Duration(maxRetryAfter) * time.Second,
+		MaxBodySize:       maxBodySize,
+	}
+
+	if p.Config.GetBool("modules.http.client.metrics.collect.enabled") {
+		config.Registry = registerer
+		config.Namespace = Sanitize(p.Config.GetString("modules.http.client.metrics.collect.namespace"))
+		config.Subsystem = Sanitize(p.Config.GetString("modules.http.client.metrics.collect.subsystem"))
+	}
+
+	return config
+}
+
+func circuitBreakerTransportConfigFromConfig(p FxHttpClientTransportParam, registerer prometheus.Registerer) *transport.CircuitBreakerTransportConfig {
+	failureThreshold := p.Config.GetInt("modules.http.client.circuit_breaker.failure_threshold")
+	if failureThreshold == 0 {
+		failureThreshold = DefaultCircuitBreakerThreshold
+	}
+
+	openTimeout := p.Config.GetInt("modules.http.client.circuit_breaker.open_timeout")
+	if openTimeout == 0 {
+		openTimeout = DefaultCircuitBreakerOpenTimeout
+	}
+
+	failureStatusCodes := p.Config.GetIntSlice("modules.http.client.circuit_breaker.failure_status_codes")
+	if len(failureStatusCodes) == 0 {
+		failureStatusCodes = transport.DefaultCircuitBreakerFailureStatusCodes
+	}
+
+	config := &transport.CircuitBreakerTransportConfig{
+		FailureThreshold:   failureThreshold,
+		OpenTimeout:        time.Duration(openTimeout) * time.Second,
+		HalfOpenRequests:   p.Config.GetInt("modules.http.client.circuit_breaker.half_open_requests"),
+		FailureStatusCodes: failureStatusCodes,
+	}
+
+	if p.Config.GetBool("modules.http.client.metrics.collect.enabled") {
+		config.Registry = registerer
+		config.Namespace = Sanitize(p.Config.GetString("modules.http.client.metrics.collect.namespace"))
+		config.Subsystem = Sanitize(p.Config.GetString("modules.http.client.metrics.collect.subsystem"))
+	}
+
+	return config
+}
+
+func cacheTransportConfigFromConfig(p FxHttpClientTransportParam, name string) (*transport.CacheTransportConfig, error) {
+	store := p.CacheStore
+	if store == nil {
+		switch storeConfig := strings.ToLower(p.Config.GetString("modules.http.client.cache.store")); storeConfig {
+		case "", CacheStoreMemory:
+			maxEntries := p.Config.GetInt("modules.http.client.cache.max_entries")
+			if maxEntries == 0 {
+				maxEntries = DefaultCacheMaxEntries
+			}
+
+			store = transport.NewMemoryCacheStore(maxEntries)
+		case CacheStoreFile:
+			path := p.Config.GetString("modules.http.client.cache.path")
+			if path == "" {
+				return nil, errors.New("missing cache path for file cache store")
+			}
+
+			fileStore, err := transport.NewFileCacheStore(path)
+			if err != nil {
+				return nil, err
+			}
+
+			store = fileStore
+		default:
+			return nil, fmt.Errorf("invalid cache store %s, must be memory or file", storeConfig)
+		}
+	}
+
+	statusCodes := p.Config.GetIntSlice("modules.http.client.cache.status_codes")
+	if len(statusCodes) == 0 {
+		statusCodes = transport.DefaultCacheStatusCodes
+	}
+
+	maxBodySize := p.Config.GetInt64("modules.http.client.cache.max_body_size")
+	if maxBodySize == 0 {
+		maxBodySize = DefaultCacheMaxBodySize
+	}
+
+	return &transport.CacheTransportConfig{
+		Store:       store,
+		StatusCodes: statusCodes,
+		MaxBodySize: maxBodySize,
+		KeyPrefix:   name,
+	}, nil
 }
 
 // FxHttpClientParam allows injection of the required dependencies in [NewFxHttpClient].
@@ -139,15 +384,23 @@ type FxHttpClientParam struct {
 	Config       *config.Config
 }
 
-// NewFxHttpClient returns a new [http.Client].
+// NewFxHttpClient returns a new [http.Client], for the default http client.
 func NewFxHttpClient(p FxHttpClientParam) (*http.Client, error) {
-	timeout := p.Config.GetInt("modules.http.client.timeout")
+	return newHttpClient(p.Factory, p.RoundTripper, p.Config)
+}
+
+func newHttpClient(factory httpclient.HttpClientFactory, roundTripper http.RoundTripper, cfg *config.Config) (*http.Client, error) {
+	return factory.Create(
+		httpclient.WithTimeout(clientTimeout(cfg)),
+		httpclient.WithTransport(roundTripper),
+	)
+}
+
+func clientTimeout(cfg *config.Config) time.Duration {
+	timeout := cfg.GetInt("modules.http.client.timeout")
 	if timeout == 0 {
 		timeout = DefaultTimeout
 	}
 
-	return p.Factory.Create(
-		httpclient.WithTimeout(time.Duration(timeout)*time.Second),
-		httpclient.WithTransport(p.RoundTripper),
-	)
+	return time.Duration(timeout) * time.Second
 }
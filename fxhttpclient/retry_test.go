@@ -0,0 +1,116 @@
+package fxhttpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxhttpclient"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithRetry(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RETRY_ENABLED", "true")
+
+	var httpClient *http.Client
+	var traceExporter tracetest.TestTraceExporter
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxmetrics.FxMetricsModule,
+		fxtrace.FxTraceModule,
+		fxhttpclient.FxHttpClientModule,
+		fx.Populate(&httpClient, &traceExporter, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	var calls atomic.Int32
+	var bodies []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		bodies = append(bodies, string(body))
+
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer httpServer.Close()
+
+	// retried idempotent request, with body replay
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, httpServer.URL, io.NopCloser(strings.NewReader("data")))
+	assert.NoError(t, err)
+
+	resp, err := httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{"data", "data", "data"}, bodies)
+
+	// not retried request
+	calls.Store(0)
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, httpServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err = httpClient.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// span events
+	span, err := traceExporter.Span("HTTP PUT")
+	assert.NoError(t, err)
+	assert.Len(t, span.Events, 2)
+
+	for i, event := range span.Events {
+		assert.Equal(t, "http client retry", event.Name)
+		assert.Contains(t, event.Attributes, attribute.Int("http.retry.attempt", i+1))
+		assert.Contains(t, event.Attributes, attribute.String("http.retry.reason", "503"))
+	}
+
+	// metrics
+	expectedMetric := fmt.Sprintf(
+		`
+			# HELP foo_bar_http_client_retries_total Number of retried HTTP requests
+			# TYPE foo_bar_http_client_retries_total counter
+			foo_bar_http_client_retries_total{host="%s",method="PUT",reason="503"} 2
+		`,
+		httpServer.URL,
+	)
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_retries_total",
+	)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package fxhttpserver
+
+import (
+	"net/http"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	AuthMethodJwt            = "jwt"
+	AuthMethodApiKey         = "apikey"
+	AuthMethodBasic          = "basic"
+	CtxPrincipalKey          = "fxhttpserver.principal"
+	LogFieldPrincipal        = "principal"
+	LogFieldAuthMethod       = "authMethod"
+	TraceSpanAttributeUserId = "enduser.id"
+	TraceSpanAttributeScope  = "enduser.scope"
+	TraceSpanAttributeRole   = "enduser.role"
+	TraceSpanAttributeMethod = "enduser.auth_method"
+)
+
+// Principal is an authenticated principal.
+type Principal struct {
+	Subject string
+	Method  string
+	Scopes  []string
+	Roles   []string
+	Claims  map[string]any
+}
+
+// HasScope returns true if the principal has the provided scope.
+func (p *Principal) HasScope(scope string) bool {
+	return Contains(p.Scopes, scope)
+}
+
+// HasRole returns true if the principal has the provided role.
+func (p *Principal) HasRole(role string) bool {
+	return Contains(p.Roles, role)
+}
+
+// CtxPrincipal returns the authenticated [Principal] from the context, or nil if not authenticated.
+func CtxPrincipal(c echo.Context) *Principal {
+	if principal, ok := c.Get(CtxPrincipalKey).(*Principal); ok {
+		return principal
+	}
+
+	return nil
+}
+
+// SetPrincipal stores the authenticated [Principal] on the context, adds it to the contextual logger fields
+// and to the current trace span attributes.
+func SetPrincipal(c echo.Context, principal *Principal) {
+	c.Set(CtxPrincipalKey, principal)
+	c.Set(CtxSubjectKey, principal.Subject)
+
+	req := c.Request()
+	ctx := req.Context()
+
+	logger := log.CtxLogger(ctx).ToZerolog().With().
+		Str(LogFieldPrincipal, principal.Subject).
+		Str(LogFieldAuthMethod, principal.Method).
+		Logger()
+
+	c.SetRequest(req.WithContext(logger.WithContext(ctx)))
+
+	if echoLogger, ok := c.Logger().(*httpserver.EchoLogger); ok {
+		c.SetLogger(httpserver.NewEchoLogger(log.FromZerolog(
+			echoLogger.ToZerolog().With().
+				Str(LogFieldPrincipal, principal.Subject).
+				Str(LogFieldAuthMethod, principal.Method).
+				Logger(),
+		)))
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String(TraceSpanAttributeUserId, principal.Subject),
+		attribute.String(TraceSpanAttributeMethod, principal.Method),
+		attribute.StringSlice(TraceSpanAttributeScope, principal.Scopes),
+		attribute.StringSlice(TraceSpanAttributeRole, principal.Roles),
+	)
+}
+
+// unauthorizedResponse logs an authentication failure, and sends a 401 problem details response with a
+// WWW-Authenticate challenge.
+func unauthorizedResponse(c echo.Context, method string, challenge string, detail string, err error) error {
+	log.CtxLogger(c.Request().Context()).
+		Warn().
+		Err(err).
+		Str(LogFieldAuthMethod, method).
+		Msg("authentication failure")
+
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+
+	return problemResponse(c, http.StatusUnauthorized, detail)
+}
@@ -0,0 +1,140 @@
+package fxhttpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const DefaultApiKeyHeader = "X-Api-Key"
+
+// ApiKeyStore is the interface for API keys stores, returning the [Principal] of a key, or nil if unknown.
+type ApiKeyStore interface {
+	Lookup(ctx context.Context, key string) (*Principal, error)
+}
+
+// ApiKey is an API key, with its associated principal subject, scopes and roles.
+type ApiKey struct {
+	Key     string   `mapstructure:"key"`
+	Subject string   `mapstructure:"subject"`
+	Scopes  []string `mapstructure:"scopes"`
+	Roles   []string `mapstructure:"roles"`
+}
+
+// StaticApiKeyStore is an [ApiKeyStore] for a static list of API keys.
+type StaticApiKeyStore struct {
+	keys []ApiKey
+}
+
+// NewStaticApiKeyStore returns a new [StaticApiKeyStore] instance.
+func NewStaticApiKeyStore(keys ...ApiKey) *StaticApiKeyStore {
+	return &StaticApiKeyStore{
+		keys: keys,
+	}
+}
+
+// Lookup returns the [Principal] of a key, or nil if unknown. Keys are compared in constant time.
+func (s *StaticApiKeyStore) Lookup(_ context.Context, key string) (*Principal, error) {
+	var found *ApiKey
+	for i := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(s.keys[i].Key), []byte(key)) == 1 {
+			found = &s.keys[i]
+		}
+	}
+
+	if found == nil {
+		return nil, nil
+	}
+
+	return &Principal{
+		Subject: found.Subject,
+		Method:  AuthMethodApiKey,
+		Scopes:  found.Scopes,
+		Roles:   found.Roles,
+	}, nil
+}
+
+// ApiKeyAuthMiddlewareConfig is the configuration for the [ApiKeyAuthMiddlewareWithConfig].
+type ApiKeyAuthMiddlewareConfig struct {
+	Skipper middleware.Skipper
+	Header  string
+	Store   ApiKeyStore
+}
+
+// ApiKeyAuthMiddlewareWithConfig returns a middleware authenticating requests with an API key header, for a provided
+// [ApiKeyAuthMiddlewareConfig]. The authenticated [Principal] is stored on the context.
+func ApiKeyAuthMiddlewareWithConfig(config ApiKeyAuthMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Header == "" {
+		config.Header = DefaultApiKeyHeader
+	}
+
+	if config.Store == nil {
+		config.Store = NewStaticApiKeyStore()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			key := c.Request().Header.Get(config.Header)
+			if key == "" {
+				return unauthorizedResponse(c, AuthMethodApiKey, config.Header, "missing api key", nil)
+			}
+
+			principal, err := config.Store.Lookup(c.Request().Context(), key)
+			if err != nil || principal == nil {
+				return unauthorizedResponse(c, AuthMethodApiKey, config.Header, "invalid api key", err)
+			}
+
+			if principal.Method == "" {
+				principal.Method = AuthMethodApiKey
+			}
+
+			SetPrincipal(c, principal)
+
+			return next(c)
+		}
+	}
+}
+
+// ApiKeyAuthMiddleware is a [Middleware] authenticating requests with an API key header,
+// configured from modules.http.server.auth.api_key, or using the [ApiKeyStore] provided in Fx if any.
+type ApiKeyAuthMiddleware struct {
+	config ApiKeyAuthMiddlewareConfig
+}
+
+// NewFxApiKeyAuthMiddleware returns a new [ApiKeyAuthMiddleware], to be registered on handlers or groups.
+func NewFxApiKeyAuthMiddleware(p FxAuthMiddlewareParam) (*ApiKeyAuthMiddleware, error) {
+	store := p.ApiKeyStore
+	if store == nil {
+		var keys []ApiKey
+
+		err := p.Config.UnmarshalKey("modules.http.server.auth.api_key.keys", &keys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api keys: %w", err)
+		}
+
+		store = NewStaticApiKeyStore(keys...)
+	}
+
+	return &ApiKeyAuthMiddleware{
+		config: ApiKeyAuthMiddlewareConfig{
+			Header: p.Config.GetString("modules.http.server.auth.api_key.header"),
+			Store:  store,
+		},
+	}, nil
+}
+
+// Handle returns the middleware func.
+func (m *ApiKeyAuthMiddleware) Handle() echo.MiddlewareFunc {
+	return ApiKeyAuthMiddlewareWithConfig(m.config)
+}
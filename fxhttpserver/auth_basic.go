@@ -0,0 +1,131 @@
+package fxhttpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const DefaultBasicAuthRealm = "Restricted"
+
+// BasicAuthValidator validates basic auth credentials, returning the [Principal] of valid ones, or nil.
+type BasicAuthValidator func(ctx context.Context, username string, password string) (*Principal, error)
+
+// BasicAuthUser is a basic auth user. The password can be in plain text, or a bcrypt hash.
+type BasicAuthUser struct {
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	Scopes   []string `mapstructure:"scopes"`
+	Roles    []string `mapstructure:"roles"`
+}
+
+// NewStaticBasicAuthValidator returns a [BasicAuthValidator] for a static list of users.
+func NewStaticBasicAuthValidator(users ...BasicAuthUser) BasicAuthValidator {
+	return func(_ context.Context, username string, password string) (*Principal, error) {
+		for _, user := range users {
+			if subtle.ConstantTimeCompare([]byte(user.Username), []byte(username)) != 1 {
+				continue
+			}
+
+			var valid bool
+			if strings.HasPrefix(user.Password, "$2") {
+				valid = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+			} else {
+				valid = subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) == 1
+			}
+
+			if !valid {
+				return nil, nil
+			}
+
+			return &Principal{
+				Subject: user.Username,
+				Method:  AuthMethodBasic,
+				Scopes:  user.Scopes,
+				Roles:   user.Roles,
+			}, nil
+		}
+
+		return nil, nil
+	}
+}
+
+// BasicAuthMiddlewareConfig is the configuration for the [BasicAuthMiddlewareWithConfig].
+type BasicAuthMiddlewareConfig struct {
+	Skipper   middleware.Skipper
+	Realm     string
+	Validator BasicAuthValidator
+}
+
+// BasicAuthMiddlewareWithConfig returns a middleware authenticating requests with basic auth, for a provided
+// [BasicAuthMiddlewareConfig]. The authenticated [Principal] is stored on the context.
+func BasicAuthMiddlewareWithConfig(config BasicAuthMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Realm == "" {
+		config.Realm = DefaultBasicAuthRealm
+	}
+
+	if config.Validator == nil {
+		config.Validator = NewStaticBasicAuthValidator()
+	}
+
+	challenge := fmt.Sprintf("Basic realm=%q", config.Realm)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			username, password, ok := c.Request().BasicAuth()
+			if !ok {
+				return unauthorizedResponse(c, AuthMethodBasic, challenge, "missing basic auth credentials", nil)
+			}
+
+			principal, err := config.Validator(c.Request().Context(), username, password)
+			if err != nil || principal == nil {
+				return unauthorizedResponse(c, AuthMethodBasic, challenge, "invalid basic auth credentials", err)
+			}
+
+			SetPrincipal(c, principal)
+
+			return next(c)
+		}
+	}
+}
+
+// BasicAuthMiddleware is a [Middleware] authenticating requests with basic auth,
+// configured from modules.http.server.auth.basic.
+type BasicAuthMiddleware struct {
+	config BasicAuthMiddlewareConfig
+}
+
+// NewFxBasicAuthMiddleware returns a new [BasicAuthMiddleware], to be registered on handlers or groups.
+func NewFxBasicAuthMiddleware(p FxAuthMiddlewareParam) (*BasicAuthMiddleware, error) {
+	var users []BasicAuthUser
+
+	err := p.Config.UnmarshalKey("modules.http.server.auth.basic.users", &users)
+	if err != nil {
+		return nil, fmt.Errorf("invalid basic auth users: %w", err)
+	}
+
+	return &BasicAuthMiddleware{
+		config: BasicAuthMiddlewareConfig{
+			Realm:     p.Config.GetString("modules.http.server.auth.basic.realm"),
+			Validator: NewStaticBasicAuthValidator(users...),
+		},
+	}, nil
+}
+
+// Handle returns the middleware func.
+func (m *BasicAuthMiddleware) Handle() echo.MiddlewareFunc {
+	return BasicAuthMiddlewareWithConfig(m.config)
+}
@@ -0,0 +1,440 @@
+package fxhttpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jonboulle/clockwork"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/fx"
+)
+
+const (
+	DefaultJwksCacheTtl       = time.Hour
+	DefaultJwtScopesClaim     = "scope"
+	DefaultJwtRolesClaim      = "roles"
+	jwtScpClaim               = "scp"
+	jwksMinRefreshInterval    = 10 * time.Second
+	oidcDiscoveryPath         = "/.well-known/openid-configuration"
+	bearerAuthScheme          = "Bearer"
+	jwtUnknownKeyErrorMessage = "unknown key id"
+)
+
+var defaultJwtAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "EdDSA"}
+
+// JwksCache fetches and caches the public keys of a JSON Web Key Set.
+// The keys are refreshed after the TTL, or when an unknown key id is requested.
+type JwksCache struct {
+	mutex     sync.Mutex
+	url       string
+	issuer    string
+	client    *http.Client
+	ttl       time.Duration
+	clock     clockwork.Clock
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// NewJwksCache returns a new [JwksCache] for a JWKS url. If the url is empty, it is resolved from the issuer
+// OpenID Connect discovery document.
+func NewJwksCache(url string, issuer string, client *http.Client, ttl time.Duration, clock clockwork.Clock) *JwksCache {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultJwksCacheTtl
+	}
+
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+
+	return &JwksCache{
+		url:    url,
+		issuer: issuer,
+		client: client,
+		ttl:    ttl,
+		clock:  clock,
+		keys:   make(map[string]any),
+	}
+}
+
+// Key returns the public key for a key id.
+func (c *JwksCache) Key(ctx context.Context, kid string) (any, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.clock.Now()
+
+	key, ok := c.keys[kid]
+
+	expired := c.fetchedAt.IsZero() || now.Sub(c.fetchedAt) > c.ttl
+	unknown := !ok && now.Sub(c.fetchedAt) > jwksMinRefreshInterval
+
+	if expired || unknown {
+		err := c.refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok = c.keys[kid]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%s %q", jwtUnknownKeyErrorMessage, kid)
+	}
+
+	return key, nil
+}
+
+func (c *JwksCache) refresh(ctx context.Context) error {
+	if c.url == "" {
+		if c.issuer == "" {
+			return errors.New("missing jwks url or issuer")
+		}
+
+		var discovery struct {
+			JwksUri string `json:"jwks_uri"`
+		}
+
+		err := c.fetch(ctx, strings.TrimSuffix(c.issuer, "/")+oidcDiscoveryPath, &discovery)
+		if err != nil {
+			return fmt.Errorf("failed to fetch openid configuration: %w", err)
+		}
+
+		if discovery.JwksUri == "" {
+			return errors.New("missing jwks_uri in openid configuration")
+		}
+
+		c.url = discovery.JwksUri
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	err := c.fetch(ctx, c.url, &jwks)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return fmt.Errorf("invalid jwk %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = c.clock.Now()
+
+	return nil
+}
+
+func (c *JwksCache) fetch(ctx context.Context, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// JwtAuthMiddlewareConfig is the configuration for the [JwtAuthMiddlewareWithConfig].
+type JwtAuthMiddlewareConfig struct {
+	Skipper     middleware.Skipper
+	Jwks        *JwksCache
+	Secret      string
+	Issuer      string
+	Audience    string
+	Leeway      time.Duration
+	Algorithms  []string
+	ScopesClaim string
+	RolesClaim  string
+	Clock       clockwork.Clock
+}
+
+// JwtAuthMiddlewareWithConfig returns a middleware authenticating requests with a JWT bearer token, for a provided
+// [JwtAuthMiddlewareConfig].
+//
+// The token signature is verified with the JWKS keys (or the HMAC secret), and its expiration, issuer and audience
+// are checked with the configured leeway. The authenticated [Principal] is stored on the context.
+func JwtAuthMiddlewareWithConfig(config JwtAuthMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Clock == nil {
+		config.Clock = clockwork.NewRealClock()
+	}
+
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = defaultJwtAlgorithms
+		if config.Secret != "" {
+			config.Algorithms = []string{"HS256", "HS384", "HS512"}
+		}
+	}
+
+	if config.ScopesClaim == "" {
+		config.ScopesClaim = DefaultJwtScopesClaim
+	}
+
+	if config.RolesClaim == "" {
+		config.RolesClaim = DefaultJwtRolesClaim
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods(config.Algorithms),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithTimeFunc(config.Clock.Now),
+		jwt.WithExpirationRequired(),
+	}
+
+	if config.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(config.Issuer))
+	}
+
+	if config.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(config.Audience))
+	}
+
+	parser := jwt.NewParser(parserOptions...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			scheme, rawToken, found := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if !found || !strings.EqualFold(scheme, bearerAuthScheme) || rawToken == "" {
+				return unauthorizedResponse(c, AuthMethodJwt, bearerAuthScheme, "missing bearer token", nil)
+			}
+
+			claims := jwt.MapClaims{}
+
+			_, err := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+					if config.Secret == "" {
+						return nil, errors.New("missing hmac secret")
+					}
+
+					return []byte(config.Secret), nil
+				}
+
+				if config.Jwks == nil {
+					return nil, errors.New("missing jwks")
+				}
+
+				kid, _ := token.Header["kid"].(string)
+
+				return config.Jwks.Key(c.Request().Context(), kid)
+			})
+			if err != nil {
+				return unauthorizedResponse(
+					c,
+					AuthMethodJwt,
+					fmt.Sprintf(`%s error="invalid_token"`, bearerAuthScheme),
+					"invalid bearer token",
+					err,
+				)
+			}
+
+			subject, _ := claims.GetSubject()
+
+			scopes := claimValues(claims[config.ScopesClaim])
+			if len(scopes) == 0 && config.ScopesClaim == DefaultJwtScopesClaim {
+				scopes = claimValues(claims[jwtScpClaim])
+			}
+
+			SetPrincipal(c, &Principal{
+				Subject: subject,
+				Method:  AuthMethodJwt,
+				Scopes:  scopes,
+				Roles:   claimValues(claims[config.RolesClaim]),
+				Claims:  claims,
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// claimValues returns the values of a claim, either a space separated string or a list of strings.
+func claimValues(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// FxAuthMiddlewareParam allows injection of the required dependencies in the authentication middlewares.
+type FxAuthMiddlewareParam struct {
+	fx.In
+	Config      *config.Config
+	Clock       clockwork.Clock `optional:"true"`
+	ApiKeyStore ApiKeyStore     `optional:"true"`
+}
+
+// JwtAuthMiddleware is a [Middleware] authenticating requests with a JWT bearer token,
+// configured from modules.http.server.auth.jwt.
+type JwtAuthMiddleware struct {
+	config JwtAuthMiddlewareConfig
+}
+
+// NewFxJwtAuthMiddleware returns a new [JwtAuthMiddleware], to be registered on handlers or groups.
+func NewFxJwtAuthMiddleware(p FxAuthMiddlewareParam) *JwtAuthMiddleware {
+	clock := p.Clock
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+
+	issuer := p.Config.GetString("modules.http.server.auth.jwt.issuer")
+
+	var jwks *JwksCache
+	if jwksUrl := p.Config.GetString("modules.http.server.auth.jwt.jwks_url"); jwksUrl != "" || issuer != "" {
+		jwks = NewJwksCache(
+			jwksUrl,
+			issuer,
+			nil,
+			time.Duration(p.Config.GetInt("modules.http.server.auth.jwt.jwks_cache_ttl"))*time.Second,
+			clock,
+		)
+	}
+
+	return &JwtAuthMiddleware{
+		config: JwtAuthMiddlewareConfig{
+			Jwks:        jwks,
+			Secret:      p.Config.GetString("modules.http.server.auth.jwt.secret"),
+			Issuer:      issuer,
+			Audience:    p.Config.GetString("modules.http.server.auth.jwt.audience"),
+			Leeway:      time.Duration(p.Config.GetInt("modules.http.server.auth.jwt.leeway")) * time.Second,
+			Algorithms:  p.Config.GetStringSlice("modules.http.server.auth.jwt.algorithms"),
+			ScopesClaim: p.Config.GetString("modules.http.server.auth.jwt.scopes_claim"),
+			RolesClaim:  p.Config.GetString("modules.http.server.auth.jwt.roles_claim"),
+			Clock:       clock,
+		},
+	}
+}
+
+// Handle returns the middleware func.
+func (m *JwtAuthMiddleware) Handle() echo.MiddlewareFunc {
+	return JwtAuthMiddlewareWithConfig(m.config)
+}
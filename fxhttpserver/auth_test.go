@@ -0,0 +1,475 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jonboulle/clockwork"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+const testJwtKid = "test-kid"
+
+var principalHandler = func(c echo.Context) error {
+	principal := fxhttpserver.CtxPrincipal(c)
+
+	log.CtxLogger(c.Request().Context()).Info().Msg("in principal handler")
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"subject": principal.Subject,
+		"method":  principal.Method,
+		"scopes":  principal.Scopes,
+		"roles":   principal.Roles,
+	})
+}
+
+type testJwksServer struct {
+	*httptest.Server
+	key     *rsa.PrivateKey
+	fetches atomic.Int32
+}
+
+func newTestJwksServer(t *testing.T) *testJwksServer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	server := &testJwksServer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"jwks_uri": server.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		server.fetches.Add(1)
+
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{
+				{
+					"kty": "RSA",
+					"kid": testJwtKid,
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *testJwksServer) token(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(s.key)
+	assert.NoError(t, err)
+
+	return signed
+}
+
+func serveWithAuthorization(httpServer *echo.Echo, path string, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Add("x-request-id", testRequestId)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	return rec
+}
+
+//nolint:maintidx
+func TestModuleWithJwtAuth(t *testing.T) {
+	jwksServer := newTestJwksServer(t)
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("AUTH_JWT_JWKS_URL", jwksServer.URL+"/jwks")
+	t.Setenv("AUTH_JWT_ISSUER", "https://issuer.example.com")
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := clockwork.NewFakeClockAt(now)
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(fx.Annotate(clock, fx.As(new(clockwork.Clock)))),
+		fxhttpserver.AsHandler("GET", "/jwt", principalHandler, fxhttpserver.NewFxJwtAuthMiddleware),
+		fx.Populate(&httpServer, &logBuffer, &traceExporter),
+	).RequireStart().RequireStop()
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":   "john",
+			"iss":   "https://issuer.example.com",
+			"aud":   "test-audience",
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "read write",
+			"roles": []string{"admin"},
+		}
+
+		for k, v := range overrides {
+			c[k] = v
+		}
+
+		return c
+	}
+
+	// missing token
+	rec := serveWithAuthorization(httpServer, "/jwt", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+	assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	// valid token
+	traceExporter.Reset()
+
+	rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+jwksServer.token(t, testJwtKid, claims(nil)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"subject":"john","method":"jwt","scopes":["read","write"],"roles":["admin"]}`, rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "info",
+		"requestID":  testRequestId,
+		"principal":  "john",
+		"authMethod": "jwt",
+		"message":    "in principal handler",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"GET /jwt",
+		attribute.String(fxhttpserver.TraceSpanAttributeUserId, "john"),
+		attribute.String(fxhttpserver.TraceSpanAttributeMethod, "jwt"),
+	)
+
+	// expired token within leeway
+	rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+jwksServer.token(t, testJwtKid, claims(jwt.MapClaims{
+		"exp": now.Add(-10 * time.Second).Unix(),
+	})))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// keys are cached
+	assert.Equal(t, int32(1), jwksServer.fetches.Load())
+
+	// invalid tokens
+	invalidTokens := map[string]string{
+		"invalid":        "invalid",
+		"wrong issuer":   jwksServer.token(t, testJwtKid, claims(jwt.MapClaims{"iss": "https://other.example.com"})),
+		"wrong audience": jwksServer.token(t, testJwtKid, claims(jwt.MapClaims{"aud": "other-audience"})),
+		"unknown kid":    jwksServer.token(t, "unknown-kid", claims(nil)),
+		"expired":        jwksServer.token(t, testJwtKid, claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})),
+	}
+
+	for name, token := range invalidTokens {
+		rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		assert.Equal(t, `Bearer error="invalid_token"`, rec.Header().Get(echo.HeaderWWWAuthenticate), name)
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "warn",
+		"requestID":  testRequestId,
+		"authMethod": "jwt",
+		"message":    "authentication failure",
+	})
+
+	// valid token expiring after the frozen time moves forward
+	validToken := jwksServer.token(t, testJwtKid, claims(nil))
+
+	rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+validToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	clock.Advance(2 * time.Hour)
+
+	rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+validToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// keys are refreshed after the cache ttl
+	assert.Equal(t, int32(2), jwksServer.fetches.Load())
+}
+
+func TestModuleWithJwtAuthOidcDiscovery(t *testing.T) {
+	jwksServer := newTestJwksServer(t)
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("AUTH_JWT_ISSUER", jwksServer.URL)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/jwt", principalHandler, fxhttpserver.NewFxJwtAuthMiddleware),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	token := jwksServer.token(t, testJwtKid, jwt.MapClaims{
+		"sub": "john",
+		"iss": jwksServer.URL,
+		"aud": "test-audience",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	rec := serveWithAuthorization(httpServer, "/jwt", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int32(1), jwksServer.fetches.Load())
+}
+
+func TestModuleWithJwtAuthHmacSecret(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("AUTH_JWT_SECRET", "test-secret")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/jwt", principalHandler, fxhttpserver.NewFxJwtAuthMiddleware),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	sign := func(secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "john",
+			"aud": "test-audience",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"scp": []string{"read"},
+		}).SignedString([]byte(secret))
+		assert.NoError(t, err)
+
+		return token
+	}
+
+	rec := serveWithAuthorization(httpServer, "/jwt", "Bearer "+sign("test-secret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"subject":"john","method":"jwt","scopes":["read"],"roles":null}`, rec.Body.String())
+
+	rec = serveWithAuthorization(httpServer, "/jwt", "Bearer "+sign("other-secret"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+type testApiKeyStore struct{}
+
+func (s *testApiKeyStore) Lookup(_ context.Context, key string) (*fxhttpserver.Principal, error) {
+	if key == "store-api-key" {
+		return &fxhttpserver.Principal{Subject: "store-service"}, nil
+	}
+
+	return nil, nil
+}
+
+func TestModuleWithApiKeyAuth(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	t.Run("with config keys", func(t *testing.T) {
+		var httpServer *echo.Echo
+		var logBuffer logtest.TestLogBuffer
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxtrace.FxTraceModule,
+			fxmetrics.FxMetricsModule,
+			fxgenerate.FxGenerateModule,
+			fxhttpserver.FxHttpServerModule,
+			fxhttpserver.AsHandler("GET", "/apikey", principalHandler, fxhttpserver.NewFxApiKeyAuthMiddleware),
+			fx.Populate(&httpServer, &logBuffer),
+		).RequireStart().RequireStop()
+
+		req := httptest.NewRequest(http.MethodGet, "/apikey", nil)
+		req.Header.Set("x-test-api-key", "test-api-key")
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"subject":"test-service","method":"apikey","scopes":["read"],"roles":null}`, rec.Body.String())
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":      "info",
+			"principal":  "test-service",
+			"authMethod": "apikey",
+			"message":    "in principal handler",
+		})
+
+		req = httptest.NewRequest(http.MethodGet, "/apikey", nil)
+		req.Header.Set("x-test-api-key", "invalid")
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "x-test-api-key", rec.Header().Get(echo.HeaderWWWAuthenticate))
+
+		req = httptest.NewRequest(http.MethodGet, "/apikey", nil)
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("with pluggable store", func(t *testing.T) {
+		var httpServer *echo.Echo
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxtrace.FxTraceModule,
+			fxmetrics.FxMetricsModule,
+			fxgenerate.FxGenerateModule,
+			fxhttpserver.FxHttpServerModule,
+			fx.Supply(fx.Annotate(&testApiKeyStore{}, fx.As(new(fxhttpserver.ApiKeyStore)))),
+			fxhttpserver.AsHandler("GET", "/apikey", principalHandler, fxhttpserver.NewFxApiKeyAuthMiddleware),
+			fx.Populate(&httpServer),
+		).RequireStart().RequireStop()
+
+		req := httptest.NewRequest(http.MethodGet, "/apikey", nil)
+		req.Header.Set("x-test-api-key", "store-api-key")
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"subject":"store-service","method":"apikey","scopes":null,"roles":null}`, rec.Body.String())
+
+		req = httptest.NewRequest(http.MethodGet, "/apikey", nil)
+		req.Header.Set("x-test-api-key", "test-api-key")
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestModuleWithBasicAuth(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/basic", principalHandler, fxhttpserver.NewFxBasicAuthMiddleware),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		expected int
+	}{
+		{"bcrypt password", "admin", "admin-password", http.StatusOK},
+		{"plain password", "user", "user-password", http.StatusOK},
+		{"invalid password", "admin", "invalid", http.StatusUnauthorized},
+		{"unknown user", "unknown", "user-password", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/basic", nil)
+		req.SetBasicAuth(tt.username, tt.password)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expected, rec.Code, tt.name)
+
+		if tt.expected == http.StatusUnauthorized {
+			assert.Equal(t, `Basic realm="test"`, rec.Header().Get(echo.HeaderWWWAuthenticate), tt.name)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/basic", nil)
+	req.SetBasicAuth("admin", "admin-password")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.JSONEq(t, `{"subject":"admin","method":"basic","scopes":null,"roles":["admin"]}`, rec.Body.String())
+}
+
+func TestPrincipal(t *testing.T) {
+	t.Parallel()
+
+	principal := &fxhttpserver.Principal{
+		Subject: "john",
+		Scopes:  []string{"read"},
+		Roles:   []string{"admin"},
+	}
+
+	assert.True(t, principal.HasScope("read"))
+	assert.False(t, principal.HasScope("write"))
+	assert.True(t, principal.HasRole("admin"))
+	assert.False(t, principal.HasRole("user"))
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Nil(t, fxhttpserver.CtxPrincipal(c))
+
+	fxhttpserver.SetPrincipal(c, principal)
+	assert.Equal(t, principal, fxhttpserver.CtxPrincipal(c))
+	assert.Equal(t, "john", c.Get(fxhttpserver.CtxSubjectKey))
+}
@@ -0,0 +1,205 @@
+package fxhttpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceSpanAttributeAuthorizationPolicy   = "authorization.policy"
+	TraceSpanAttributeAuthorizationDecision = "authorization.decision"
+	AuthorizationDecisionAllowed            = "allowed"
+	AuthorizationDecisionDenied             = "denied"
+	LogFieldAuthorizationPolicy             = "policy"
+)
+
+// ErrUnauthenticated is returned by an [Authorizer] when the request is not authenticated.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// AuthorizationPolicy is an authorization policy, that can be declared on handlers and handlers groups registrations.
+// It requires all the Scopes, and any of the Roles.
+type AuthorizationPolicy struct {
+	Name   string
+	Scopes []string
+	Roles  []string
+}
+
+// RequireScopes returns an [AuthorizationPolicy] requiring all the provided scopes.
+func RequireScopes(scopes ...string) AuthorizationPolicy {
+	return AuthorizationPolicy{Scopes: scopes}
+}
+
+// RequireRoles returns an [AuthorizationPolicy] requiring any of the provided roles.
+func RequireRoles(roles ...string) AuthorizationPolicy {
+	return AuthorizationPolicy{Roles: roles}
+}
+
+// String returns the policy name if set, or a description of its requirements.
+func (p AuthorizationPolicy) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	var requirements []string
+
+	if len(p.Scopes) > 0 {
+		requirements = append(requirements, fmt.Sprintf("scopes=%s", strings.Join(p.Scopes, ",")))
+	}
+
+	if len(p.Roles) > 0 {
+		requirements = append(requirements, fmt.Sprintf("roles=%s", strings.Join(p.Roles, "|")))
+	}
+
+	return strings.Join(requirements, ";")
+}
+
+// Authorizer is the interface for authorizers, evaluating an [AuthorizationPolicy] before the handler.
+// It returns nil if the request is allowed, [ErrUnauthenticated] if it's not authenticated, or the denial reason.
+type Authorizer interface {
+	Authorize(c echo.Context, policy AuthorizationPolicy) error
+}
+
+// PrincipalAuthorizer is the default [Authorizer], evaluating the policies against the authenticated [Principal].
+type PrincipalAuthorizer struct{}
+
+// NewPrincipalAuthorizer returns a new [PrincipalAuthorizer] instance.
+func NewPrincipalAuthorizer() *PrincipalAuthorizer {
+	return &PrincipalAuthorizer{}
+}
+
+// Authorize evaluates a policy against the authenticated [Principal] scopes and roles.
+func (a *PrincipalAuthorizer) Authorize(c echo.Context, policy AuthorizationPolicy) error {
+	principal := CtxPrincipal(c)
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+
+	for _, scope := range policy.Scopes {
+		if !principal.HasScope(scope) {
+			return fmt.Errorf("missing scope %s", scope)
+		}
+	}
+
+	if len(policy.Roles) == 0 {
+		return nil
+	}
+
+	for _, role := range policy.Roles {
+		if principal.HasRole(role) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("missing any role of %s", strings.Join(policy.Roles, ", "))
+}
+
+// AuthorizationMiddleware returns a middleware evaluating the provided policies with an [Authorizer].
+//
+// Unauthenticated requests get a 401 problem details response, and denied ones a 403 one. Denials are logged
+// and traced with the policy that failed.
+func AuthorizationMiddleware(authorizer Authorizer, policies ...AuthorizationPolicy) echo.MiddlewareFunc {
+	if authorizer == nil {
+		authorizer = NewPrincipalAuthorizer()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			span := trace.SpanFromContext(c.Request().Context())
+
+			for _, policy := range policies {
+				err := authorizer.Authorize(c, policy)
+				if err == nil {
+					continue
+				}
+
+				span.SetAttributes(
+					attribute.String(TraceSpanAttributeAuthorizationPolicy, policy.String()),
+					attribute.String(TraceSpanAttributeAuthorizationDecision, AuthorizationDecisionDenied),
+				)
+
+				log.CtxLogger(c.Request().Context()).
+					Warn().
+					Err(err).
+					Str(LogFieldAuthorizationPolicy, policy.String()).
+					Msg("authorization failure")
+
+				if errors.Is(err, ErrUnauthenticated) {
+					return problemResponse(c, http.StatusUnauthorized, "authentication required")
+				}
+
+				return problemResponse(c, http.StatusForbidden, fmt.Sprintf("policy %s not satisfied", policy))
+			}
+
+			span.SetAttributes(attribute.String(TraceSpanAttributeAuthorizationDecision, AuthorizationDecisionAllowed))
+
+			return next(c)
+		}
+	}
+}
+
+// RoutePolicies is the authorization policies matrix of a registered route.
+type RoutePolicies struct {
+	Method   string
+	Path     string
+	Policies []AuthorizationPolicy
+}
+
+// applyRegistrationOption adds the policy to the registration policies.
+func (p AuthorizationPolicy) applyRegistrationOption(options *registrationOptions) {
+	options.policies = append(options.policies, p)
+}
+
+// definitionPolicies returns the authorization policies of a definition implementing [AuthorizationPoliciesDefinition].
+func definitionPolicies(definition any) []AuthorizationPolicy {
+	if policiesDefinition, ok := definition.(AuthorizationPoliciesDefinition); ok {
+		return policiesDefinition.Policies()
+	}
+
+	return nil
+}
+
+// HttpServerRoutes exposes the registered routes and their authorization policies, to the core debug routes.
+type HttpServerRoutes struct {
+	registry *HttpServerRegistry
+}
+
+// NewFxHttpServerRoutes returns a new [HttpServerRoutes] instance.
+func NewFxHttpServerRoutes(registry *HttpServerRegistry) *HttpServerRoutes {
+	return &HttpServerRoutes{
+		registry: registry,
+	}
+}
+
+// Name returns the routes provider name.
+func (r *HttpServerRoutes) Name() string {
+	return ModuleName
+}
+
+// Routes returns the registered routes, with their authorization policies.
+func (r *HttpServerRoutes) Routes() []map[string]any {
+	routePolicies := r.registry.ResolveRoutePolicies()
+
+	routes := make([]map[string]any, 0, len(routePolicies))
+	for _, route := range routePolicies {
+		policies := make([]string, 0, len(route.Policies))
+		for _, policy := range route.Policies {
+			policies = append(policies, policy.String())
+		}
+
+		routes = append(routes, map[string]any{
+			"server":   ModuleName,
+			"method":   route.Method,
+			"path":     route.Path,
+			"policies": policies,
+		})
+	}
+
+	return routes
+}
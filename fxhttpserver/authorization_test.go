@@ -0,0 +1,212 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testAuthorizer struct{}
+
+func (a *testAuthorizer) Authorize(c echo.Context, policy fxhttpserver.AuthorizationPolicy) error {
+	if c.Request().Header.Get("x-allow") == policy.Name {
+		return nil
+	}
+
+	return errors.New("not allowed")
+}
+
+//nolint:maintidx
+func TestModuleWithAuthorizationPolicies(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var registry *fxhttpserver.HttpServerRegistry
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler(
+			"GET",
+			"/admin",
+			principalHandler,
+			fxhttpserver.NewFxBasicAuthMiddleware,
+			fxhttpserver.RequireRoles("admin"),
+		),
+		fxhttpserver.AsHandler("GET", "/unauthenticated", principalHandler, fxhttpserver.RequireScopes("read")),
+		fxhttpserver.AsHandlersGroup(
+			"/group",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration("GET", "/read", principalHandler),
+				fxhttpserver.NewHandlerRegistration("GET,POST", "/write", principalHandler, fxhttpserver.RequireScopes("write")),
+			},
+			fxhttpserver.NewFxApiKeyAuthMiddleware,
+			fxhttpserver.RequireScopes("read"),
+		),
+		fx.Populate(&httpServer, &registry, &logBuffer, &traceExporter),
+	).RequireStart().RequireStop()
+
+	// allowed role
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.SetBasicAuth("admin", "admin-password")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// denied role
+	traceExporter.Reset()
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	req.SetBasicAuth("user", "user-password")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var problem map[string]any
+	err := json.Unmarshal(rec.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Forbidden", problem["title"])
+	assert.Equal(t, "policy roles=admin not satisfied", problem["detail"])
+	assert.Equal(t, testRequestId, problem["requestId"])
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "warn",
+		"requestID": testRequestId,
+		"principal": "user",
+		"policy":    "roles=admin",
+		"error":     "missing any role of admin",
+		"message":   "authorization failure",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"GET /admin",
+		attribute.String(fxhttpserver.TraceSpanAttributeAuthorizationPolicy, "roles=admin"),
+		attribute.String(fxhttpserver.TraceSpanAttributeAuthorizationDecision, fxhttpserver.AuthorizationDecisionDenied),
+	)
+
+	// unauthenticated
+	req = httptest.NewRequest(http.MethodGet, "/unauthenticated", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "authentication required")
+
+	// group policy
+	req = httptest.NewRequest(http.MethodGet, "/group/read", nil)
+	req.Header.Set("x-test-api-key", "test-api-key")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// group and handler policies
+	req = httptest.NewRequest(http.MethodPost, "/group/write", nil)
+	req.Header.Set("x-test-api-key", "test-api-key")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "policy scopes=write not satisfied")
+
+	// routes policies matrix
+	routes := fxhttpserver.NewFxHttpServerRoutes(registry)
+	assert.Equal(t, "httpserver", routes.Name())
+	assert.ElementsMatch(
+		t,
+		[]map[string]any{
+			{"server": "httpserver", "method": "GET", "path": "/admin", "policies": []string{"roles=admin"}},
+			{"server": "httpserver", "method": "GET", "path": "/unauthenticated", "policies": []string{"scopes=read"}},
+			{"server": "httpserver", "method": "GET", "path": "/group/read", "policies": []string{"scopes=read"}},
+			{"server": "httpserver", "method": "GET", "path": "/group/write", "policies": []string{"scopes=read", "scopes=write"}},
+			{"server": "httpserver", "method": "POST", "path": "/group/write", "policies": []string{"scopes=read", "scopes=write"}},
+		},
+		routes.Routes(),
+	)
+}
+
+func TestModuleWithCustomAuthorizer(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsAuthorizer(func() *testAuthorizer {
+			return &testAuthorizer{}
+		}),
+		fxhttpserver.AsHandler(
+			"GET",
+			"/custom",
+			rateLimitHandler,
+			fxhttpserver.AuthorizationPolicy{Name: "custom-policy"},
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/custom", nil)
+	req.Header.Set("x-allow", "custom-policy")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/custom", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "policy custom-policy not satisfied")
+}
+
+func TestAuthorizationPolicyString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "named", fxhttpserver.AuthorizationPolicy{Name: "named", Scopes: []string{"read"}}.String())
+	assert.Equal(t, "scopes=read,write", fxhttpserver.RequireScopes("read", "write").String())
+	assert.Equal(t, "roles=admin|editor", fxhttpserver.RequireRoles("admin", "editor").String())
+	assert.Equal(
+		t,
+		"scopes=read;roles=admin",
+		fxhttpserver.AuthorizationPolicy{Scopes: []string{"read"}, Roles: []string{"admin"}}.String(),
+	)
+}
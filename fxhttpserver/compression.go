@@ -0,0 +1,368 @@
+package fxhttpserver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ankorstore/yokai/httpserver"
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	CompressionEncodingGzip   = "gzip"
+	CompressionEncodingZstd   = "zstd"
+	CompressionEncodingBrotli = "br"
+	DefaultCompressionMinSize = 1024
+)
+
+// DefaultCompressionEncodings are the default compression encodings, by server preference order.
+var DefaultCompressionEncodings = []string{
+	CompressionEncodingZstd,
+	CompressionEncodingBrotli,
+	CompressionEncodingGzip,
+}
+
+// DefaultCompressionContentTypes are the default compressible content types prefixes.
+var DefaultCompressionContentTypes = []string{
+	"text/",
+	echo.MIMEApplicationJSON,
+	httpserver.MIMEApplicationProblemJSON,
+	echo.MIMEApplicationJavaScript,
+	echo.MIMEApplicationXML,
+	"image/svg+xml",
+}
+
+// CompressionMiddlewareConfig is the configuration for the [CompressionMiddlewareWithConfig].
+type CompressionMiddlewareConfig struct {
+	Skipper      middleware.Skipper
+	Encodings    []string
+	MinSize      int
+	ContentTypes []string
+}
+
+// CompressionMiddlewareWithConfig returns a middleware compressing the responses, for a provided [CompressionMiddlewareConfig].
+//
+// The encoding is negotiated from the request Accept-Encoding header, by the configured encodings preference order.
+// Responses smaller than the min size, or with a content type not matching the configured prefixes, are not compressed.
+// The compression ratio (compressed size / original size) is reported to the [httpservermiddleware.RequestMetricsMiddleware].
+// Unsupported encodings are ignored, see [ValidateCompressionEncodings].
+func CompressionMiddlewareWithConfig(config CompressionMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if len(config.Encodings) == 0 {
+		config.Encodings = DefaultCompressionEncodings
+	}
+
+	if config.MinSize < 0 {
+		config.MinSize = 0
+	}
+
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = DefaultCompressionContentTypes
+	}
+
+	var encodings []string
+	for _, encoding := range config.Encodings {
+		if _, ok := compressionEncoders[encoding]; ok {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if config.Skipper(c) || req.Method == http.MethodHead || c.IsWebSocket() {
+				return next(c)
+			}
+
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+			encoding := NegotiateCompressionEncoding(req.Header.Get(echo.HeaderAcceptEncoding), encodings)
+			if encoding == "" {
+				return next(c)
+			}
+
+			resp := c.Response()
+			writer := resp.Writer
+
+			compressionWriter := &compressionResponseWriter{
+				ResponseWriter: writer,
+				encoding:       encoding,
+				minSize:        config.MinSize,
+				contentTypes:   config.ContentTypes,
+				status:         http.StatusOK,
+			}
+			resp.Writer = compressionWriter
+
+			err := next(c)
+
+			// the error handler will write the error response without compression
+			if err != nil && !resp.Committed {
+				resp.Writer = writer
+
+				return err
+			}
+
+			closeErr := compressionWriter.close()
+
+			if compressionWriter.compressed && compressionWriter.originalSize > 0 {
+				httpservermiddleware.ObserveResponseCompression(
+					c,
+					encoding,
+					float64(compressionWriter.compressedSize)/float64(compressionWriter.originalSize),
+				)
+			}
+
+			return errors.Join(err, closeErr)
+		}
+	}
+}
+
+// ValidateCompressionEncodings returns an error if one of the provided encodings is not supported.
+func ValidateCompressionEncodings(encodings []string) error {
+	for _, encoding := range encodings {
+		if _, ok := compressionEncoders[encoding]; !ok {
+			return fmt.Errorf("invalid compression encoding %q", encoding)
+		}
+	}
+
+	return nil
+}
+
+// NegotiateCompressionEncoding returns the first of the provided encodings accepted by an Accept-Encoding header
+// value, or an empty string if none is accepted.
+func NegotiateCompressionEncoding(acceptEncoding string, encodings []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	wildcard := false
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if name == "*" {
+			wildcard = quality > 0
+
+			continue
+		}
+
+		accepted[name] = quality > 0
+	}
+
+	for _, encoding := range encodings {
+		if acceptedEncoding, ok := accepted[encoding]; ok {
+			if acceptedEncoding {
+				return encoding
+			}
+
+			continue
+		}
+
+		if wildcard {
+			return encoding
+		}
+	}
+
+	return ""
+}
+
+type compressionEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressionEncoders = map[string]*sync.Pool{
+	CompressionEncodingGzip: {
+		New: func() any {
+			return gzip.NewWriter(io.Discard)
+		},
+	},
+	CompressionEncodingZstd: {
+		New: func() any {
+			//nolint:errcheck
+			encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+
+			return encoder
+		},
+	},
+	CompressionEncodingBrotli: {
+		New: func() any {
+			return brotli.NewWriter(io.Discard)
+		},
+	},
+}
+
+type compressionCountingWriter struct {
+	writer io.Writer
+	count  int
+}
+
+func (w *compressionCountingWriter) Write(b []byte) (int, error) {
+	n, err := w.writer.Write(b)
+	w.count += n
+
+	return n, err
+}
+
+type compressionResponseWriter struct {
+	http.ResponseWriter
+	encoding       string
+	minSize        int
+	contentTypes   []string
+	status         int
+	buffer         bytes.Buffer
+	decided        bool
+	compressed     bool
+	encoder        compressionEncoder
+	counter        *compressionCountingWriter
+	originalSize   int
+	compressedSize int
+}
+
+func (w *compressionResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *compressionResponseWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buffer.Write(b)
+
+		if w.buffer.Len() < w.minSize {
+			return len(b), nil
+		}
+
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+
+		return len(b), nil
+	}
+
+	if w.compressed {
+		w.originalSize += len(b)
+
+		return w.encoder.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressionResponseWriter) Flush() {
+	if !w.decided {
+		//nolint:errcheck
+		w.decide(true)
+	}
+
+	if w.compressed {
+		//nolint:errcheck
+		w.encoder.Flush()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressionResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *compressionResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressionResponseWriter) decide(sizeReached bool) error {
+	w.decided = true
+
+	header := w.Header()
+
+	w.compressed = sizeReached &&
+		w.buffer.Len() > 0 &&
+		w.status != http.StatusNoContent &&
+		w.status != http.StatusNotModified &&
+		w.status != http.StatusPartialContent &&
+		header.Get(echo.HeaderContentEncoding) == "" &&
+		httpserver.MatchPrefix(w.contentTypes, header.Get(echo.HeaderContentType))
+
+	if !w.compressed {
+		w.ResponseWriter.WriteHeader(w.status)
+
+		if w.buffer.Len() > 0 {
+			_, err := w.ResponseWriter.Write(w.buffer.Bytes())
+
+			return err
+		}
+
+		return nil
+	}
+
+	header.Del(echo.HeaderContentLength)
+	header.Set(echo.HeaderContentEncoding, w.encoding)
+
+	if etag := header.Get(HeaderETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+		// the compressed representation differs from the strong validated one
+		header.Set(HeaderETag, "W/"+etag)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.counter = &compressionCountingWriter{writer: w.ResponseWriter}
+
+	//nolint:forcetypeassert
+	w.encoder = compressionEncoders[w.encoding].Get().(compressionEncoder)
+	w.encoder.Reset(w.counter)
+
+	w.originalSize = w.buffer.Len()
+
+	_, err := w.encoder.Write(w.buffer.Bytes())
+
+	return err
+}
+
+func (w *compressionResponseWriter) close() error {
+	if !w.decided {
+		if err := w.decide(w.buffer.Len() >= w.minSize); err != nil {
+			return err
+		}
+	}
+
+	if !w.compressed {
+		return nil
+	}
+
+	err := w.encoder.Close()
+
+	w.compressedSize = w.counter.count
+
+	w.encoder.Reset(io.Discard)
+	compressionEncoders[w.encoding].Put(w.encoder)
+
+	return err
+}
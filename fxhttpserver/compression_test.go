@@ -0,0 +1,195 @@
+package fxhttpserver_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var compressionBody = strings.Repeat("compressible content ", 100)
+
+var compressionHandler = func(c echo.Context) error {
+	switch c.QueryParam("kind") {
+	case "small":
+		return c.String(http.StatusOK, "small")
+	case "binary":
+		return c.Blob(http.StatusOK, "image/png", []byte(compressionBody))
+	case "error":
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request")
+	default:
+		return c.String(http.StatusOK, compressionBody)
+	}
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var reader io.Reader
+	var err error
+
+	switch encoding {
+	case fxhttpserver.CompressionEncodingGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case fxhttpserver.CompressionEncodingZstd:
+		reader, err = zstd.NewReader(bytes.NewReader(body))
+	case fxhttpserver.CompressionEncodingBrotli:
+		reader = brotli.NewReader(bytes.NewReader(body))
+	}
+
+	assert.NoError(t, err)
+
+	decompressed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	return string(decompressed)
+}
+
+//nolint:maintidx
+func TestModuleWithCompression(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("COMPRESSION_ENABLED", "true")
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/compression", compressionHandler),
+		fxhttpserver.AsHandler("GET", "/compression/excluded", compressionHandler),
+		fx.Populate(&httpServer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	// negotiated encodings
+	for acceptEncoding, expectedEncoding := range map[string]string{
+		"gzip":                  fxhttpserver.CompressionEncodingGzip,
+		"gzip, zstd":            fxhttpserver.CompressionEncodingZstd,
+		"gzip, br;q=0.5":        fxhttpserver.CompressionEncodingBrotli,
+		"zstd;q=0, br;q=0, *":   fxhttpserver.CompressionEncodingGzip,
+		"deflate, identity;q=1": "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/compression", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, expectedEncoding, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, echo.HeaderAcceptEncoding, rec.Header().Get(echo.HeaderVary))
+
+		if expectedEncoding == "" {
+			assert.Equal(t, compressionBody, rec.Body.String())
+		} else {
+			assert.Less(t, rec.Body.Len(), len(compressionBody))
+			assert.Equal(t, compressionBody, decompress(t, expectedEncoding, rec.Body.Bytes()))
+		}
+	}
+
+	// not compressed responses
+	for _, target := range []string{
+		"/compression?kind=small",
+		"/compression?kind=binary",
+		"/compression/excluded",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	}
+
+	// error response
+	req := httptest.NewRequest(http.MethodGet, "/compression?kind=error", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Contains(t, rec.Body.String(), "Bad Request")
+
+	// metrics
+	assert.Equal(t, 3, testutil.CollectAndCount(metricsRegistry, "http_server_response_compression_ratio"))
+
+	expectedMetric := `
+		# HELP http_server_requests_total Number of processed HTTP requests
+		# TYPE http_server_requests_total counter
+		http_server_requests_total{method="GET",path="/compression",status="2xx"} 7
+		http_server_requests_total{method="GET",path="/compression",status="4xx"} 1
+		http_server_requests_total{method="GET",path="/compression/excluded",status="2xx"} 1
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithInvalidCompressionEncoding(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("COMPRESSION_ENABLED", "true")
+	t.Setenv("MODULES_HTTP_SERVER_COMPRESSION_ENCODINGS", "gzip deflate")
+
+	var httpServer *echo.Echo
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid compression encoding "deflate"`)
+}
+
+func TestNegotiateCompressionEncoding(t *testing.T) {
+	t.Parallel()
+
+	encodings := []string{
+		fxhttpserver.CompressionEncodingZstd,
+		fxhttpserver.CompressionEncodingBrotli,
+		fxhttpserver.CompressionEncodingGzip,
+	}
+
+	assert.Equal(t, "", fxhttpserver.NegotiateCompressionEncoding("", encodings))
+	assert.Equal(t, "gzip", fxhttpserver.NegotiateCompressionEncoding("GZIP", encodings))
+	assert.Equal(t, "br", fxhttpserver.NegotiateCompressionEncoding("gzip, br", encodings))
+	assert.Equal(t, "gzip", fxhttpserver.NegotiateCompressionEncoding("gzip, br;q=0", encodings))
+	assert.Equal(t, "zstd", fxhttpserver.NegotiateCompressionEncoding("*", encodings))
+	assert.Equal(t, "", fxhttpserver.NegotiateCompressionEncoding("*;q=0", encodings))
+	assert.Equal(t, "", fxhttpserver.NegotiateCompressionEncoding("deflate", encodings))
+}
@@ -44,24 +44,32 @@ type HandlerDefinition interface {
 	Middlewares() []MiddlewareDefinition
 }
 
+// AuthorizationPoliciesDefinition is the optional interface of the handlers and handlers groups definitions
+// declaring authorization policies.
+type AuthorizationPoliciesDefinition interface {
+	Policies() []AuthorizationPolicy
+}
+
 type handlerDefinition struct {
 	method      string
 	path        string
 	handler     any
 	middlewares []MiddlewareDefinition
+	policies    []AuthorizationPolicy
 }
 
 // NewHandlerDefinition returns a new [HandlerDefinition].
-func NewHandlerDefinition(method string, path string, handler any, middlewares []MiddlewareDefinition) HandlerDefinition {
+func NewHandlerDefinition(method string, path string, handler any, middlewares []MiddlewareDefinition, policies ...AuthorizationPolicy) HandlerDefinition {
 	return &handlerDefinition{
 		method:      method,
 		path:        path,
 		handler:     handler,
 		middlewares: middlewares,
+		policies:    policies,
 	}
 }
 
-// Concrete returns true if the handler is a [echo.HandlerFunc] concrete implementation.
+// Concrete returns true if the handler is a [echo.HandlerFunc] concrete implementation, or a [Handler] instance.
 func (d *handlerDefinition) Concrete() bool {
 	return IsConcreteHandler(d.handler)
 }
@@ -86,6 +94,11 @@ func (d *handlerDefinition) Middlewares() []MiddlewareDefinition {
 	return d.middlewares
 }
 
+// Policies returns the handler associated authorization policies.
+func (d *handlerDefinition) Policies() []AuthorizationPolicy {
+	return d.policies
+}
+
 // HandlersGroupDefinition is the interface for handlers groups definitions.
 type HandlersGroupDefinition interface {
 	Prefix() string
@@ -97,14 +110,16 @@ type handlersGroupDefinition struct {
 	prefix      string
 	handlers    []HandlerDefinition
 	middlewares []MiddlewareDefinition
+	policies    []AuthorizationPolicy
 }
 
 // NewHandlersGroupDefinition returns a new [HandlersGroupDefinition].
-func NewHandlersGroupDefinition(prefix string, handlers []HandlerDefinition, middlewares []MiddlewareDefinition) HandlersGroupDefinition {
+func NewHandlersGroupDefinition(prefix string, handlers []HandlerDefinition, middlewares []MiddlewareDefinition, policies ...AuthorizationPolicy) HandlersGroupDefinition {
 	return &handlersGroupDefinition{
 		prefix:      prefix,
 		handlers:    handlers,
 		middlewares: middlewares,
+		policies:    policies,
 	}
 }
 
@@ -122,3 +137,8 @@ func (h *handlersGroupDefinition) Handlers() []HandlerDefinition {
 func (h *handlersGroupDefinition) Middlewares() []MiddlewareDefinition {
 	return h.middlewares
 }
+
+// Policies returns the handlers group associated authorization policies.
+func (h *handlersGroupDefinition) Policies() []AuthorizationPolicy {
+	return h.policies
+}
@@ -57,3 +57,21 @@ func TestHandlersGroupDefinition(t *testing.T) {
 	assert.Equal(t, handlers, hgd.Handlers())
 	assert.Equal(t, middlewares, hgd.Middlewares())
 }
+
+func TestDefinitionsPolicies(t *testing.T) {
+	t.Parallel()
+
+	policy := fxhttpserver.RequireScopes("read")
+
+	hd := fxhttpserver.NewHandlerDefinition(http.MethodGet, "/test", handler.NewTestBarHandler, nil, policy)
+
+	hdPolicies, ok := hd.(fxhttpserver.AuthorizationPoliciesDefinition)
+	assert.True(t, ok)
+	assert.Equal(t, []fxhttpserver.AuthorizationPolicy{policy}, hdPolicies.Policies())
+
+	hgd := fxhttpserver.NewHandlersGroupDefinition("/group", []fxhttpserver.HandlerDefinition{hd}, nil, policy)
+
+	hgdPolicies, ok := hgd.(fxhttpserver.AuthorizationPoliciesDefinition)
+	assert.True(t, ok)
+	assert.Equal(t, []fxhttpserver.AuthorizationPolicy{policy}, hgdPolicies.Policies())
+}
@@ -0,0 +1,212 @@
+package fxhttpserver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	HeaderETag                    = "ETag"
+	HeaderIfNoneMatch             = "If-None-Match"
+	HeaderIfModifiedSince         = "If-Modified-Since"
+	etagWeakPrefix                = "W/"
+	etagHashLength                = 16
+	etagConditionalRequestAnyETag = "*"
+)
+
+// ETagMiddlewareConfig is the configuration for the [ETagMiddlewareWithConfig].
+type ETagMiddlewareConfig struct {
+	Skipper middleware.Skipper
+	Weak    bool
+}
+
+// ETagMiddlewareWithConfig returns a middleware handling the GET requests ETags, for a provided [ETagMiddlewareConfig].
+//
+// The 200 responses get a strong (or weak) ETag computed from their body, unless already provided by the handler.
+// Conditional requests matching the If-None-Match header (or the If-Modified-Since header, against the response
+// Last-Modified header) get a 304 response, and their result (hit or miss) is reported to the
+// [httpservermiddleware.RequestMetricsMiddleware].
+func ETagMiddlewareWithConfig(config ETagMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if config.Skipper(c) || req.Method != http.MethodGet || c.IsWebSocket() {
+				return next(c)
+			}
+
+			resp := c.Response()
+			writer := resp.Writer
+
+			etagWriter := &etagResponseWriter{
+				ResponseWriter: writer,
+				status:         http.StatusOK,
+			}
+			resp.Writer = etagWriter
+
+			err := next(c)
+
+			// the error handler will write the error response
+			if err != nil && !resp.Committed {
+				resp.Writer = writer
+
+				return err
+			}
+
+			if err != nil || etagWriter.streamed || etagWriter.status != http.StatusOK {
+				return etagWriter.writeBuffered()
+			}
+
+			header := resp.Header()
+
+			etag := header.Get(HeaderETag)
+			if etag == "" {
+				etag = ComputeETag(etagWriter.buffer.Bytes(), config.Weak)
+				header.Set(HeaderETag, etag)
+			}
+
+			conditional, matched := evaluateConditionalRequest(req, header, etag)
+			if !conditional {
+				return etagWriter.writeBuffered()
+			}
+
+			if !matched {
+				httpservermiddleware.ObserveConditionalRequest(c, false)
+
+				return etagWriter.writeBuffered()
+			}
+
+			httpservermiddleware.ObserveConditionalRequest(c, true)
+
+			header.Del(echo.HeaderContentType)
+			header.Del(echo.HeaderContentLength)
+
+			resp.Status = http.StatusNotModified
+			writer.WriteHeader(http.StatusNotModified)
+
+			return nil
+		}
+	}
+}
+
+// ComputeETag returns the strong (or weak) ETag of a response body.
+func ComputeETag(body []byte, weak bool) string {
+	hash := sha256.Sum256(body)
+
+	etag := `"` + hex.EncodeToString(hash[:etagHashLength]) + `"`
+	if weak {
+		return etagWeakPrefix + etag
+	}
+
+	return etag
+}
+
+// MatchETag returns true if an If-None-Match header value matches an ETag, with the weak comparison.
+func MatchETag(ifNoneMatch string, etag string) bool {
+	etag = strings.TrimPrefix(etag, etagWeakPrefix)
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == etagConditionalRequestAnyETag || strings.TrimPrefix(candidate, etagWeakPrefix) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func evaluateConditionalRequest(req *http.Request, header http.Header, etag string) (bool, bool) {
+	if ifNoneMatch := req.Header.Get(HeaderIfNoneMatch); ifNoneMatch != "" {
+		return true, MatchETag(ifNoneMatch, etag)
+	}
+
+	ifModifiedSince := req.Header.Get(HeaderIfModifiedSince)
+	lastModified := header.Get(echo.HeaderLastModified)
+
+	if ifModifiedSince == "" || lastModified == "" {
+		return false, false
+	}
+
+	ifModifiedSinceTime, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false, false
+	}
+
+	lastModifiedTime, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false, false
+	}
+
+	return true, !lastModifiedTime.After(ifModifiedSinceTime)
+}
+
+type etagResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	buffer   bytes.Buffer
+	streamed bool
+}
+
+func (w *etagResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *etagResponseWriter) Write(b []byte) (int, error) {
+	if w.streamed {
+		return w.ResponseWriter.Write(b)
+	}
+
+	return w.buffer.Write(b)
+}
+
+// Flush switches to streaming, since the ETag of a streamed response cannot be computed.
+func (w *etagResponseWriter) Flush() {
+	if !w.streamed {
+		//nolint:errcheck
+		w.writeBuffered()
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *etagResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *etagResponseWriter) writeBuffered() error {
+	if w.streamed {
+		return nil
+	}
+
+	w.streamed = true
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+
+	_, err := w.ResponseWriter.Write(w.buffer.Bytes())
+
+	return err
+}
@@ -0,0 +1,183 @@
+package fxhttpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var etagLastModified = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var etagHandler = func(c echo.Context) error {
+	if c.QueryParam("custom") == "true" {
+		c.Response().Header().Set(fxhttpserver.HeaderETag, `"custom"`)
+	}
+
+	c.Response().Header().Set(echo.HeaderLastModified, etagLastModified.Format(http.TimeFormat))
+
+	return c.String(http.StatusOK, compressionBody)
+}
+
+func sendETagRequest(httpServer *echo.Echo, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	return rec
+}
+
+//nolint:maintidx
+func TestModuleWithETag(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("ETAG_ENABLED", "true")
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/etag", etagHandler),
+		fxhttpserver.AsHandler("GET", "/etag/excluded", etagHandler),
+		fx.Populate(&httpServer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	expectedETag := fxhttpserver.ComputeETag([]byte(compressionBody), false)
+
+	// computed etag
+	rec := sendETagRequest(httpServer, "/etag", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, expectedETag, rec.Header().Get(fxhttpserver.HeaderETag))
+	assert.Equal(t, compressionBody, rec.Body.String())
+
+	// if-none-match hit
+	rec = sendETagRequest(httpServer, "/etag", map[string]string{
+		fxhttpserver.HeaderIfNoneMatch: `"other", ` + expectedETag,
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, expectedETag, rec.Header().Get(fxhttpserver.HeaderETag))
+	assert.Empty(t, rec.Body.String())
+
+	// if-none-match miss
+	rec = sendETagRequest(httpServer, "/etag", map[string]string{
+		fxhttpserver.HeaderIfNoneMatch: `"other"`,
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, compressionBody, rec.Body.String())
+
+	// if-modified-since hit and miss
+	rec = sendETagRequest(httpServer, "/etag", map[string]string{
+		fxhttpserver.HeaderIfModifiedSince: etagLastModified.Add(time.Hour).Format(http.TimeFormat),
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = sendETagRequest(httpServer, "/etag", map[string]string{
+		fxhttpserver.HeaderIfModifiedSince: etagLastModified.Add(-time.Hour).Format(http.TimeFormat),
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// handler provided etag
+	rec = sendETagRequest(httpServer, "/etag?custom=true", map[string]string{
+		fxhttpserver.HeaderIfNoneMatch: `W/"custom"`,
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"custom"`, rec.Header().Get(fxhttpserver.HeaderETag))
+
+	// excluded
+	rec = sendETagRequest(httpServer, "/etag/excluded", map[string]string{
+		fxhttpserver.HeaderIfNoneMatch: expectedETag,
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(fxhttpserver.HeaderETag))
+
+	// metrics
+	expectedMetric := `
+		# HELP http_server_conditional_requests_total Number of HTTP conditional requests, by result (hit with 304 response, or miss)
+		# TYPE http_server_conditional_requests_total counter
+		http_server_conditional_requests_total{method="GET",path="/etag",result="hit"} 3
+		http_server_conditional_requests_total{method="GET",path="/etag",result="miss"} 2
+	`
+
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_conditional_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithWeakETagAndCompression(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("ETAG_ENABLED", "true")
+	t.Setenv("ETAG_WEAK", "true")
+	t.Setenv("COMPRESSION_ENABLED", "true")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/etag", etagHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	expectedETag := fxhttpserver.ComputeETag([]byte(compressionBody), true)
+
+	rec := sendETagRequest(httpServer, "/etag", map[string]string{
+		echo.HeaderAcceptEncoding: "gzip",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, fxhttpserver.CompressionEncodingGzip, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, expectedETag, rec.Header().Get(fxhttpserver.HeaderETag))
+	assert.Equal(t, compressionBody, decompress(t, fxhttpserver.CompressionEncodingGzip, rec.Body.Bytes()))
+
+	rec = sendETagRequest(httpServer, "/etag", map[string]string{
+		echo.HeaderAcceptEncoding:      "gzip",
+		fxhttpserver.HeaderIfNoneMatch: expectedETag,
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestMatchETag(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, fxhttpserver.MatchETag(`"foo"`, `"foo"`))
+	assert.True(t, fxhttpserver.MatchETag(`W/"foo"`, `"foo"`))
+	assert.True(t, fxhttpserver.MatchETag(`"foo"`, `W/"foo"`))
+	assert.True(t, fxhttpserver.MatchETag(`"bar", "foo"`, `"foo"`))
+	assert.True(t, fxhttpserver.MatchETag(`*`, `"foo"`))
+	assert.False(t, fxhttpserver.MatchETag(`"bar"`, `"foo"`))
+}
@@ -0,0 +1,68 @@
+package fxhttpservertest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/labstack/echo/v4"
+)
+
+// HttpServerTestServer is a test server, serving a provided [echo.Echo] instance on a real listener, to drive
+// streaming (Server-Sent Events and WebSocket) endpoints.
+type HttpServerTestServer struct {
+	testServer *httptest.Server
+}
+
+// NewHttpServerTestServer returns a new started [HttpServerTestServer], for a provided [echo.Echo] instance.
+func NewHttpServerTestServer(httpServer *echo.Echo) *HttpServerTestServer {
+	return &HttpServerTestServer{
+		testServer: httptest.NewServer(httpServer),
+	}
+}
+
+// URL returns the test server base URL.
+func (s *HttpServerTestServer) URL() string {
+	return s.testServer.URL
+}
+
+// Close closes the test server, and its open client connections.
+func (s *HttpServerTestServer) Close() {
+	s.testServer.CloseClientConnections()
+	s.testServer.Close()
+}
+
+// ConnectSSE connects to a Server-Sent Events endpoint, on a provided path, with optional request headers.
+func (s *HttpServerTestServer) ConnectSSE(ctx context.Context, path string, headers http.Header) (*SSETestClient, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.testServer.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	req.Header.Set(echo.HeaderAccept, fxhttpserver.MIMETextEventStream)
+
+	//nolint:bodyclose
+	resp, err := s.testServer.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSSETestClient(resp), nil
+}
+
+// ConnectWebSocket connects to a WebSocket endpoint, on a provided path, with optional request headers.
+//
+// The handshake response is returned, also on failure, to allow assertions on rejected upgrades.
+func (s *HttpServerTestServer) ConnectWebSocket(ctx context.Context, path string, headers http.Header) (*WebSocketTestClient, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(s.testServer.URL, "http") + path
+
+	return newWebSocketTestClient(ctx, url, headers)
+}
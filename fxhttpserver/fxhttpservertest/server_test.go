@@ -0,0 +1,110 @@
+package fxhttpservertest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/fxhttpservertest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSSEHandler struct{}
+
+func (h *testSSEHandler) Stream(c echo.Context, stream *fxhttpserver.SSEStream) error {
+	err := stream.Send(fxhttpserver.SSEEvent{
+		Id:    "1",
+		Event: "greeting",
+		Data:  "hello\nworld",
+		Retry: time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.SendJSON("json", map[string]string{"name": c.QueryParam("name")})
+}
+
+type testWebSocketHandler struct{}
+
+func (h *testWebSocketHandler) Serve(c echo.Context, conn *fxhttpserver.WebSocketConn) error {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		err = conn.WriteMessage(messageType, data)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestHttpServerTestServer(t *testing.T) {
+	t.Parallel()
+
+	manager := fxhttpserver.NewStreamManager(fxhttpserver.StreamManagerConfig{
+		Heartbeat: 100 * time.Millisecond,
+		Registry:  prometheus.NewRegistry(),
+	})
+
+	httpServer := echo.New()
+	httpServer.GET("/sse", manager.SSEHandlerFunc(&testSSEHandler{}))
+	httpServer.GET("/ws", manager.WebSocketHandlerFunc(&testWebSocketHandler{}))
+
+	srv := fxhttpservertest.NewHttpServerTestServer(httpServer)
+	defer srv.Close()
+
+	assert.NotEmpty(t, srv.URL())
+
+	t.Run("sse", func(t *testing.T) {
+		cli, err := srv.ConnectSSE(context.Background(), "/sse?name=test", nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, cli.Response().StatusCode)
+		assert.Equal(t, fxhttpserver.MIMETextEventStream, cli.Response().Header.Get(echo.HeaderContentType))
+
+		event, err := cli.Next(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, fxhttpserver.SSEEvent{Id: "1", Event: "greeting", Data: "hello\nworld", Retry: time.Second}, event)
+
+		event, err = cli.Next(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "json", event.Event)
+		assert.JSONEq(t, `{"name":"test"}`, event.Data)
+
+		// the stream is closed once the handler returned
+		_, err = cli.Next(time.Second)
+		assert.ErrorIs(t, err, io.EOF)
+
+		assert.NoError(t, cli.Close())
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		cli, resp, err := srv.ConnectWebSocket(context.Background(), "/ws", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+		assert.NoError(t, cli.Send("hello"))
+
+		message, err := cli.Receive(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", message)
+
+		assert.NoError(t, cli.SendJSON(map[string]string{"name": "test"}))
+
+		var target map[string]string
+		assert.NoError(t, cli.ReceiveJSON(&target, time.Second))
+		assert.Equal(t, map[string]string{"name": "test"}, target)
+
+		assert.NoError(t, cli.Close())
+	})
+
+	assert.NoError(t, manager.Shutdown(context.Background()))
+}
@@ -0,0 +1,125 @@
+package fxhttpservertest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+)
+
+// ErrTimeout is returned when no message was received before the timeout.
+var ErrTimeout = errors.New("timeout")
+
+// SSETestClient is a Server-Sent Events test client.
+type SSETestClient struct {
+	response   *http.Response
+	events     chan fxhttpserver.SSEEvent
+	done       chan struct{}
+	err        error
+	heartbeats atomic.Int64
+}
+
+func newSSETestClient(resp *http.Response) *SSETestClient {
+	client := &SSETestClient{
+		response: resp,
+		events:   make(chan fxhttpserver.SSEEvent),
+		done:     make(chan struct{}),
+	}
+
+	go client.read()
+
+	return client
+}
+
+// Response returns the stream [http.Response].
+func (c *SSETestClient) Response() *http.Response {
+	return c.response
+}
+
+// Heartbeats returns the number of heartbeats received.
+func (c *SSETestClient) Heartbeats() int {
+	return int(c.heartbeats.Load())
+}
+
+// Next returns the next received event, or [ErrTimeout] if none was received before the timeout, or [io.EOF] if the
+// stream was closed by the server.
+func (c *SSETestClient) Next(timeout time.Duration) (fxhttpserver.SSEEvent, error) {
+	select {
+	case event, ok := <-c.events:
+		if !ok {
+			return fxhttpserver.SSEEvent{}, c.err
+		}
+
+		return event, nil
+	case <-time.After(timeout):
+		return fxhttpserver.SSEEvent{}, ErrTimeout
+	}
+}
+
+// Close closes the stream.
+func (c *SSETestClient) Close() error {
+	close(c.done)
+
+	return c.response.Body.Close()
+}
+
+func (c *SSETestClient) read() {
+	defer close(c.events)
+
+	scanner := bufio.NewScanner(c.response.Body)
+
+	var event fxhttpserver.SSEEvent
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+
+				select {
+				case c.events <- event:
+				case <-c.done:
+					return
+				}
+			}
+
+			event = fxhttpserver.SSEEvent{}
+			data = nil
+		case strings.HasPrefix(line, ":"):
+			if strings.TrimSpace(line[1:]) == "heartbeat" {
+				c.heartbeats.Add(1)
+			}
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+
+			switch field {
+			case "id":
+				event.Id = value
+			case "event":
+				event.Event = value
+			case "data":
+				data = append(data, value)
+			case "retry":
+				if retry, err := strconv.Atoi(value); err == nil {
+					event.Retry = time.Duration(retry) * time.Millisecond
+				}
+			}
+		}
+	}
+
+	c.err = io.EOF
+	if err := scanner.Err(); err != nil {
+		c.err = fmt.Errorf("cannot read stream: %w", err)
+	}
+}
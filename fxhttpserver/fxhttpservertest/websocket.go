@@ -0,0 +1,137 @@
+package fxhttpservertest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketTestClient is a WebSocket test client.
+//
+// Messages are read in background, to answer the server heartbeats while waiting.
+type WebSocketTestClient struct {
+	conn     *websocket.Conn
+	mutex    sync.Mutex
+	messages chan string
+	done     chan struct{}
+	err      error
+	pings    atomic.Int64
+}
+
+func newWebSocketTestClient(ctx context.Context, url string, headers http.Header) (*WebSocketTestClient, *http.Response, error) {
+	//nolint:bodyclose
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, url, headers)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	client := &WebSocketTestClient{
+		conn:     conn,
+		messages: make(chan string),
+		done:     make(chan struct{}),
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		client.pings.Add(1)
+
+		return client.writeControl(websocket.PongMessage, []byte(data))
+	})
+
+	go client.read()
+
+	return client, resp, nil
+}
+
+// Conn returns the underlying [websocket.Conn].
+func (c *WebSocketTestClient) Conn() *websocket.Conn {
+	return c.conn
+}
+
+// Pings returns the number of heartbeat pings received.
+func (c *WebSocketTestClient) Pings() int {
+	return int(c.pings.Load())
+}
+
+// Send sends a text message.
+func (c *WebSocketTestClient) Send(message string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+// SendJSON sends a text message, with the JSON encoded data.
+func (c *WebSocketTestClient) SendJSON(data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(string(encoded))
+}
+
+// Receive returns the next received message, or [ErrTimeout] if none was received before the timeout, or a
+// [websocket.CloseError] if the connection was closed by the server.
+func (c *WebSocketTestClient) Receive(timeout time.Duration) (string, error) {
+	select {
+	case message, ok := <-c.messages:
+		if !ok {
+			return "", c.err
+		}
+
+		return message, nil
+	case <-time.After(timeout):
+		return "", ErrTimeout
+	}
+}
+
+// ReceiveJSON receives the next message, and decodes its JSON data into the provided target.
+func (c *WebSocketTestClient) ReceiveJSON(target any, timeout time.Duration) error {
+	message, err := c.Receive(timeout)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(message), target)
+}
+
+// Close closes gracefully the connection.
+func (c *WebSocketTestClient) Close() error {
+	close(c.done)
+
+	//nolint:errcheck
+	c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	return c.conn.Close()
+}
+
+func (c *WebSocketTestClient) writeControl(messageType int, data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.conn.WriteControl(messageType, data, time.Now().Add(time.Second))
+}
+
+func (c *WebSocketTestClient) read() {
+	defer close(c.messages)
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.err = err
+
+			return
+		}
+
+		select {
+		case c.messages <- string(data):
+		case <-c.done:
+			return
+		}
+	}
+}
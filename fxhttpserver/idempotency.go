@@ -0,0 +1,92 @@
+package fxhttpserver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver"
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	IdempotencyStoreMemory = "memory"
+	IdempotencyStoreSql    = "sql"
+)
+
+// withIdempotencyMiddleware registers the idempotency middleware from the modules.http.server.idempotency configuration.
+//
+// The keys scoped by subject need the authenticated principal, set by the routes authentication middlewares: the
+// middleware is then returned instead, to attach on the routes after their own middlewares.
+func withIdempotencyMiddleware(httpServer *echo.Echo, p FxHttpServerParam) (*echo.Echo, echo.MiddlewareFunc, error) {
+	store, err := newIdempotencyStore(p)
+	if err != nil {
+		return httpServer, nil, err
+	}
+
+	scope := p.Config.GetString("modules.http.server.idempotency.scope")
+
+	// same scopes as the rate limiting keys
+	keyScope, err := ResolveRateLimitKeyExtractor(scope)
+	if err != nil {
+		return httpServer, nil, fmt.Errorf("invalid idempotency scope %q", scope)
+	}
+
+	excludes := p.Config.GetStringSlice("modules.http.server.idempotency.exclude")
+
+	idempotencyMiddleware := httpservermiddleware.IdempotencyMiddlewareWithConfig(httpservermiddleware.IdempotencyMiddlewareConfig{
+		Skipper: func(c echo.Context) bool {
+			return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+		},
+		Store:          store,
+		Methods:        p.Config.GetStringSlice("modules.http.server.idempotency.methods"),
+		KeyHeader:      p.Config.GetString("modules.http.server.idempotency.header"),
+		KeyScope:       httpservermiddleware.IdempotencyKeyScope(keyScope),
+		Required:       p.Config.GetBool("modules.http.server.idempotency.required"),
+		Ttl:            time.Duration(p.Config.GetInt("modules.http.server.idempotency.ttl")) * time.Second,
+		ReservationTtl: time.Duration(p.Config.GetInt("modules.http.server.idempotency.reservation_ttl")) * time.Second,
+		LockTimeout:    time.Duration(p.Config.GetInt("modules.http.server.idempotency.lock_timeout")) * time.Second,
+	})
+
+	if scope == RateLimitKeySubject {
+		return httpServer, idempotencyMiddleware, nil
+	}
+
+	httpServer.Use(idempotencyMiddleware)
+
+	return httpServer, nil, nil
+}
+
+// newIdempotencyStore returns the idempotency store selected by modules.http.server.idempotency.store: an application
+// provided [httpservermiddleware.IdempotencyStore] takes precedence, then memory (default) or sql, on the fxsql database.
+func newIdempotencyStore(p FxHttpServerParam) (httpservermiddleware.IdempotencyStore, error) {
+	if p.IdempotencyStore != nil {
+		return p.IdempotencyStore, nil
+	}
+
+	switch storeType := p.Config.GetString("modules.http.server.idempotency.store"); storeType {
+	case "", IdempotencyStoreMemory:
+		return httpservermiddleware.NewMemoryIdempotencyStore(), nil
+	case IdempotencyStoreSql:
+		if p.Database == nil {
+			return nil, errors.New("idempotency sql store requires a database, provided by the fxsql module")
+		}
+
+		dialect := p.Config.GetString("modules.http.server.idempotency.sql.dialect")
+		if dialect == "" {
+			dialect = p.Config.GetString("modules.sql.driver")
+		}
+
+		switch dialect {
+		case httpservermiddleware.IdempotencySqlDialectSqlite,
+			httpservermiddleware.IdempotencySqlDialectMysql,
+			httpservermiddleware.IdempotencySqlDialectPostgres:
+			return httpservermiddleware.NewSqlIdempotencyStore(p.Database, dialect), nil
+		default:
+			return nil, fmt.Errorf("invalid idempotency sql dialect %q", dialect)
+		}
+	default:
+		return nil, fmt.Errorf("invalid idempotency store %q", storeType)
+	}
+}
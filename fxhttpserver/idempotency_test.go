@@ -0,0 +1,336 @@
+package fxhttpserver_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func newIdempotencyDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	migration, err := httpservermiddleware.IdempotencyMigrations.ReadFile(
+		"migrations/sqlite/20241019000000_create_idempotency_keys_table.sql",
+	)
+	assert.NoError(t, err)
+
+	up, _, _ := strings.Cut(strings.TrimPrefix(string(migration), "-- +goose Up"), "-- +goose Down")
+
+	_, err = db.Exec(up)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func TestModuleWithIdempotency(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("IDEMPOTENCY_ENABLED", "true")
+	t.Setenv("IDEMPOTENCY_SQL_DIALECT", "sqlite")
+
+	for _, store := range []string{"memory", "sql"} {
+		t.Run(store, func(t *testing.T) {
+			t.Setenv("IDEMPOTENCY_STORE", store)
+
+			var calls atomic.Int32
+
+			handler := func(c echo.Context) error {
+				calls.Add(1)
+
+				return c.String(http.StatusCreated, "created")
+			}
+
+			var httpServer *echo.Echo
+
+			app := fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Provide(func() *sql.DB {
+					return newIdempotencyDatabase(t)
+				}),
+				fx.Options(
+					fxhttpserver.AsHandler("POST", "/idempotency", handler),
+					fxhttpserver.AsHandler("POST", "/idempotency/excluded", handler),
+				),
+				fx.Populate(&httpServer),
+			)
+
+			app.RequireStart()
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/idempotency", strings.NewReader(`{"amount":10}`))
+				req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, "key")
+				rec := httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusCreated, rec.Code)
+				assert.Equal(t, "created", rec.Body.String())
+			}
+
+			assert.Equal(t, int32(1), calls.Load())
+
+			// key reuse with a different payload
+			req := httptest.NewRequest(http.MethodPost, "/idempotency", strings.NewReader(`{"amount":20}`))
+			req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, "key")
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Equal(t, int32(1), calls.Load())
+
+			// excluded
+			for i := 0; i < 2; i++ {
+				req = httptest.NewRequest(http.MethodPost, "/idempotency/excluded", strings.NewReader(`{"amount":10}`))
+				req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, "key")
+				rec = httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusCreated, rec.Code)
+			}
+
+			assert.Equal(t, int32(3), calls.Load())
+
+			app.RequireStop()
+		})
+	}
+}
+
+func TestModuleWithIdempotencyAndCompression(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("IDEMPOTENCY_ENABLED", "true")
+	t.Setenv("COMPRESSION_ENABLED", "true")
+
+	var calls atomic.Int32
+
+	handler := func(c echo.Context) error {
+		calls.Add(1)
+
+		return c.String(http.StatusCreated, compressionBody)
+	}
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("POST", "/idempotency", handler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	send := func(key string, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/idempotency", strings.NewReader(`{"amount":10}`))
+		req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, key)
+		if acceptEncoding != "" {
+			req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		}
+
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// the stored responses are uncompressed, and compressed again on replay depending on the replay request
+	for _, key := range []string{"compressed", "uncompressed"} {
+		encodings := []string{"gzip", ""}
+		if key == "uncompressed" {
+			encodings = []string{"", "gzip"}
+		}
+
+		for i, encoding := range encodings {
+			rec := send(key, encoding)
+
+			assert.Equal(t, http.StatusCreated, rec.Code, key)
+
+			if i > 0 {
+				assert.Equal(t, "true", rec.Header().Get(httpservermiddleware.HeaderIdempotentReplayed), key)
+			}
+
+			if encoding == "" {
+				assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding), key)
+				assert.Equal(t, compressionBody, rec.Body.String(), key)
+			} else {
+				assert.Equal(t, encoding, rec.Header().Get(echo.HeaderContentEncoding), key)
+				assert.Equal(t, echo.HeaderAcceptEncoding, rec.Header().Get(echo.HeaderVary), key)
+				assert.Equal(t, compressionBody, decompress(t, encoding, rec.Body.Bytes()), key)
+			}
+		}
+	}
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestModuleWithIdempotencyScope(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("IDEMPOTENCY_ENABLED", "true")
+
+	t.Run("ip", func(t *testing.T) {
+		var calls atomic.Int32
+
+		handler := func(c echo.Context) error {
+			calls.Add(1)
+
+			return c.String(http.StatusCreated, "created")
+		}
+
+		var httpServer *echo.Echo
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxtrace.FxTraceModule,
+			fxmetrics.FxMetricsModule,
+			fxgenerate.FxGenerateModule,
+			fxhttpserver.FxHttpServerModule,
+			fxhttpserver.AsHandler("POST", "/idempotency", handler),
+			fx.Populate(&httpServer),
+		).RequireStart().RequireStop()
+
+		for _, remoteAddr := range []string{"10.0.0.1:1234", "10.0.0.2:1234", "10.0.0.1:5678"} {
+			req := httptest.NewRequest(http.MethodPost, "/idempotency", strings.NewReader(`{"amount":10}`))
+			req.RemoteAddr = remoteAddr
+			req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, "key")
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+		}
+
+		// the key of another client is not replayed
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("subject", func(t *testing.T) {
+		t.Setenv("IDEMPOTENCY_SCOPE", "subject")
+
+		var calls atomic.Int32
+
+		handler := func(c echo.Context) error {
+			calls.Add(1)
+
+			return c.String(http.StatusCreated, "created by "+fxhttpserver.CtxPrincipal(c).Subject)
+		}
+
+		var httpServer *echo.Echo
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxtrace.FxTraceModule,
+			fxmetrics.FxMetricsModule,
+			fxgenerate.FxGenerateModule,
+			fxhttpserver.FxHttpServerModule,
+			fxhttpserver.AsHandler("POST", "/idempotency", handler, fxhttpserver.NewFxBasicAuthMiddleware),
+			fx.Populate(&httpServer),
+		).RequireStart().RequireStop()
+
+		// the requests come from the same client IP, but the keys are scoped per authenticated subject
+		for _, username := range []string{"admin", "user", "admin"} {
+			req := httptest.NewRequest(http.MethodPost, "/idempotency", strings.NewReader(`{"amount":10}`))
+			req.Header.Set(httpservermiddleware.HeaderIdempotencyKey, "key")
+			req.SetBasicAuth(username, username+"-password")
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, "created by "+username, rec.Body.String())
+		}
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestModuleWithInvalidIdempotencyStore(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("IDEMPOTENCY_ENABLED", "true")
+
+	tests := []struct {
+		name     string
+		store    string
+		dialect  string
+		scope    string
+		expected string
+	}{
+		{
+			name:     "invalid store",
+			store:    "invalid",
+			expected: `invalid idempotency store "invalid"`,
+		},
+		{
+			name:     "sql store without database",
+			store:    "sql",
+			dialect:  "sqlite",
+			expected: "idempotency sql store requires a database, provided by the fxsql module",
+		},
+		{
+			name:     "invalid scope",
+			store:    "memory",
+			scope:    "invalid",
+			expected: `invalid idempotency scope "invalid"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IDEMPOTENCY_STORE", tt.store)
+			t.Setenv("IDEMPOTENCY_SQL_DIALECT", tt.dialect)
+			t.Setenv("IDEMPOTENCY_SCOPE", tt.scope)
+
+			var httpServer *echo.Echo
+
+			app := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Populate(&httpServer),
+			)
+
+			assert.Error(t, app.Err())
+			assert.Contains(t, app.Err().Error(), tt.expected)
+		})
+	}
+}
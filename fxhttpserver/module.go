@@ -2,14 +2,20 @@ package fxhttpserver
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"io/fs"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/httpserver"
 	httpservermiddleware "github.com/ankorstore/yokai/httpserver/middleware"
 	"github.com/ankorstore/yokai/log"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	gommonlog "github.com/labstack/gommon/log"
@@ -31,41 +37,93 @@ var FxHttpServerModule = fx.Module(
 	fx.Provide(
 		httpserver.NewDefaultHttpServerFactory,
 		NewFxHttpServerRegistry,
+		NewFxStreamManager,
 		NewFxHttpServer,
+		NewFxHttpServerPool,
+		NewFxOpenApiSpec,
+		fx.Annotate(
+			func(spec *OpenApiSpec) *OpenApiSpec {
+				return spec
+			},
+			fx.As(new(interface{})),
+			fx.ResultTags(`group:"core-openapi-specs"`),
+		),
+		fx.Annotate(
+			NewFxHttpServerRoutes,
+			fx.As(new(interface{})),
+			fx.ResultTags(`group:"core-routes"`),
+		),
 		fx.Annotate(
 			NewFxHttpServerModuleInfo,
 			fx.As(new(interface{})),
 			fx.ResultTags(`group:"core-module-infos"`),
 		),
 	),
+	// the named http servers are started with the application
+	fx.Invoke(func(*HttpServerPool) {}),
 )
 
 // FxHttpServerParam allows injection of the required dependencies in [NewFxHttpServer].
 type FxHttpServerParam struct {
 	fx.In
-	LifeCycle       fx.Lifecycle
-	Factory         httpserver.HttpServerFactory
-	Generator       uuid.UuidGenerator
-	Registry        *HttpServerRegistry
-	Config          *config.Config
-	Logger          *log.Logger
-	TracerProvider  trace.TracerProvider
-	MetricsRegistry *prometheus.Registry
+	LifeCycle        fx.Lifecycle
+	Factory          httpserver.HttpServerFactory
+	Generator        uuid.UuidGenerator
+	Registry         *HttpServerRegistry
+	Streams          *StreamManager
+	OpenApiSpec      *OpenApiSpec
+	TemplatesFS      fs.FS                                 `name:"httpserver-templates-fs" optional:"true"`
+	Validator        *validator.Validate                   `optional:"true"`
+	ErrorMapper      *httpserver.ErrorMapper               `optional:"true"`
+	Database         *sql.DB                               `optional:"true"`
+	IdempotencyStore httpservermiddleware.IdempotencyStore `optional:"true"`
+	TimeoutRules     []TimeoutRule                         `group:"httpserver-timeout-rules"`
+	CoreMiddlewares  []any                                 `group:"core-http-middlewares"`
+	ShutdownRecorder healthcheck.ShutdownRecorder          `optional:"true"`
+	Config           *config.Config
+	Logger           *log.Logger
+	TracerProvider   trace.TracerProvider
+	MetricsRegistry  *prometheus.Registry
 }
 
-// NewFxHttpServer returns a new [echo.Echo].
+// NewFxHttpServer returns a new [echo.Echo], for the default http server.
 func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
+	p.TimeoutRules = filterTimeoutRules(p.TimeoutRules, DefaultServerName)
+
+	return newHttpServer(p, DefaultServerName, serverMetricsRegisterer(p.Config, p.MetricsRegistry))
+}
+
+//nolint:cyclop
+func newHttpServer(p FxHttpServerParam, name string, registerer prometheus.Registerer) (*echo.Echo, error) {
 	appDebug := p.Config.AppDebug()
 
 	// logger
-	echoLogger := httpserver.NewEchoLogger(
-		log.FromZerolog(p.Logger.ToZerolog().With().Str("module", ModuleName).Logger()),
-	)
+	loggerContext := p.Logger.ToZerolog().With().Str("module", ModuleName)
+	if name != DefaultServerName {
+		loggerContext = loggerContext.Str("server", name)
+	}
+
+	echoLogger := httpserver.NewEchoLogger(log.FromZerolog(loggerContext.Logger()))
 
 	// renderer
 	var echoRenderer echo.Renderer
 	if p.Config.GetBool("modules.http.server.templates.enabled") {
-		echoRenderer = httpserver.NewHtmlTemplateRenderer(p.Config.GetString("modules.http.server.templates.path"))
+		// live reload by default in dev env
+		templatesReload := p.Config.IsDevEnv()
+		if p.Config.IsSet("modules.http.server.templates.reload") {
+			templatesReload = p.Config.GetBool("modules.http.server.templates.reload")
+		}
+
+		htmlTemplateRenderer, err := NewHtmlTemplateRenderer(
+			p.TemplatesFS,
+			p.Config.GetString("modules.http.server.templates.path"),
+			templatesReload,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load http server templates: %w", err)
+		}
+
+		echoRenderer = htmlTemplateRenderer
 	}
 
 	// error handler
@@ -74,34 +132,76 @@ func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
 	if len(resolvedErrorHandlers) > 0 {
 		echoErrorHandler = resolvedErrorHandlers[0]
 	} else {
-		echoErrorHandler = httpserver.NewJsonErrorHandler(
-			p.Config.GetBool("modules.http.server.errors.obfuscate") || !appDebug,
-			p.Config.GetBool("modules.http.server.errors.stack") || appDebug,
-		)
+		var err error
+		echoErrorHandler, err = newErrorHandler(p, appDebug)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// server
 	httpServer, err := p.Factory.Create(
-		httpserver.WithDebug(appDebug),
-		httpserver.WithBanner(false),
-		httpserver.WithLogger(echoLogger),
-		httpserver.WithRenderer(echoRenderer),
-		httpserver.WithHttpErrorHandler(echoErrorHandler.Handle()),
+		append(
+			serverOptions(p.Config),
+			httpserver.WithDebug(appDebug),
+			httpserver.WithBanner(false),
+			httpserver.WithLogger(echoLogger),
+			httpserver.WithRenderer(echoRenderer),
+			httpserver.WithHttpErrorHandler(echoErrorHandler.Handle()),
+		)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create http server: %w", err)
 	}
 
+	// validator
+	if p.Validator != nil {
+		httpServer.Validator = NewEchoValidator(p.Validator)
+	}
+
+	// tls
+	var tlsReloader *TlsCertificateReloader
+	if p.Config.GetBool("modules.http.server.tls.enabled") {
+		tlsReloader, err = NewTlsCertificateReloader(
+			p.Config.GetString("modules.http.server.tls.cert"),
+			p.Config.GetString("modules.http.server.tls.key"),
+			p.Config.GetString("modules.http.server.tls.client_ca"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load http server tls certificate: %w", err)
+		}
+
+		httpServer.TLSServer.TLSConfig, err = NewTlsConfig(p.Config, tlsReloader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http server tls config: %w", err)
+		}
+	}
+
 	// middlewares registrations
-	httpServer = withDefaultMiddlewares(httpServer, p)
+	httpServer, routeMiddlewares, err := withDefaultMiddlewares(httpServer, p, registerer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register http server default middlewares: %w", err)
+	}
 
 	// groups, handlers & middlewares registrations
-	httpServer, err = withRegisteredResources(httpServer, p)
+	httpServer, err = withRegisteredResources(httpServer, p, routeMiddlewares)
 	if err != nil {
 		return httpServer, fmt.Errorf("failed to register http server resources: %w", err)
 	}
 
+	// openapi specification
+	if p.Config.GetBool("modules.http.server.openapi.expose") {
+		openApiPath := p.Config.GetString("modules.http.server.openapi.path")
+		if openApiPath == "" {
+			openApiPath = DefaultOpenApiPath
+		}
+
+		httpServer.GET(openApiPath, p.OpenApiSpec.Handle())
+	}
+
 	// lifecycles
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+
 	p.LifeCycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			if !p.Config.IsTestEnv() {
@@ -110,15 +210,38 @@ func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
 					address = DefaultAddress
 				}
 
-				//nolint:errcheck
-				go httpServer.Start(address)
+				if tlsReloader != nil {
+					reloadInterval := DefaultTlsReloadInterval
+					if p.Config.IsSet("modules.http.server.tls.reload_interval") {
+						reloadInterval = p.Config.GetInt("modules.http.server.tls.reload_interval")
+					}
+
+					if reloadInterval > 0 {
+						go tlsReloader.Watch(watchCtx, time.Duration(reloadInterval)*time.Second, p.Logger)
+					}
+
+					httpServer.TLSServer.Addr = address
+
+					//nolint:errcheck
+					go httpServer.StartServer(httpServer.TLSServer)
+				} else {
+					//nolint:errcheck
+					go httpServer.Start(address)
+				}
 			}
 
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
+			watchCancel()
+
+			// streams connections are closed first, since the server shutdown waits for their handlers
+			if err := p.Streams.Shutdown(ctx); err != nil {
+				httpServer.Logger.Errorf("cannot close streams connections: %v", err)
+			}
+
 			if !p.Config.IsTestEnv() {
-				return httpServer.Shutdown(ctx)
+				return shutdownHttpServer(ctx, httpServer, name, p)
 			}
 
 			return nil
@@ -128,7 +251,11 @@ func NewFxHttpServer(p FxHttpServerParam) (*echo.Echo, error) {
 	return httpServer, nil
 }
 
-func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) *echo.Echo {
+// withDefaultMiddlewares registers the default middlewares, and returns the ones to attach on the registered routes,
+// after their own middlewares.
+func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam, registerer prometheus.Registerer) (*echo.Echo, []echo.MiddlewareFunc, error) {
+	var routeMiddlewares []echo.MiddlewareFunc
+
 	// request id middleware
 	httpServer.Use(httpservermiddleware.RequestIdMiddlewareWithConfig(
 		httpservermiddleware.RequestIdMiddlewareConfig{
@@ -147,6 +274,11 @@ func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) *echo.Ec
 		))
 	}
 
+	// tls client certificate middleware
+	if p.Config.GetBool("modules.http.server.tls.enabled") {
+		httpServer.Use(TlsClientCertificateMiddleware())
+	}
+
 	// request logger middleware
 	requestHeadersToLog := map[string]string{
 		httpservermiddleware.HeaderXRequestId: httpservermiddleware.LogFieldRequestId,
@@ -180,28 +312,136 @@ func withDefaultMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) *echo.Ec
 		}
 
 		metricsMiddlewareConfig := httpservermiddleware.RequestMetricsMiddlewareConfig{
-			Registry:                p.MetricsRegistry,
+			Registry:                registerer,
 			Namespace:               namespace,
 			Subsystem:               subsystem,
 			Buckets:                 buckets,
 			NormalizeRequestPath:    p.Config.GetBool("modules.http.server.metrics.normalize.request_path"),
 			NormalizeResponseStatus: p.Config.GetBool("modules.http.server.metrics.normalize.response_status"),
+			CollectCompressionRatio: p.Config.GetBool("modules.http.server.compression.enabled"),
+			CollectConditional:      p.Config.GetBool("modules.http.server.etag.enabled"),
 		}
 
 		httpServer.Use(httpservermiddleware.RequestMetricsMiddlewareWithConfig(metricsMiddlewareConfig))
 	}
 
+	// security middlewares
+	httpServer, err := withSecurityMiddlewares(httpServer, p)
+	if err != nil {
+		return httpServer, nil, err
+	}
+
+	// core middlewares (like the fxcore maintenance mode one)
+	for _, coreMiddleware := range p.CoreMiddlewares {
+		if middleware, ok := coreMiddleware.(Middleware); ok {
+			httpServer.Use(middleware.Handle())
+		}
+	}
+
+	// timeout middleware
+	httpServer, err = withTimeoutMiddleware(httpServer, p, registerer)
+	if err != nil {
+		return httpServer, nil, err
+	}
+
+	// rate limit middleware
+	if p.Config.GetBool("modules.http.server.ratelimit.enabled") {
+		var rateLimitRouteMiddleware echo.MiddlewareFunc
+
+		httpServer, rateLimitRouteMiddleware, err = withRateLimitMiddleware(httpServer, p, registerer)
+		if err != nil {
+			return httpServer, nil, err
+		}
+
+		// subject keyed rate limits, after the routes authentication
+		if rateLimitRouteMiddleware != nil {
+			routeMiddlewares = append(routeMiddlewares, rateLimitRouteMiddleware)
+		}
+	}
+
+	// compression middleware
+	if p.Config.GetBool("modules.http.server.compression.enabled") {
+		minSize := p.Config.GetInt("modules.http.server.compression.min_size")
+		if minSize == 0 {
+			minSize = DefaultCompressionMinSize
+		}
+
+		encodings := p.Config.GetStringSlice("modules.http.server.compression.encodings")
+		if err = ValidateCompressionEncodings(encodings); err != nil {
+			return httpServer, nil, err
+		}
+
+		excludes := p.Config.GetStringSlice("modules.http.server.compression.exclude")
+
+		httpServer.Use(CompressionMiddlewareWithConfig(CompressionMiddlewareConfig{
+			Skipper: func(c echo.Context) bool {
+				return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+			},
+			Encodings:    encodings,
+			MinSize:      minSize,
+			ContentTypes: p.Config.GetStringSlice("modules.http.server.compression.content_types"),
+		}))
+	}
+
+	// etag middleware
+	if p.Config.GetBool("modules.http.server.etag.enabled") {
+		excludes := p.Config.GetStringSlice("modules.http.server.etag.exclude")
+
+		httpServer.Use(ETagMiddlewareWithConfig(ETagMiddlewareConfig{
+			Skipper: func(c echo.Context) bool {
+				return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+			},
+			Weak: p.Config.GetBool("modules.http.server.etag.weak"),
+		}))
+	}
+
+	// openapi validation middleware
+	if p.Config.GetBool("modules.http.server.openapi.validate.enabled") {
+		router, err := NewOpenApiRouter(p.Config.GetString("modules.http.server.openapi.validate.spec"))
+		if err != nil {
+			return httpServer, nil, err
+		}
+
+		excludes := p.Config.GetStringSlice("modules.http.server.openapi.validate.exclude")
+
+		httpServer.Use(OpenApiValidationMiddlewareWithConfig(OpenApiValidationMiddlewareConfig{
+			Skipper: func(c echo.Context) bool {
+				return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+			},
+			Router:            router,
+			Registry:          registerer,
+			Namespace:         Sanitize(p.Config.GetString("modules.http.server.metrics.collect.namespace")),
+			Subsystem:         Sanitize(p.Config.GetString("modules.http.server.metrics.collect.subsystem")),
+			ValidateResponses: p.Config.GetBool("modules.http.server.openapi.validate.responses") && !p.Config.IsProdEnv(),
+		}))
+	}
+
+	// idempotency middleware, after the compression and etag ones to store the uncompressed responses
+	if p.Config.GetBool("modules.http.server.idempotency.enabled") {
+		var idempotencyRouteMiddleware echo.MiddlewareFunc
+
+		httpServer, idempotencyRouteMiddleware, err = withIdempotencyMiddleware(httpServer, p)
+		if err != nil {
+			return httpServer, nil, err
+		}
+
+		// subject scoped idempotency keys, after the routes authentication
+		if idempotencyRouteMiddleware != nil {
+			routeMiddlewares = append(routeMiddlewares, idempotencyRouteMiddleware)
+		}
+	}
+
 	// recovery middleware
 	httpServer.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		DisableErrorHandler: true,
 		LogLevel:            gommonlog.ERROR,
 	}))
 
-	return httpServer
+	return httpServer, routeMiddlewares, nil
 }
 
 //nolint:cyclop
-func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) (*echo.Echo, error) {
+func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam, routeMiddlewares []echo.MiddlewareFunc) (*echo.Echo, error) {
 	// register handler groups
 	resolvedHandlersGroups, err := p.Registry.ResolveHandlersGroups()
 	if err != nil {
@@ -222,7 +462,7 @@ func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) (*echo.
 					method,
 					h.Path(),
 					h.Handler(),
-					h.Middlewares()...,
+					slices.Concat(h.Middlewares(), routeMiddlewares)...,
 				)
 
 				httpServer.Logger.Debugf("registering handler in group for [%s] %s%s", method, g.Prefix(), h.Path())
@@ -267,7 +507,7 @@ func withRegisteredResources(httpServer *echo.Echo, p FxHttpServerParam) (*echo.
 				method,
 				h.Path(),
 				h.Handler(),
-				h.Middlewares()...,
+				slices.Concat(h.Middlewares(), routeMiddlewares)...,
 			)
 
 			httpServer.Logger.Debugf("registered handler for [%s] %s", method, h.Path())
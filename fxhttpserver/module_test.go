@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxgenerate"
 	"github.com/ankorstore/yokai/fxhttpserver"
@@ -1257,3 +1258,39 @@ func TestModuleDecoration(t *testing.T) {
 
 	assert.False(t, httpServer.HideBanner)
 }
+
+func TestModuleWithCoreMiddlewares(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(
+			service.NewTestService,
+			fx.Annotate(
+				func(cfg *config.Config) []any {
+					// the non middleware items of the group are ignored
+					return []any{middleware.NewTestGlobalMiddleware(cfg), "invalid"}
+				},
+				fx.ResultTags(`group:"core-http-middlewares,flatten"`),
+			),
+		),
+		fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/bar", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("global-middleware"))
+}
@@ -0,0 +1,670 @@
+package fxhttpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+)
+
+const (
+	OpenApiVersion         = "3.1.0"
+	DefaultOpenApiPath     = "/openapi.json"
+	DefaultOpenApiMimeType = "application/json"
+	DefaultValidationTag   = "validate"
+)
+
+// OpenApiOperation is the OpenAPI declaration of a handler.
+type OpenApiOperation struct {
+	OperationId string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	// Request is the handler request type: its param, query and header tagged fields are documented as parameters,
+	// and its json tagged fields as the request body schema.
+	Request any
+	// Responses are the handler response types by status code, a nil type meaning no content.
+	Responses map[int]any
+}
+
+// OpenApiHandler is the optional interface for handlers declaring their OpenAPI operation.
+type OpenApiHandler interface {
+	Handler
+	OpenApi() *OpenApiOperation
+}
+
+// OpenApiDocument is an OpenAPI document.
+type OpenApiDocument struct {
+	OpenApi    string                                      `json:"openapi"`
+	Info       OpenApiInfo                                 `json:"info"`
+	Paths      map[string]map[string]*OpenApiPathOperation `json:"paths"`
+	Components *OpenApiComponents                          `json:"components,omitempty"`
+}
+
+// OpenApiInfo is an OpenAPI document info.
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenApiPathOperation is an OpenAPI document path operation.
+type OpenApiPathOperation struct {
+	OperationId string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []*OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenApiResponse `json:"responses"`
+}
+
+// OpenApiParameter is an OpenAPI document operation parameter.
+type OpenApiParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenApiSchema `json:"schema"`
+}
+
+// OpenApiRequestBody is an OpenAPI document operation request body.
+type OpenApiRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenApiMediaType `json:"content"`
+}
+
+// OpenApiResponse is an OpenAPI document operation response.
+type OpenApiResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenApiMediaType `json:"content,omitempty"`
+}
+
+// OpenApiMediaType is an OpenAPI document media type.
+type OpenApiMediaType struct {
+	Schema *OpenApiSchema `json:"schema"`
+}
+
+// OpenApiComponents are OpenAPI document components.
+type OpenApiComponents struct {
+	Schemas map[string]*OpenApiSchema `json:"schemas,omitempty"`
+}
+
+// OpenApiSchema is an OpenAPI document schema.
+type OpenApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenApiSchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenApiSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64                  `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64                  `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+}
+
+// OpenApiGenerator generates an [OpenApiDocument] from the handlers registered in a [HttpServerRegistry].
+type OpenApiGenerator struct {
+	mutex         sync.Mutex
+	registry      *HttpServerRegistry
+	info          OpenApiInfo
+	validationTag string
+	schemas       map[string]*OpenApiSchema
+	names         map[reflect.Type]string
+}
+
+// NewOpenApiGenerator returns a new [OpenApiGenerator], using the provided struct tag to read the validation rules.
+func NewOpenApiGenerator(registry *HttpServerRegistry, info OpenApiInfo, validationTag string) *OpenApiGenerator {
+	if validationTag == "" {
+		validationTag = DefaultValidationTag
+	}
+
+	return &OpenApiGenerator{
+		registry:      registry,
+		info:          info,
+		validationTag: validationTag,
+	}
+}
+
+// Generate generates the [OpenApiDocument].
+func (g *OpenApiGenerator) Generate() (*OpenApiDocument, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.schemas = map[string]*OpenApiSchema{}
+	g.names = map[reflect.Type]string{}
+
+	document := &OpenApiDocument{
+		OpenApi: OpenApiVersion,
+		Info:    g.info,
+		Paths:   map[string]map[string]*OpenApiPathOperation{},
+	}
+
+	for _, groupDef := range g.registry.handlersGroupDefinitions {
+		for _, handlerDef := range groupDef.Handlers() {
+			err := g.addHandlerDefinition(document, groupDef.Prefix(), handlerDef)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, handlerDef := range g.registry.handlerDefinitions {
+		err := g.addHandlerDefinition(document, "", handlerDef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(g.schemas) > 0 {
+		document.Components = &OpenApiComponents{
+			Schemas: g.schemas,
+		}
+	}
+
+	return document, nil
+}
+
+func (g *OpenApiGenerator) addHandlerDefinition(document *OpenApiDocument, prefix string, handlerDef HandlerDefinition) error {
+	if handlerDef.Method() == AllMethods {
+		return nil
+	}
+
+	methods, err := ExtractMethods(handlerDef.Method())
+	if err != nil {
+		return err
+	}
+
+	var declaration *OpenApiOperation
+	if openApiHandler, ok := handlerDef.Handler().(OpenApiHandler); ok {
+		declaration = openApiHandler.OpenApi()
+	} else if !handlerDef.Concrete() {
+		if handlerName, ok := handlerDef.Handler().(string); ok {
+			if registeredHandler, err := g.registry.lookupRegisteredHandler(handlerName); err == nil {
+				if openApiHandler, ok := registeredHandler.(OpenApiHandler); ok {
+					declaration = openApiHandler.OpenApi()
+				}
+			}
+		}
+	}
+
+	if declaration == nil {
+		declaration = &OpenApiOperation{}
+	}
+
+	documentPath, pathParams := g.convertPath(prefix + handlerDef.Path())
+
+	for _, method := range methods {
+		operation, err := g.buildOperation(declaration, pathParams)
+		if err != nil {
+			return fmt.Errorf("cannot build openapi operation for [%s] %s: %w", method, documentPath, err)
+		}
+
+		if _, ok := document.Paths[documentPath]; !ok {
+			document.Paths[documentPath] = map[string]*OpenApiPathOperation{}
+		}
+
+		document.Paths[documentPath][strings.ToLower(method)] = operation
+	}
+
+	return nil
+}
+
+func (g *OpenApiGenerator) convertPath(routePath string) (string, []string) {
+	var params []string
+
+	parts := strings.Split(routePath, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			params = append(params, part[1:])
+			parts[i] = fmt.Sprintf("{%s}", part[1:])
+		}
+	}
+
+	return path.Clean("/" + strings.Join(parts, "/")), params
+}
+
+//nolint:cyclop
+func (g *OpenApiGenerator) buildOperation(declaration *OpenApiOperation, pathParams []string) (*OpenApiPathOperation, error) {
+	operation := &OpenApiPathOperation{
+		OperationId: declaration.OperationId,
+		Summary:     declaration.Summary,
+		Description: declaration.Description,
+		Tags:        declaration.Tags,
+		Deprecated:  declaration.Deprecated,
+		Responses:   map[string]*OpenApiResponse{},
+	}
+
+	declaredParams := map[string]bool{}
+
+	if declaration.Request != nil {
+		requestType := derefType(reflect.TypeOf(declaration.Request))
+		if requestType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("request type %s is not a struct", requestType)
+		}
+
+		bodySchema := &OpenApiSchema{Type: "object", Properties: map[string]*OpenApiSchema{}}
+
+		for _, field := range structFields(requestType) {
+			for _, in := range []string{"path", "query", "header"} {
+				tag := map[string]string{"path": "param", "query": "query", "header": "header"}[in]
+
+				name := tagName(field, tag)
+				if name == "" {
+					continue
+				}
+
+				required := in == "path" || g.hasValidation(field, "required")
+				operation.Parameters = append(operation.Parameters, &OpenApiParameter{
+					Name:     name,
+					In:       in,
+					Required: required,
+					Schema:   g.fieldSchema(field),
+				})
+
+				if in == "path" {
+					declaredParams[name] = true
+				}
+			}
+
+			if name := tagName(field, "json"); name != "" {
+				bodySchema.Properties[name] = g.fieldSchema(field)
+				if g.hasValidation(field, "required") {
+					bodySchema.Required = append(bodySchema.Required, name)
+				}
+			}
+		}
+
+		if len(bodySchema.Properties) > 0 {
+			operation.RequestBody = &OpenApiRequestBody{
+				Required: true,
+				Content: map[string]*OpenApiMediaType{
+					DefaultOpenApiMimeType: {Schema: bodySchema},
+				},
+			}
+		}
+	}
+
+	for _, param := range pathParams {
+		if !declaredParams[param] {
+			operation.Parameters = append(operation.Parameters, &OpenApiParameter{
+				Name:     param,
+				In:       "path",
+				Required: true,
+				Schema:   &OpenApiSchema{Type: "string"},
+			})
+		}
+	}
+
+	sort.SliceStable(operation.Parameters, func(i, j int) bool {
+		return operation.Parameters[i].In == "path" && operation.Parameters[j].In != "path"
+	})
+
+	if len(declaration.Responses) == 0 {
+		operation.Responses[strconv.Itoa(http.StatusOK)] = &OpenApiResponse{Description: http.StatusText(http.StatusOK)}
+	}
+
+	for status, responseType := range declaration.Responses {
+		response := &OpenApiResponse{Description: http.StatusText(status)}
+
+		if responseType != nil {
+			response.Content = map[string]*OpenApiMediaType{
+				DefaultOpenApiMimeType: {Schema: g.typeSchema(reflect.TypeOf(responseType))},
+			}
+		}
+
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	return operation, nil
+}
+
+func (g *OpenApiGenerator) fieldSchema(field reflect.StructField) *OpenApiSchema {
+	schema := g.typeSchema(field.Type)
+
+	if schema.Ref != "" {
+		return schema
+	}
+
+	g.applyValidations(schema, field)
+
+	return schema
+}
+
+//nolint:cyclop
+func (g *OpenApiGenerator) typeSchema(t reflect.Type) *OpenApiSchema {
+	t = derefType(t)
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenApiSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenApiSchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &OpenApiSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenApiSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenApiSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenApiSchema{Type: "string", Format: "byte"}
+		}
+
+		return &OpenApiSchema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &OpenApiSchema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return &OpenApiSchema{}
+	}
+}
+
+func (g *OpenApiGenerator) structSchema(t reflect.Type) *OpenApiSchema {
+	if t.Name() == "" {
+		return g.objectSchema(t)
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := g.schemas[name]; taken {
+			name = fmt.Sprintf("%s.%s", path.Base(t.PkgPath()), t.Name())
+		}
+
+		// registered before resolution to support recursive types
+		g.names[t] = name
+		g.schemas[name] = &OpenApiSchema{}
+		*g.schemas[name] = *g.objectSchema(t)
+	}
+
+	return &OpenApiSchema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *OpenApiGenerator) objectSchema(t reflect.Type) *OpenApiSchema {
+	schema := &OpenApiSchema{Type: "object", Properties: map[string]*OpenApiSchema{}}
+
+	for _, field := range structFields(t) {
+		name := tagName(field, "json")
+		if name == "" {
+			if field.Tag.Get("json") != "" {
+				continue
+			}
+
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.fieldSchema(field)
+		if g.hasValidation(field, "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// OpenApiSpec provides the JSON OpenAPI specification of the registered handlers.
+type OpenApiSpec struct {
+	generator *OpenApiGenerator
+	mutex     sync.Mutex
+	spec      []byte
+}
+
+// NewOpenApiSpec returns a new [OpenApiSpec].
+func NewOpenApiSpec(generator *OpenApiGenerator) *OpenApiSpec {
+	return &OpenApiSpec{
+		generator: generator,
+	}
+}
+
+// FxOpenApiSpecParam allows injection of the required dependencies in [NewFxOpenApiSpec].
+type FxOpenApiSpecParam struct {
+	fx.In
+	Config   *config.Config
+	Registry *HttpServerRegistry
+}
+
+// NewFxOpenApiSpec returns a new [OpenApiSpec], with info from the modules.http.server.openapi.* configuration,
+// and validation rules read from the modules.validator.tag_name struct tag (default validate).
+func NewFxOpenApiSpec(p FxOpenApiSpecParam) *OpenApiSpec {
+	info := OpenApiInfo{
+		Title:       p.Config.GetString("modules.http.server.openapi.title"),
+		Description: p.Config.GetString("modules.http.server.openapi.description"),
+		Version:     p.Config.GetString("modules.http.server.openapi.version"),
+	}
+
+	if info.Title == "" {
+		info.Title = p.Config.AppName()
+	}
+
+	if info.Description == "" {
+		info.Description = p.Config.AppDescription()
+	}
+
+	if info.Version == "" {
+		info.Version = p.Config.AppVersion()
+	}
+
+	return NewOpenApiSpec(NewOpenApiGenerator(p.Registry, info, p.Config.GetString("modules.validator.tag_name")))
+}
+
+// Name returns the name of the OpenAPI specification.
+func (s *OpenApiSpec) Name() string {
+	return ModuleName
+}
+
+// Document generates the [OpenApiDocument].
+func (s *OpenApiSpec) Document() (*OpenApiDocument, error) {
+	return s.generator.Generate()
+}
+
+// OpenApiSpec returns the JSON OpenAPI specification, generated once.
+func (s *OpenApiSpec) OpenApiSpec() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.spec == nil {
+		document, err := s.Document()
+		if err != nil {
+			return nil, err
+		}
+
+		spec, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal openapi document: %w", err)
+		}
+
+		s.spec = spec
+	}
+
+	return s.spec, nil
+}
+
+// Handle returns the [echo.HandlerFunc] serving the JSON OpenAPI specification.
+func (s *OpenApiSpec) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		spec, err := s.OpenApiSpec()
+		if err != nil {
+			return err
+		}
+
+		return c.JSONBlob(http.StatusOK, spec)
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// structFields returns the exported fields of a struct type, with anonymous struct fields flattened.
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && derefType(field.Type).Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, structFields(derefType(field.Type))...)
+
+			continue
+		}
+
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+func (g *OpenApiGenerator) validations(field reflect.StructField) map[string]string {
+	rules := map[string]string{}
+
+	for _, rule := range strings.Split(field.Tag.Get(g.validationTag), ",") {
+		if rule == "" || rule == "dive" {
+			break
+		}
+
+		key, value, _ := strings.Cut(rule, "=")
+		rules[key] = value
+	}
+
+	return rules
+}
+
+func (g *OpenApiGenerator) hasValidation(field reflect.StructField, rule string) bool {
+	_, ok := g.validations(field)[rule]
+
+	return ok
+}
+
+//nolint:cyclop
+func (g *OpenApiGenerator) applyValidations(schema *OpenApiSchema, field reflect.StructField) {
+	formats := map[string]string{
+		"email":    "email",
+		"url":      "uri",
+		"uri":      "uri",
+		"uuid":     "uuid",
+		"uuid4":    "uuid",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"hostname": "hostname",
+		"datetime": "date-time",
+	}
+
+	for rule, value := range g.validations(field) {
+		if format, ok := formats[rule]; ok {
+			schema.Format = format
+
+			continue
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil && rule != "oneof" {
+			continue
+		}
+
+		switch rule {
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				if schema.Type == "integer" || schema.Type == "number" {
+					if n, err := strconv.ParseFloat(v, 64); err == nil {
+						schema.Enum = append(schema.Enum, n)
+
+						continue
+					}
+				}
+
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "len":
+			applyBound(schema, &number, &number)
+		case "min", "gte":
+			applyBound(schema, &number, nil)
+		case "max", "lte":
+			applyBound(schema, nil, &number)
+		case "gt":
+			if schema.Type == "integer" || schema.Type == "number" {
+				schema.ExclusiveMinimum = &number
+			}
+		case "lt":
+			if schema.Type == "integer" || schema.Type == "number" {
+				schema.ExclusiveMaximum = &number
+			}
+		}
+	}
+}
+
+func applyBound(schema *OpenApiSchema, lower *float64, upper *float64) {
+	toInt := func(f *float64) *int {
+		if f == nil {
+			return nil
+		}
+
+		i := int(*f)
+
+		return &i
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if lower != nil {
+			schema.Minimum = lower
+		}
+		if upper != nil {
+			schema.Maximum = upper
+		}
+	case "string":
+		if lower != nil {
+			schema.MinLength = toInt(lower)
+		}
+		if upper != nil {
+			schema.MaxLength = toInt(upper)
+		}
+	case "array":
+		if lower != nil {
+			schema.MinItems = toInt(lower)
+		}
+		if upper != nil {
+			schema.MaxItems = toInt(upper)
+		}
+	}
+}
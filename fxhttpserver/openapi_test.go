@@ -0,0 +1,201 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithOpenApi(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("OPENAPI_EXPOSE", "true")
+	t.Setenv("OPENAPI_PATH", "/docs/openapi.json")
+	t.Setenv("OPENAPI_TITLE", "test api")
+
+	var httpServer *echo.Echo
+	var spec *fxhttpserver.OpenApiSpec
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsHandler("PUT,PATCH", "/users/:id", handler.NewTestOpenApiHandler),
+		fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		fxhttpserver.AsHandler("*", "/any", concreteHandler),
+		fxhttpserver.AsHandlersGroup(
+			"/foo",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration("GET", "/:name/baz", concreteHandler),
+			},
+		),
+		fx.Populate(&httpServer, &spec),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, "httpserver", spec.Name())
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var document fxhttpserver.OpenApiDocument
+	err := json.Unmarshal(rec.Body.Bytes(), &document)
+	assert.NoError(t, err)
+
+	// info
+	assert.Equal(t, "3.1.0", document.OpenApi)
+	assert.Equal(t, "test api", document.Info.Title)
+	assert.Equal(t, "0.1.0", document.Info.Version)
+
+	// paths
+	assert.Len(t, document.Paths, 3)
+	assert.NotContains(t, document.Paths, "/any")
+
+	bar := document.Paths["/bar"]["get"]
+	assert.NotNil(t, bar)
+	assert.Equal(t, "OK", bar.Responses["200"].Description)
+
+	baz := document.Paths["/foo/{name}/baz"]["get"]
+	assert.NotNil(t, baz)
+	assert.Len(t, baz.Parameters, 1)
+	assert.Equal(t, "name", baz.Parameters[0].Name)
+	assert.Equal(t, "path", baz.Parameters[0].In)
+	assert.True(t, baz.Parameters[0].Required)
+
+	for _, method := range []string{"put", "patch"} {
+		op := document.Paths["/users/{id}"][method]
+		assert.NotNil(t, op)
+		assert.Equal(t, "updateUser", op.OperationId)
+		assert.Equal(t, "Update a user", op.Summary)
+		assert.Equal(t, []string{"users"}, op.Tags)
+
+		// parameters
+		assert.Len(t, op.Parameters, 3)
+		assert.Equal(t, "id", op.Parameters[0].Name)
+		assert.Equal(t, "path", op.Parameters[0].In)
+		assert.Equal(t, "integer", op.Parameters[0].Schema.Type)
+		assert.Equal(t, "dryRun", op.Parameters[1].Name)
+		assert.Equal(t, "query", op.Parameters[1].In)
+		assert.Equal(t, "boolean", op.Parameters[1].Schema.Type)
+		assert.False(t, op.Parameters[1].Required)
+		assert.Equal(t, "X-Tenant", op.Parameters[2].Name)
+		assert.Equal(t, "header", op.Parameters[2].In)
+		assert.True(t, op.Parameters[2].Required)
+
+		// request body
+		body := op.RequestBody.Content["application/json"].Schema
+		assert.Equal(t, "object", body.Type)
+		assert.Equal(t, []string{"name"}, body.Required)
+		assert.Equal(t, "string", body.Properties["name"].Type)
+		assert.Equal(t, "#/components/schemas/TestAddress", body.Properties["address"].Ref)
+
+		// responses
+		assert.Equal(t, "#/components/schemas/TestUser", op.Responses["200"].Content["application/json"].Schema.Ref)
+		assert.Equal(t, "No Content", op.Responses["204"].Description)
+		assert.Nil(t, op.Responses["204"].Content)
+	}
+
+	// components
+	user := document.Components.Schemas["TestUser"]
+	assert.Equal(t, "object", user.Type)
+	assert.Equal(t, []string{"name"}, user.Required)
+	assert.Len(t, user.Properties, 9)
+	assert.Equal(t, "int64", user.Properties["id"].Format)
+	assert.Equal(t, 2, *user.Properties["name"].MinLength)
+	assert.Equal(t, 50, *user.Properties["name"].MaxLength)
+	assert.Equal(t, "email", user.Properties["email"].Format)
+	assert.Equal(t, []any{"admin", "user"}, user.Properties["role"].Enum)
+	assert.Equal(t, float64(18), *user.Properties["age"].Minimum)
+	assert.Equal(t, float64(150), *user.Properties["age"].ExclusiveMaximum)
+	assert.Equal(t, "array", user.Properties["tags"].Type)
+	assert.Equal(t, 5, *user.Properties["tags"].MaxItems)
+	assert.Equal(t, "#/components/schemas/TestAddress", user.Properties["address"].Ref)
+	assert.Equal(t, "#/components/schemas/TestUser", user.Properties["friends"].Items.Ref)
+	assert.Equal(t, "date-time", user.Properties["createdAt"].Format)
+
+	address := document.Components.Schemas["TestAddress"]
+	assert.Equal(t, []string{"city"}, address.Required)
+	assert.Equal(t, 2, *address.Properties["country"].MinLength)
+	assert.Equal(t, 2, *address.Properties["country"].MaxLength)
+
+	// cached specification
+	first, err := spec.OpenApiSpec()
+	assert.NoError(t, err)
+
+	second, err := spec.OpenApiSpec()
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestModuleWithOpenApiNotExposed(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, fxhttpserver.DefaultOpenApiPath, nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestOpenApiGeneratorWithInvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	registry := fxhttpserver.NewFxHttpServerRegistry(fxhttpserver.FxHttpServerRegistryParam{
+		Handlers: []fxhttpserver.Handler{&invalidOpenApiHandler{}},
+		HandlerDefinitions: []fxhttpserver.HandlerDefinition{
+			fxhttpserver.NewHandlerDefinition("GET", "/invalid", fxhttpserver.GetType(&invalidOpenApiHandler{}), nil),
+		},
+	})
+
+	_, err := fxhttpserver.NewOpenApiGenerator(registry, fxhttpserver.OpenApiInfo{}, "").Generate()
+	assert.Error(t, err)
+	assert.Equal(t, "cannot build openapi operation for [GET] /invalid: request type string is not a struct", err.Error())
+}
+
+type invalidOpenApiHandler struct{}
+
+func (h *invalidOpenApiHandler) OpenApi() *fxhttpserver.OpenApiOperation {
+	return &fxhttpserver.OpenApiOperation{Request: "invalid"}
+}
+
+func (h *invalidOpenApiHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return nil
+	}
+}
@@ -0,0 +1,237 @@
+package fxhttpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	OpenApiValidationMetricsViolationsCount = "http_server_openapi_violations_total"
+	OpenApiValidationKindRequest            = "request"
+	OpenApiValidationKindResponse           = "response"
+)
+
+// NewOpenApiRouter loads the OpenAPI specification file from the provided path, and returns a [routers.Router] for it.
+// The specification servers are ignored, to match the requests on their paths only.
+func NewOpenApiRouter(specPath string) (routers.Router, error) {
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromFile(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load openapi specification %s: %w", specPath, err)
+	}
+
+	err = doc.Validate(loader.Context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid openapi specification %s: %w", specPath, err)
+	}
+
+	doc.Servers = nil
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create openapi router: %w", err)
+	}
+
+	return router, nil
+}
+
+// OpenApiValidationMiddlewareConfig is the configuration for the [OpenApiValidationMiddlewareWithConfig].
+type OpenApiValidationMiddlewareConfig struct {
+	Skipper           middleware.Skipper
+	Router            routers.Router
+	Registry          prometheus.Registerer
+	Namespace         string
+	Subsystem         string
+	ValidateResponses bool
+}
+
+// OpenApiValidationMiddlewareWithConfig returns a middleware validating the requests (and optionally the responses)
+// against an OpenAPI specification, for a provided [OpenApiValidationMiddlewareConfig].
+//
+// Invalid requests get a 400 application/problem+json response, and invalid responses are logged.
+// Requests not matching an operation of the specification are not validated.
+func OpenApiValidationMiddlewareWithConfig(config OpenApiValidationMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	violationsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      OpenApiValidationMetricsViolationsCount,
+			Help:      "Number of HTTP requests and responses OpenAPI validation violations",
+		},
+		[]string{
+			"kind",
+			"method",
+			"path",
+		},
+	)
+
+	violationsCounter = transport.RegisterCollector(config.Registry, violationsCounter)
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			route, pathParams, err := config.Router.FindRoute(req)
+			if err != nil {
+				return next(c)
+			}
+
+			ctx := req.Context()
+			logger := httpserver.CtxLogger(c)
+
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+
+			err = openapi3filter.ValidateRequest(ctx, requestInput)
+			if err != nil {
+				violations := OpenApiValidationViolations(err)
+
+				violationsCounter.WithLabelValues(OpenApiValidationKindRequest, req.Method, route.Path).Inc()
+
+				logger.Warn().
+					Str("kind", OpenApiValidationKindRequest).
+					Interface("violations", violations).
+					Msg("openapi validation failure")
+
+				return problemResponse(c, http.StatusBadRequest, "request validation failure", violations...)
+			}
+
+			if !config.ValidateResponses {
+				return next(c)
+			}
+
+			recorder := &openApiResponseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+			if err != nil {
+				return err
+			}
+
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 c.Response().Status,
+				Header:                 c.Response().Header(),
+				Options:                options,
+			}
+			responseInput.SetBodyBytes(recorder.body.Bytes())
+
+			err = openapi3filter.ValidateResponse(context.WithoutCancel(ctx), responseInput)
+			if err != nil {
+				violationsCounter.WithLabelValues(OpenApiValidationKindResponse, req.Method, route.Path).Inc()
+
+				logger.Error().
+					Str("kind", OpenApiValidationKindResponse).
+					Interface("violations", OpenApiValidationViolations(err)).
+					Msg("openapi validation failure")
+			}
+
+			return nil
+		}
+	}
+}
+
+// OpenApiValidationViolations converts an OpenAPI validation error into a list of [httpserver.ProblemDetailError].
+func OpenApiValidationViolations(err error) []httpserver.ProblemDetailError {
+	return openApiViolations("", err)
+}
+
+func openApiViolations(field string, err error) []httpserver.ProblemDetailError {
+	// direct assertion, since a MultiError matches any of its errors types with errors.As
+	//nolint:errorlint
+	if multiErr, ok := err.(openapi3.MultiError); ok {
+		var violations []httpserver.ProblemDetailError
+		for _, e := range multiErr {
+			violations = append(violations, openApiViolations(field, e)...)
+		}
+
+		return violations
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		switch {
+		case requestErr.Parameter != nil:
+			field = fmt.Sprintf("%s.%s", requestErr.Parameter.In, requestErr.Parameter.Name)
+		case requestErr.RequestBody != nil:
+			field = "body"
+		}
+
+		if requestErr.Err != nil {
+			return openApiViolations(field, requestErr.Err)
+		}
+
+		return []httpserver.ProblemDetailError{{Field: field, Message: requestErr.Reason}}
+	}
+
+	var responseErr *openapi3filter.ResponseError
+	if errors.As(err, &responseErr) {
+		if responseErr.Err != nil {
+			return openApiViolations("body", responseErr.Err)
+		}
+
+		return []httpserver.ProblemDetailError{{Field: field, Message: responseErr.Reason}}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			field = strings.Trim(fmt.Sprintf("%s.%s", field, strings.Join(pointer, ".")), ".")
+		}
+
+		return []httpserver.ProblemDetailError{{Field: field, Message: schemaErr.Reason}}
+	}
+
+	return []httpserver.ProblemDetailError{{Field: field, Message: err.Error()}}
+}
+
+type openApiResponseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *openApiResponseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *openApiResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,224 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var openApiUserHandler = func(c echo.Context) error {
+	if c.QueryParam("verbose") == "true" {
+		return c.JSON(http.StatusOK, map[string]any{"name": "x"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"name": "john", "age": 30})
+}
+
+//nolint:maintidx
+func TestModuleWithOpenApiValidation(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("OPENAPI_VALIDATE_ENABLED", "true")
+	t.Setenv("OPENAPI_VALIDATE_SPEC", "testdata/openapi/openapi.yaml")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET,PUT", "/users/:id", openApiUserHandler),
+		fxhttpserver.AsHandler("GET", "/other", concreteHandler),
+		fx.Populate(&httpServer, &logBuffer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	// valid request
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// invalid path and query parameters
+	req = httptest.NewRequest(http.MethodGet, "/users/invalid?verbose=invalid", nil)
+	req.Header.Add("x-request-id", testRequestId)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var problem httpserver.ProblemDetails
+	err := json.Unmarshal(rec.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/users/invalid", problem.Instance)
+	assert.Equal(t, testRequestId, problem.RequestId)
+	assert.Len(t, problem.Errors, 2)
+	assert.Equal(t, "path.id", problem.Errors[0].Field)
+	assert.Equal(t, "query.verbose", problem.Errors[1].Field)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "warn",
+		"requestID": testRequestId,
+		"kind":      "request",
+		"message":   "openapi validation failure",
+	})
+
+	// invalid body
+	req = httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name":"j","age":12}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	problem = httpserver.ProblemDetails{}
+	err = json.Unmarshal(rec.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+
+	assert.Len(t, problem.Errors, 2)
+
+	fields := []string{problem.Errors[0].Field, problem.Errors[1].Field}
+	assert.Contains(t, fields, "body.name")
+	assert.Contains(t, fields, "body.age")
+
+	// valid body, forwarded to the handler
+	req = httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name":"john","age":30}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// invalid response
+	req = httptest.NewRequest(http.MethodGet, "/users/1?verbose=true", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"x"`)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"kind":    "response",
+		"message": "openapi validation failure",
+	})
+
+	// excluded and not specified routes
+	req = httptest.NewRequest(http.MethodGet, "/users/excluded", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/other", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// metrics
+	expectedMetric := `
+		# HELP http_server_openapi_violations_total Number of HTTP requests and responses OpenAPI validation violations
+		# TYPE http_server_openapi_violations_total counter
+		http_server_openapi_violations_total{kind="request",method="GET",path="/users/{id}"} 1
+		http_server_openapi_violations_total{kind="request",method="PUT",path="/users/{id}"} 1
+		http_server_openapi_violations_total{kind="response",method="GET",path="/users/{id}"} 1
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_openapi_violations_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithOpenApiValidationWithInvalidSpec(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("OPENAPI_VALIDATE_ENABLED", "true")
+	t.Setenv("OPENAPI_VALIDATE_SPEC", "testdata/openapi/invalid.yaml")
+
+	var httpServer *echo.Echo
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot load openapi specification testdata/openapi/invalid.yaml")
+}
+
+func TestOpenApiValidationMiddlewareWithSharedRegistry(t *testing.T) {
+	t.Parallel()
+
+	router, err := fxhttpserver.NewOpenApiRouter("testdata/openapi/openapi.yaml")
+	assert.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+
+	httpServer := echo.New()
+	httpServer.GET("/users/:id", openApiUserHandler)
+
+	// several middleware instances share the violations counter
+	for range 2 {
+		httpServer.Use(fxhttpserver.OpenApiValidationMiddlewareWithConfig(fxhttpserver.OpenApiValidationMiddlewareConfig{
+			Router:   router,
+			Registry: registry,
+		}))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/invalid", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	expectedMetric := `
+		# HELP http_server_openapi_violations_total Number of HTTP requests and responses OpenAPI validation violations
+		# TYPE http_server_openapi_violations_total counter
+		http_server_openapi_violations_total{kind="request",method="GET",path="/users/{id}"} 1
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"http_server_openapi_violations_total",
+	)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,50 @@
+package fxhttpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ErrorFormatJson    = "json"
+	ErrorFormatProblem = "problem"
+)
+
+// newErrorHandler returns the default error handler, for the modules.http.server.errors configuration.
+func newErrorHandler(p FxHttpServerParam, appDebug bool) (ErrorHandler, error) {
+	obfuscate := p.Config.GetBool("modules.http.server.errors.obfuscate") || !appDebug
+	stack := p.Config.GetBool("modules.http.server.errors.stack") || appDebug
+
+	switch format := p.Config.GetString("modules.http.server.errors.format"); format {
+	case "", ErrorFormatJson:
+		return httpserver.NewJsonErrorHandler(obfuscate, stack), nil
+	case ErrorFormatProblem:
+		return httpserver.NewProblemDetailsErrorHandler(p.ErrorMapper, obfuscate, stack), nil
+	default:
+		return nil, fmt.Errorf("invalid http server errors format %s", format)
+	}
+}
+
+// problemResponse sends a problem details response for a provided status, detail and optional field errors.
+func problemResponse(c echo.Context, status int, detail string, errs ...httpserver.ProblemDetailError) error {
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(status)
+	}
+
+	//nolint:errchkjson
+	problem, _ := json.Marshal(httpserver.ProblemDetails{
+		Type:      httpserver.DefaultProblemType,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request().URL.Path,
+		RequestId: httpserver.CtxRequestId(c),
+		Errors:    errs,
+	})
+
+	return c.Blob(status, httpserver.MIMEApplicationProblemJSON, problem)
+}
@@ -0,0 +1,89 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var errProblemNotFound = errors.New("resource not found")
+
+func TestModuleWithProblemDetailsErrorHandler(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("SERVER_ERRORS_FORMAT", fxhttpserver.ErrorFormatProblem)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(
+			httpserver.NewErrorMapper().Map(errProblemNotFound, httpserver.ErrorMapping{
+				Type:   "https://example.com/problems/not-found",
+				Status: http.StatusNotFound,
+			}),
+		),
+		fxhttpserver.AsHandler("GET", "/problem", func(c echo.Context) error {
+			return errProblemNotFound
+		}),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/problem", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var problem httpserver.ProblemDetails
+	err := json.Unmarshal(rec.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/problems/not-found", problem.Type)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "resource not found", problem.Detail)
+	assert.Equal(t, "/problem", problem.Instance)
+}
+
+func TestModuleWithInvalidErrorsFormat(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SERVER_ERRORS_FORMAT", "invalid")
+
+	var httpServer *echo.Echo
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid http server errors format invalid")
+}
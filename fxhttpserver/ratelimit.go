@@ -0,0 +1,449 @@
+package fxhttpserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+const (
+	RateLimitAlgorithmTokenBucket   = "token_bucket"
+	RateLimitAlgorithmSlidingWindow = "sliding_window"
+	RateLimitKeyIp                  = "ip"
+	RateLimitKeySubject             = "subject"
+	RateLimitKeyHeaderPrefix        = "header:"
+	DefaultRateLimitPeriod          = 60
+	RateLimitMetricsDecisionsCount  = "http_server_rate_limit_decisions_total"
+	RateLimitDecisionAllowed        = "allowed"
+	RateLimitDecisionLimited        = "limited"
+	HeaderRateLimitLimit            = "RateLimit-Limit"
+	HeaderRateLimitRemaining        = "RateLimit-Remaining"
+	HeaderRateLimitReset            = "RateLimit-Reset"
+	HeaderRateLimitPolicy           = "RateLimit-Policy"
+	CtxSubjectKey                   = "fxhttpserver.subject"
+)
+
+// RateLimitPolicy is a rate limiting policy: Limit requests per Period, using the Algorithm.
+type RateLimitPolicy struct {
+	Algorithm string
+	Limit     int
+	Period    time.Duration
+}
+
+// Validate returns an error if the policy is invalid.
+func (p RateLimitPolicy) Validate() error {
+	if p.Algorithm != RateLimitAlgorithmTokenBucket && p.Algorithm != RateLimitAlgorithmSlidingWindow {
+		return fmt.Errorf("invalid rate limit algorithm %q", p.Algorithm)
+	}
+
+	if p.Limit <= 0 {
+		return fmt.Errorf("invalid rate limit %d, must be positive", p.Limit)
+	}
+
+	if p.Period <= 0 {
+		return fmt.Errorf("invalid rate limit period %s, must be positive", p.Period)
+	}
+
+	return nil
+}
+
+// RateLimitResult is the result of a rate limiting decision.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore is the interface for rate limiting states stores.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimitKeyExtractor extracts the rate limiting key of a request.
+type RateLimitKeyExtractor func(c echo.Context) string
+
+// RateLimitKeyByIp returns a [RateLimitKeyExtractor] using the request client IP.
+func RateLimitKeyByIp() RateLimitKeyExtractor {
+	return func(c echo.Context) string {
+		return c.RealIP()
+	}
+}
+
+// RateLimitKeyByHeader returns a [RateLimitKeyExtractor] using a request header value.
+func RateLimitKeyByHeader(name string) RateLimitKeyExtractor {
+	return func(c echo.Context) string {
+		return c.Request().Header.Get(name)
+	}
+}
+
+// RateLimitKeyBySubject returns a [RateLimitKeyExtractor] using the authenticated subject, stored in the context
+// under [CtxSubjectKey] by authentication middlewares.
+func RateLimitKeyBySubject() RateLimitKeyExtractor {
+	return func(c echo.Context) string {
+		if subject, ok := c.Get(CtxSubjectKey).(string); ok {
+			return subject
+		}
+
+		return ""
+	}
+}
+
+// ResolveRateLimitKeyExtractor resolves a [RateLimitKeyExtractor] from its name: ip (default), subject or header:<name>.
+func ResolveRateLimitKeyExtractor(name string) (RateLimitKeyExtractor, error) {
+	switch {
+	case name == "" || name == RateLimitKeyIp:
+		return RateLimitKeyByIp(), nil
+	case name == RateLimitKeySubject:
+		return RateLimitKeyBySubject(), nil
+	case strings.HasPrefix(name, RateLimitKeyHeaderPrefix) && len(name) > len(RateLimitKeyHeaderPrefix):
+		return RateLimitKeyByHeader(strings.TrimPrefix(name, RateLimitKeyHeaderPrefix)), nil
+	default:
+		return nil, fmt.Errorf("invalid rate limit key %q", name)
+	}
+}
+
+// RateLimitRule is a rate limiting rule, applying a policy to the requests matching a method and a route path.
+// Empty method or path match any request. Authenticated rules, keyed on the authenticated principal (like with
+// [RateLimitKeyBySubject]), are applied by a middleware running after the routes authentication.
+type RateLimitRule struct {
+	Method        string
+	Path          string
+	Policy        RateLimitPolicy
+	KeyExtractor  RateLimitKeyExtractor
+	Authenticated bool
+}
+
+func (r RateLimitRule) match(c echo.Context) bool {
+	return (r.Method == "" || strings.EqualFold(r.Method, c.Request().Method)) && (r.Path == "" || r.Path == c.Path())
+}
+
+func (r RateLimitRule) key(c echo.Context) string {
+	key := ""
+	if r.KeyExtractor != nil {
+		key = r.KeyExtractor(c)
+	}
+
+	if key == "" {
+		key = c.RealIP()
+	}
+
+	method := r.Method
+	if method == "" {
+		method = "*"
+	}
+
+	path := r.Path
+	if path == "" {
+		path = "*"
+	}
+
+	return fmt.Sprintf("%s:%s:%s", strings.ToUpper(method), path, key)
+}
+
+// RateLimitMiddlewareConfig is the configuration for the [RateLimitMiddlewareWithConfig].
+type RateLimitMiddlewareConfig struct {
+	Skipper   middleware.Skipper
+	Store     RateLimitStore
+	Rules     []RateLimitRule
+	Registry  prometheus.Registerer
+	Namespace string
+	Subsystem string
+	// Authenticated is true for a middleware running after the routes authentication, applying the authenticated
+	// rules only. Otherwise, only the non authenticated rules are applied.
+	Authenticated bool
+}
+
+// RateLimitMiddlewareWithConfig returns a middleware limiting the requests rate, for a provided [RateLimitMiddlewareConfig].
+//
+// The first rule matching the request is applied, requests matching no rule, or a rule applied by the other
+// authentication stage middleware (see [RateLimitMiddlewareConfig] Authenticated), are not limited. Responses carry the
+// RateLimit-* headers, and limited requests get a 429 application/problem+json response with a Retry-After header.
+// Store failures are logged, and do not limit the requests.
+func RateLimitMiddlewareWithConfig(config RateLimitMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	decisionsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      RateLimitMetricsDecisionsCount,
+			Help:      "Number of HTTP requests rate limiting decisions",
+		},
+		[]string{
+			"decision",
+			"method",
+			"path",
+		},
+	)
+
+	// allows several middleware instances (for example per handler) to share the counter
+	decisionsCounter = transport.RegisterCollector(config.Registry, decisionsCounter)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			var rule *RateLimitRule
+			for i := range config.Rules {
+				if config.Rules[i].match(c) {
+					rule = &config.Rules[i]
+
+					break
+				}
+			}
+
+			if rule == nil || rule.Authenticated != config.Authenticated {
+				return next(c)
+			}
+
+			req := c.Request()
+			logger := log.CtxLogger(req.Context())
+
+			result, err := config.Store.Take(req.Context(), rule.key(c), rule.Policy)
+			if err != nil {
+				logger.Error().Err(err).Msg("rate limit store failure")
+
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", rule.Policy.Limit, ceilSeconds(rule.Policy.Period)))
+
+			if result.Allowed {
+				decisionsCounter.WithLabelValues(RateLimitDecisionAllowed, req.Method, c.Path()).Inc()
+
+				return next(c)
+			}
+
+			decisionsCounter.WithLabelValues(RateLimitDecisionLimited, req.Method, c.Path()).Inc()
+
+			logger.Warn().
+				Str("algorithm", rule.Policy.Algorithm).
+				Int("limit", rule.Policy.Limit).
+				Str("period", rule.Policy.Period.String()).
+				Msg("rate limit exceeded")
+
+			header.Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+
+			return problemResponse(
+				c,
+				http.StatusTooManyRequests,
+				fmt.Sprintf("rate limit of %d requests per %s exceeded", rule.Policy.Limit, rule.Policy.Period),
+			)
+		}
+	}
+}
+
+// tokenBucketResult computes the result of a token bucket decision, from the remaining tokens.
+func tokenBucketResult(policy RateLimitPolicy, allowed bool, tokens float64) RateLimitResult {
+	rate := float64(policy.Limit) / float64(policy.Period)
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Limit) - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+// slidingWindowResult computes the result of a sliding window decision, from the previous and current windows counts
+// and the elapsed duration of the current window.
+func slidingWindowResult(policy RateLimitPolicy, allowed bool, previous int, current int, elapsed time.Duration) RateLimitResult {
+	weight := 1 - float64(elapsed)/float64(policy.Period)
+	estimated := float64(previous)*weight + float64(current)
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: max(0, int(math.Floor(float64(policy.Limit)-estimated))),
+		Reset:     policy.Period - elapsed,
+	}
+
+	if !allowed {
+		// time for the previous window weight to free one slot, or end of the current window
+		result.RetryAfter = policy.Period - elapsed
+		if previous > 0 && current < policy.Limit {
+			targetWeight := float64(policy.Limit-1-current) / float64(previous)
+			result.RetryAfter = time.Duration((weight - targetWeight) * float64(policy.Period))
+		}
+	}
+
+	return result
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type rateLimitRouteConfig struct {
+	Method    string `mapstructure:"method"`
+	Path      string `mapstructure:"path"`
+	Algorithm string `mapstructure:"algorithm"`
+	Limit     int    `mapstructure:"limit"`
+	Period    int    `mapstructure:"period"`
+	Key       string `mapstructure:"key"`
+}
+
+// withRateLimitMiddleware registers the rate limit middleware from the modules.http.server.ratelimit configuration.
+//
+// The rules keyed by subject need the authenticated principal, set by the routes authentication middlewares: they are
+// applied by the returned middleware, to attach on the routes after their own middlewares (nil if there is none).
+func withRateLimitMiddleware(httpServer *echo.Echo, p FxHttpServerParam, registerer prometheus.Registerer) (*echo.Echo, echo.MiddlewareFunc, error) {
+	defaults := rateLimitRouteConfig{
+		Algorithm: p.Config.GetString("modules.http.server.ratelimit.algorithm"),
+		Limit:     p.Config.GetInt("modules.http.server.ratelimit.limit"),
+		Period:    p.Config.GetInt("modules.http.server.ratelimit.period"),
+		Key:       p.Config.GetString("modules.http.server.ratelimit.key"),
+	}
+
+	if defaults.Algorithm == "" {
+		defaults.Algorithm = RateLimitAlgorithmTokenBucket
+	}
+
+	if defaults.Period == 0 {
+		defaults.Period = DefaultRateLimitPeriod
+	}
+
+	var routes []rateLimitRouteConfig
+	if err := p.Config.UnmarshalKey("modules.http.server.ratelimit.routes", &routes); err != nil {
+		return httpServer, nil, fmt.Errorf("invalid rate limit routes: %w", err)
+	}
+
+	// the default rule, applied to any route, comes last
+	if defaults.Limit > 0 {
+		routes = append(routes, rateLimitRouteConfig{Limit: defaults.Limit})
+	}
+
+	authenticated := false
+
+	rules := make([]RateLimitRule, 0, len(routes))
+	for _, route := range routes {
+		rule, err := newRateLimitRule(route, defaults)
+		if err != nil {
+			return httpServer, nil, err
+		}
+
+		authenticated = authenticated || rule.Authenticated
+
+		rules = append(rules, rule)
+	}
+
+	var store RateLimitStore
+	switch storeType := p.Config.GetString("modules.http.server.ratelimit.store"); storeType {
+	case "", "memory":
+		store = NewMemoryRateLimitStore()
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     p.Config.GetString("modules.http.server.ratelimit.redis.address"),
+			Username: p.Config.GetString("modules.http.server.ratelimit.redis.username"),
+			Password: p.Config.GetString("modules.http.server.ratelimit.redis.password"),
+			DB:       p.Config.GetInt("modules.http.server.ratelimit.redis.db"),
+		})
+
+		p.LifeCycle.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return client.Close()
+			},
+		})
+
+		store = NewRedisRateLimitStore(client, p.Config.GetString("modules.http.server.ratelimit.redis.prefix"))
+	default:
+		return httpServer, nil, fmt.Errorf("invalid rate limit store %q", storeType)
+	}
+
+	excludes := p.Config.GetStringSlice("modules.http.server.ratelimit.exclude")
+
+	config := RateLimitMiddlewareConfig{
+		Skipper: func(c echo.Context) bool {
+			return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+		},
+		Store:     store,
+		Rules:     rules,
+		Registry:  registerer,
+		Namespace: Sanitize(p.Config.GetString("modules.http.server.metrics.collect.namespace")),
+		Subsystem: Sanitize(p.Config.GetString("modules.http.server.metrics.collect.subsystem")),
+	}
+
+	httpServer.Use(RateLimitMiddlewareWithConfig(config))
+
+	if !authenticated {
+		return httpServer, nil, nil
+	}
+
+	config.Authenticated = true
+
+	return httpServer, RateLimitMiddlewareWithConfig(config), nil
+}
+
+func newRateLimitRule(route rateLimitRouteConfig, defaults rateLimitRouteConfig) (RateLimitRule, error) {
+	if route.Algorithm == "" {
+		route.Algorithm = defaults.Algorithm
+	}
+
+	if route.Period == 0 {
+		route.Period = defaults.Period
+	}
+
+	if route.Key == "" {
+		route.Key = defaults.Key
+	}
+
+	keyExtractor, err := ResolveRateLimitKeyExtractor(route.Key)
+	if err != nil {
+		return RateLimitRule{}, err
+	}
+
+	policy := RateLimitPolicy{
+		Algorithm: route.Algorithm,
+		Limit:     route.Limit,
+		Period:    time.Duration(route.Period) * time.Second,
+	}
+
+	if err = policy.Validate(); err != nil {
+		return RateLimitRule{}, err
+	}
+
+	return RateLimitRule{
+		Method:        route.Method,
+		Path:          route.Path,
+		Policy:        policy,
+		KeyExtractor:  keyExtractor,
+		Authenticated: route.Key == RateLimitKeySubject,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package fxhttpserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultRateLimitRedisPrefix = "ratelimit"
+	rateLimitSweepInterval      = time.Minute
+)
+
+type memoryRateLimitEntry struct {
+	tokens    float64
+	timestamp time.Time
+	window    int64
+	previous  int
+	current   int
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore is an in-memory [RateLimitStore], for single instance deployments.
+type MemoryRateLimitStore struct {
+	mutex     sync.Mutex
+	entries   map[string]*memoryRateLimitEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore returns a new [MemoryRateLimitStore] instance.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries:   make(map[string]*memoryRateLimitEntry),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take consumes one request for a key, according to the provided [RateLimitPolicy].
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	if err := policy.Validate(); err != nil {
+		return RateLimitResult{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+
+	s.sweep(now)
+
+	key = fmt.Sprintf("%s:%s", policy.Algorithm, key)
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		entry = &memoryRateLimitEntry{
+			tokens:    float64(policy.Limit),
+			timestamp: now,
+		}
+
+		s.entries[key] = entry
+	}
+
+	if policy.Algorithm == RateLimitAlgorithmTokenBucket {
+		rate := float64(policy.Limit) / float64(policy.Period)
+
+		entry.tokens = min(float64(policy.Limit), entry.tokens+float64(now.Sub(entry.timestamp))*rate)
+		entry.timestamp = now
+		entry.expiresAt = now.Add(policy.Period)
+
+		allowed := entry.tokens >= 1
+		if allowed {
+			entry.tokens--
+		}
+
+		return tokenBucketResult(policy, allowed, entry.tokens), nil
+	}
+
+	window := now.UnixNano() / int64(policy.Period)
+	elapsed := time.Duration(now.UnixNano() % int64(policy.Period))
+
+	switch entry.window {
+	case window:
+	case window - 1:
+		entry.previous, entry.current = entry.current, 0
+	default:
+		entry.previous, entry.current = 0, 0
+	}
+
+	entry.window = window
+	entry.expiresAt = now.Add(2 * policy.Period)
+
+	weight := 1 - float64(elapsed)/float64(policy.Period)
+	allowed := float64(entry.previous)*weight+float64(entry.current)+1 <= float64(policy.Limit)
+	if allowed {
+		entry.current++
+	}
+
+	return slidingWindowResult(policy, allowed, entry.previous, entry.current, elapsed), nil
+}
+
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastSweep = now
+}
+
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'timestamp')
+local tokens = tonumber(state[1]) or limit
+local timestamp = tonumber(state[2]) or now
+tokens = math.min(limit, tokens + math.max(0, now - timestamp) * limit / period)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'timestamp', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local weight = tonumber(ARGV[3])
+local current = tonumber(redis.call('GET', KEYS[1])) or 0
+local previous = tonumber(redis.call('GET', KEYS[2])) or 0
+local allowed = 0
+if previous * weight + current + 1 <= limit then
+	current = redis.call('INCR', KEYS[1])
+	redis.call('PEXPIRE', KEYS[1], 2 * period)
+	allowed = 1
+end
+return {allowed, previous, current}
+`)
+
+// RedisRateLimitStore is a Redis based [RateLimitStore], to share the rate limiting states between instances.
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisRateLimitStore returns a new [RedisRateLimitStore] instance, storing its keys under the provided prefix.
+func NewRedisRateLimitStore(client redis.Scripter, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = DefaultRateLimitRedisPrefix
+	}
+
+	return &RedisRateLimitStore{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Take consumes one request for a key, according to the provided [RateLimitPolicy].
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	if err := policy.Validate(); err != nil {
+		return RateLimitResult{}, err
+	}
+
+	now := s.now()
+
+	// hash tag, for the sliding window keys to map to the same Redis Cluster slot
+	key = fmt.Sprintf("{%s:%s:%s}", s.prefix, policy.Algorithm, key)
+
+	if policy.Algorithm == RateLimitAlgorithmTokenBucket {
+		res, err := tokenBucketScript.Run(
+			ctx,
+			s.client,
+			[]string{key},
+			policy.Limit,
+			policy.Period.Milliseconds(),
+			now.UnixMilli(),
+		).Slice()
+		if err != nil {
+			return RateLimitResult{}, fmt.Errorf("failed to run redis rate limit script: %w", err)
+		}
+
+		allowed, _ := res[0].(int64)
+		tokensStr, _ := res[1].(string)
+
+		tokens, err := strconv.ParseFloat(tokensStr, 64)
+		if err != nil {
+			return RateLimitResult{}, fmt.Errorf("invalid redis rate limit tokens %q: %w", tokensStr, err)
+		}
+
+		return tokenBucketResult(policy, allowed == 1, tokens), nil
+	}
+
+	window := now.UnixNano() / int64(policy.Period)
+	elapsed := time.Duration(now.UnixNano() % int64(policy.Period))
+
+	res, err := slidingWindowScript.Run(
+		ctx,
+		s.client,
+		[]string{
+			fmt.Sprintf("%s:%d", key, window),
+			fmt.Sprintf("%s:%d", key, window-1),
+		},
+		policy.Limit,
+		policy.Period.Milliseconds(),
+		strconv.FormatFloat(1-float64(elapsed)/float64(policy.Period), 'f', -1, 64),
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to run redis rate limit script: %w", err)
+	}
+
+	return slidingWindowResult(policy, res[0] == 1, int(res[1]), int(res[2]), elapsed), nil
+}
@@ -0,0 +1,207 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func rateLimitStores(t *testing.T) map[string]fxhttpserver.RateLimitStore {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	return map[string]fxhttpserver.RateLimitStore{
+		"memory": fxhttpserver.NewMemoryRateLimitStore(),
+		"redis":  fxhttpserver.NewRedisRateLimitStore(client, "test"),
+	}
+}
+
+func TestRateLimitStoreTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			policy := fxhttpserver.RateLimitPolicy{
+				Algorithm: fxhttpserver.RateLimitAlgorithmTokenBucket,
+				Limit:     2,
+				Period:    200 * time.Millisecond,
+			}
+
+			res, err := store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 2, res.Limit)
+			assert.Equal(t, 1, res.Remaining)
+
+			res, err = store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+
+			res, err = store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, 100*time.Millisecond)
+
+			// other keys are not impacted
+			res, err = store.Take(ctx, "other", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+
+			// tokens refill over time
+			time.Sleep(150 * time.Millisecond)
+
+			res, err = store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func TestRateLimitStoreSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			policy := fxhttpserver.RateLimitPolicy{
+				Algorithm: fxhttpserver.RateLimitAlgorithmSlidingWindow,
+				Limit:     2,
+				Period:    time.Hour,
+			}
+
+			res, err := store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 2, res.Limit)
+
+			res, err = store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+
+			res, err = store.Take(ctx, "key", policy)
+			assert.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, time.Hour)
+		})
+	}
+}
+
+// keysRecordingScripter is a [redis.Scripter] recording the keys of the scripts runs.
+type keysRecordingScripter struct {
+	redis.Scripter
+	mutex sync.Mutex
+	keys  []string
+}
+
+func (s *keysRecordingScripter) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	s.record(keys)
+
+	return s.Scripter.Eval(ctx, script, keys, args...)
+}
+
+func (s *keysRecordingScripter) EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	s.record(keys)
+
+	return s.Scripter.EvalSha(ctx, sha1, keys, args...)
+}
+
+func (s *keysRecordingScripter) record(keys []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys = append(s.keys, keys...)
+}
+
+// redisClusterSlot returns the Redis Cluster slot of a key (CRC16 of its hash tag, if any, modulo 16384).
+func redisClusterSlot(key string) uint16 {
+	if start := strings.Index(key, "{"); start >= 0 {
+		if end := strings.Index(key[start+1:], "}"); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc % 16384
+}
+
+func TestRedisClusterSlot(t *testing.T) {
+	t.Parallel()
+
+	// reference values from the Redis Cluster specification and CLUSTER KEYSLOT
+	assert.Equal(t, uint16(12739), redisClusterSlot("123456789"))
+	assert.Equal(t, redisClusterSlot("user1000"), redisClusterSlot("{user1000}.following"))
+}
+
+func TestRedisRateLimitStoreSlidingWindowKeysSlot(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	scripter := &keysRecordingScripter{Scripter: client}
+	store := fxhttpserver.NewRedisRateLimitStore(scripter, "test")
+
+	_, err := store.Take(context.Background(), "key", fxhttpserver.RateLimitPolicy{
+		Algorithm: fxhttpserver.RateLimitAlgorithmSlidingWindow,
+		Limit:     2,
+		Period:    time.Hour,
+	})
+	assert.NoError(t, err)
+
+	// the current and previous windows keys must be in the same slot, for the script to run on Redis Cluster
+	assert.GreaterOrEqual(t, len(scripter.keys), 2)
+	assert.NotEqual(t, scripter.keys[0], scripter.keys[1])
+
+	for _, key := range scripter.keys {
+		assert.True(t, strings.HasPrefix(key, "{test:sliding_window:key}:"), key)
+		assert.Equal(t, redisClusterSlot(scripter.keys[0]), redisClusterSlot(key))
+	}
+}
+
+func TestRateLimitStoreInvalidPolicy(t *testing.T) {
+	t.Parallel()
+
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := store.Take(context.Background(), "key", fxhttpserver.RateLimitPolicy{Algorithm: "invalid"})
+			assert.Error(t, err)
+			assert.Equal(t, `invalid rate limit algorithm "invalid"`, err.Error())
+		})
+	}
+}
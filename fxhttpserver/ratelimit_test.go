@@ -0,0 +1,326 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var rateLimitHandler = func(c echo.Context) error {
+	return c.String(http.StatusOK, "ok")
+}
+
+func TestModuleWithRateLimit(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RATELIMIT_ENABLED", "true")
+	t.Setenv("RATELIMIT_LIMIT", "2")
+
+	for _, store := range []string{"memory", "redis"} {
+		t.Run(store, func(t *testing.T) {
+			t.Setenv("RATELIMIT_STORE", store)
+
+			if store == "redis" {
+				t.Setenv("RATELIMIT_REDIS_ADDRESS", miniredis.RunT(t).Addr())
+			}
+
+			var httpServer *echo.Echo
+			var logBuffer logtest.TestLogBuffer
+			var metricsRegistry *prometheus.Registry
+
+			app := fxtest.New(
+				t,
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fxhttpserver.AsHandler("GET", "/ratelimit/default", rateLimitHandler),
+				fxhttpserver.AsHandler("POST", "/ratelimit/route", rateLimitHandler),
+				fxhttpserver.AsHandler("GET", "/ratelimit/excluded", rateLimitHandler),
+				fx.Populate(&httpServer, &logBuffer, &metricsRegistry),
+			).RequireStart()
+
+			// the redis store client is closed on stop
+			defer app.RequireStop()
+
+			// default rule: 2 requests per 60 seconds
+			for i := 1; i <= 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/ratelimit/default", nil)
+				rec := httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, "2", rec.Header().Get(fxhttpserver.HeaderRateLimitLimit))
+				assert.Equal(t, "2;w=60", rec.Header().Get(fxhttpserver.HeaderRateLimitPolicy))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/ratelimit/default", nil)
+			req.Header.Add("x-request-id", testRequestId)
+			rec := httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Equal(t, "0", rec.Header().Get(fxhttpserver.HeaderRateLimitRemaining))
+			assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+			assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+			var problem map[string]any
+			err := json.Unmarshal(rec.Body.Bytes(), &problem)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "Too Many Requests", problem["title"])
+			assert.Equal(t, float64(http.StatusTooManyRequests), problem["status"])
+			assert.Equal(t, "/ratelimit/default", problem["instance"])
+			assert.Equal(t, testRequestId, problem["requestId"])
+
+			logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+				"level":     "warn",
+				"requestID": testRequestId,
+				"algorithm": "token_bucket",
+				"message":   "rate limit exceeded",
+			})
+
+			// route rule: 1 request per 60 seconds, per api key
+			for _, apiKey := range []string{"key-a", "key-b"} {
+				req = httptest.NewRequest(http.MethodPost, "/ratelimit/route", nil)
+				req.Header.Set("x-api-key", apiKey)
+				rec = httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, "1;w=60", rec.Header().Get(fxhttpserver.HeaderRateLimitPolicy))
+			}
+
+			req = httptest.NewRequest(http.MethodPost, "/ratelimit/route", nil)
+			req.Header.Set("x-api-key", "key-a")
+			rec = httptest.NewRecorder()
+			httpServer.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+			// excluded
+			for i := 1; i <= 3; i++ {
+				req = httptest.NewRequest(http.MethodGet, "/ratelimit/excluded", nil)
+				rec = httptest.NewRecorder()
+				httpServer.ServeHTTP(rec, req)
+
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Empty(t, rec.Header().Get(fxhttpserver.HeaderRateLimitLimit))
+			}
+
+			// metrics
+			expectedMetric := `
+				# HELP http_server_rate_limit_decisions_total Number of HTTP requests rate limiting decisions
+				# TYPE http_server_rate_limit_decisions_total counter
+				http_server_rate_limit_decisions_total{decision="allowed",method="GET",path="/ratelimit/default"} 2
+				http_server_rate_limit_decisions_total{decision="allowed",method="POST",path="/ratelimit/route"} 2
+				http_server_rate_limit_decisions_total{decision="limited",method="GET",path="/ratelimit/default"} 1
+				http_server_rate_limit_decisions_total{decision="limited",method="POST",path="/ratelimit/route"} 1
+			`
+
+			err = testutil.GatherAndCompare(
+				metricsRegistry,
+				strings.NewReader(expectedMetric),
+				"http_server_rate_limit_decisions_total",
+			)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestModuleWithRateLimitBySubject(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RATELIMIT_ENABLED", "true")
+	t.Setenv("RATELIMIT_LIMIT", "1")
+	t.Setenv("RATELIMIT_KEY", "subject")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/ratelimit/subject", rateLimitHandler, fxhttpserver.NewFxBasicAuthMiddleware),
+		fxhttpserver.AsHandlersGroup(
+			"/ratelimit/group",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration("GET", "/subject", rateLimitHandler),
+			},
+			fxhttpserver.NewFxBasicAuthMiddleware,
+		),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	// the requests come from the same client IP, but are counted per authenticated subject, on any route
+	for _, username := range []string{"admin", "user"} {
+		req := httptest.NewRequest(http.MethodGet, "/ratelimit/subject", nil)
+		req.SetBasicAuth(username, username+"-password")
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, username)
+		assert.Equal(t, "0", rec.Header().Get(fxhttpserver.HeaderRateLimitRemaining), username)
+
+		req = httptest.NewRequest(http.MethodGet, "/ratelimit/group/subject", nil)
+		req.SetBasicAuth(username, username+"-password")
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code, username)
+	}
+
+	// unauthenticated requests are rejected before the rate limiting
+	for _, path := range []string{"/ratelimit/subject", "/ratelimit/group/subject"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+		assert.Empty(t, rec.Header().Get(fxhttpserver.HeaderRateLimitLimit), path)
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":      "warn",
+		"principal":  "admin",
+		"authMethod": "basic",
+		"message":    "rate limit exceeded",
+	})
+}
+
+func TestModuleWithRateLimitStoreFailure(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RATELIMIT_ENABLED", "true")
+	t.Setenv("RATELIMIT_LIMIT", "1")
+	t.Setenv("RATELIMIT_STORE", "redis")
+	t.Setenv("RATELIMIT_REDIS_ADDRESS", "localhost:1")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/ratelimit/default", rateLimitHandler),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	for i := 1; i <= 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/ratelimit/default", nil)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"message": "rate limit store failure",
+	})
+}
+
+func TestModuleWithInvalidRateLimitConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("RATELIMIT_ENABLED", "true")
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"invalid store", map[string]string{"RATELIMIT_STORE": "invalid"}, `invalid rate limit store "invalid"`},
+		{"invalid algorithm", map[string]string{"RATELIMIT_ALGORITHM": "invalid", "RATELIMIT_LIMIT": "1"}, `invalid rate limit algorithm "invalid"`},
+		{"invalid key", map[string]string{"RATELIMIT_KEY": "invalid", "RATELIMIT_LIMIT": "1"}, `invalid rate limit key "invalid"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			err := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Invoke(func(*echo.Echo) {}),
+			).Err()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestResolveRateLimitKeyExtractor(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("x-api-key", "key")
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", "10.0.0.1"},
+		{"ip", "10.0.0.1"},
+		{"subject", ""},
+		{"header:x-api-key", "key"},
+	}
+
+	for _, tt := range tests {
+		extractor, err := fxhttpserver.ResolveRateLimitKeyExtractor(tt.name)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, extractor(c))
+	}
+
+	_, err := fxhttpserver.ResolveRateLimitKeyExtractor("header:")
+	assert.Error(t, err)
+}
+
+func TestRateLimitPolicyValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, fxhttpserver.RateLimitPolicy{Algorithm: "token_bucket", Limit: 1, Period: time.Second}.Validate())
+	assert.Error(t, fxhttpserver.RateLimitPolicy{Algorithm: "invalid", Limit: 1, Period: time.Second}.Validate())
+	assert.Error(t, fxhttpserver.RateLimitPolicy{Algorithm: "sliding_window", Limit: 0, Period: time.Second}.Validate())
+	assert.Error(t, fxhttpserver.RateLimitPolicy{Algorithm: "sliding_window", Limit: 1, Period: 0}.Validate())
+}
@@ -48,7 +48,12 @@ func IsConcreteMiddleware(middleware any) bool {
 	return reflect.TypeOf(middleware).ConvertibleTo(reflect.TypeOf(echo.MiddlewareFunc(nil)))
 }
 
-// IsConcreteHandler returns true if the handler is a concrete [echo.HandlerFunc] implementation.
+// IsConcreteHandler returns true if the handler is a concrete [echo.HandlerFunc] implementation, or a [Handler]
+// instance (like a [TypedHandler]).
 func IsConcreteHandler(handler any) bool {
+	if _, ok := handler.(Handler); ok {
+		return true
+	}
+
 	return reflect.TypeOf(handler).ConvertibleTo(reflect.TypeOf(echo.HandlerFunc(nil)))
 }
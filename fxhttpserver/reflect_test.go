@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,6 +94,8 @@ func TestIsConcreteHandler(t *testing.T) {
 		expected bool
 	}{
 		{echo.HandlerFunc(func(c echo.Context) error { return nil }), true},
+		{fxhttpserver.Typed(handler.TestTypedHandler), true},
+		{handler.NewTestBarHandler, false},
 		{123, false},
 		{"test", false},
 	}
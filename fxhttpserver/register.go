@@ -1,6 +1,10 @@
 package fxhttpserver
 
 import (
+	"io/fs"
+	"net/http"
+	"strings"
+
 	"go.uber.org/fx"
 )
 
@@ -8,6 +12,7 @@ import (
 type MiddlewareRegistration struct {
 	middleware any
 	kind       MiddlewareKind
+	server     string
 }
 
 // NewMiddlewareRegistration returns a new [MiddlewareRegistration].
@@ -28,9 +33,13 @@ func (m *MiddlewareRegistration) Kind() MiddlewareKind {
 	return m.kind
 }
 
-// AsMiddleware registers a middleware into Fx.
-func AsMiddleware(middleware any, kind MiddlewareKind) fx.Option {
-	return RegisterMiddleware(NewMiddlewareRegistration(middleware, kind))
+// AsMiddleware registers a middleware into Fx, on the default http server, or on the named http server of the
+// provided [ServerTarget].
+func AsMiddleware(middleware any, kind MiddlewareKind, targets ...ServerTarget) fx.Option {
+	middlewareRegistration := NewMiddlewareRegistration(middleware, kind)
+	middlewareRegistration.server = lastServer(targets)
+
+	return RegisterMiddleware(middlewareRegistration)
 }
 
 // RegisterMiddleware registers a middleware registration into Fx.
@@ -62,9 +71,43 @@ func RegisterMiddleware(middlewareRegistration *MiddlewareRegistration) fx.Optio
 				fx.ResultTags(`group:"httpserver-middleware-definitions"`),
 			),
 		),
+		supplyServerBinding(middlewareRegistration.server, middlewareDef),
 	)
 }
 
+// RegistrationOption is an option of a handler, handlers group or stream handler registration, declared among its
+// middlewares: an [AuthorizationPolicy] (see [RequireScopes] and [RequireRoles]), a [RouteTimeout] (see [Timeout]),
+// or a [ServerTarget] (see [OnServer]).
+type RegistrationOption interface {
+	applyRegistrationOption(options *registrationOptions)
+}
+
+// registrationOptions are the options of a registration.
+type registrationOptions struct {
+	policies []AuthorizationPolicy
+	timeout  *RouteTimeout
+	server   string
+}
+
+// splitRegistrationOptions splits the [RegistrationOption] declared among registration middlewares.
+func splitRegistrationOptions(middlewares []any) ([]any, registrationOptions) {
+	var filteredMiddlewares []any
+
+	options := registrationOptions{
+		server: DefaultServerName,
+	}
+
+	for _, middleware := range middlewares {
+		if option, ok := middleware.(RegistrationOption); ok {
+			option.applyRegistrationOption(&options)
+		} else {
+			filteredMiddlewares = append(filteredMiddlewares, middleware)
+		}
+	}
+
+	return filteredMiddlewares, options
+}
+
 // HandlerRegistration is a handler registration.
 type HandlerRegistration struct {
 	method      string
@@ -109,11 +152,16 @@ func AsHandler(method string, path string, handler any, middlewares ...any) fx.O
 }
 
 // RegisterHandler registers a handler registration into Fx.
+// The [RegistrationOption] provided among the middlewares apply to the handler: its [AuthorizationPolicy] are
+// evaluated before the handler, its [RouteTimeout] is applied to the handler, and its [ServerTarget] selects the
+// named http server to register the handler on.
 func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 	var providers []any
 
+	middlewares, options := splitRegistrationOptions(handlerRegistration.Middlewares())
+
 	var middlewareDefs []MiddlewareDefinition
-	for _, middleware := range handlerRegistration.Middlewares() {
+	for _, middleware := range middlewares {
 		if !IsConcreteMiddleware(middleware) {
 			providers = append(
 				providers,
@@ -145,6 +193,7 @@ func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 			handlerRegistration.Path(),
 			GetReturnType(handlerRegistration.Handler()),
 			middlewareDefs,
+			options.policies...,
 		)
 	} else {
 		handlerDef = NewHandlerDefinition(
@@ -152,6 +201,7 @@ func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 			handlerRegistration.Path(),
 			handlerRegistration.Handler(),
 			middlewareDefs,
+			options.policies...,
 		)
 	}
 
@@ -164,6 +214,8 @@ func RegisterHandler(handlerRegistration *HandlerRegistration) fx.Option {
 				fx.ResultTags(`group:"httpserver-handler-definitions"`),
 			),
 		),
+		supplyServerBinding(options.server, handlerDef),
+		supplyTimeoutRule(options.server, handlerRegistration.Method(), handlerRegistration.Path(), options.timeout),
 	)
 }
 
@@ -204,11 +256,18 @@ func AsHandlersGroup(prefix string, handlersRegistrations []*HandlerRegistration
 }
 
 // RegisterHandlersGroup registers a handlers group registration into Fx.
+// The [RegistrationOption] provided among the group or handlers middlewares apply to the handlers: their
+// [AuthorizationPolicy] are evaluated before the handlers, the handler (or else the group) [RouteTimeout] is applied
+// to the handlers, and the group [ServerTarget] selects the named http server to register the group on.
 func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration) fx.Option {
 	var providers []any
+	var timeoutRules []fx.Option
+
+	groupMiddlewares, groupOptions := splitRegistrationOptions(handlersGroupRegistration.Middlewares())
+	server := groupOptions.server
 
 	var groupMiddlewareDefs []MiddlewareDefinition
-	for _, middleware := range handlersGroupRegistration.Middlewares() {
+	for _, middleware := range groupMiddlewares {
 		if !IsConcreteMiddleware(middleware) {
 			providers = append(
 				providers,
@@ -230,7 +289,25 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 		var handlerDef HandlerDefinition
 		var middlewareDefs []MiddlewareDefinition
 
-		for _, middleware := range handlerRegistration.Middlewares() {
+		// the handlers are registered on the group server
+		middlewares, options := splitRegistrationOptions(handlerRegistration.Middlewares())
+
+		timeout := options.timeout
+		if timeout == nil {
+			timeout = groupOptions.timeout
+		}
+
+		timeoutRules = append(
+			timeoutRules,
+			supplyTimeoutRule(
+				server,
+				handlerRegistration.Method(),
+				handlersGroupRegistration.Prefix()+handlerRegistration.Path(),
+				timeout,
+			),
+		)
+
+		for _, middleware := range middlewares {
 			if !IsConcreteMiddleware(middleware) {
 				providers = append(
 					providers,
@@ -261,6 +338,7 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 				handlerRegistration.Path(),
 				GetReturnType(handlerRegistration.Handler()),
 				middlewareDefs,
+				options.policies...,
 			)
 		} else {
 			handlerDef = NewHandlerDefinition(
@@ -268,6 +346,7 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 				handlerRegistration.Path(),
 				handlerRegistration.Handler(),
 				middlewareDefs,
+				options.policies...,
 			)
 		}
 
@@ -278,6 +357,7 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 		handlersGroupRegistration.Prefix(),
 		groupHandlerDefs,
 		groupMiddlewareDefs,
+		groupOptions.policies...,
 	)
 
 	return fx.Options(
@@ -289,16 +369,137 @@ func RegisterHandlersGroup(handlersGroupRegistration *HandlersGroupRegistration)
 				fx.ResultTags(`group:"httpserver-handlers-group-definitions"`),
 			),
 		),
+		supplyServerBinding(server, handlersGroupDef),
+		fx.Options(timeoutRules...),
 	)
 }
 
-// AsErrorHandler replaces the default error handler.
-func AsErrorHandler(errorHandler any) fx.Option {
-	return fx.Provide(
+// AsSSEHandler registers a Server-Sent Events [SSEHandler] constructor into Fx, for GET requests on the provided path.
+// The [AuthorizationPolicy] provided among the middlewares are evaluated before the handler, and the [RouteTimeout]
+// are ignored.
+func AsSSEHandler(path string, handler any, middlewares ...any) fx.Option {
+	return registerStreamHandler(path, handler, new(SSEHandler), `group:"httpserver-sse-handlers"`, middlewares)
+}
+
+// AsWebSocketHandler registers a [WebSocketHandler] constructor into Fx, for GET requests on the provided path.
+// The [AuthorizationPolicy] provided among the middlewares are evaluated before the connection upgrade, and the
+// [RouteTimeout] are ignored.
+func AsWebSocketHandler(path string, handler any, middlewares ...any) fx.Option {
+	return registerStreamHandler(path, handler, new(WebSocketHandler), `group:"httpserver-websocket-handlers"`, middlewares)
+}
+
+func registerStreamHandler(path string, handler any, handlerInterface any, handlerGroup string, handlerMiddlewares []any) fx.Option {
+	var providers []any
+
+	// the streams are long-lived, and not subject to timeouts
+	middlewares, options := splitRegistrationOptions(handlerMiddlewares)
+
+	var middlewareDefs []MiddlewareDefinition
+	for _, middleware := range middlewares {
+		if !IsConcreteMiddleware(middleware) {
+			providers = append(
+				providers,
+				fx.Annotate(
+					middleware,
+					fx.As(new(Middleware)),
+					fx.ResultTags(`group:"httpserver-middlewares"`),
+				),
+			)
+
+			middlewareDefs = append(middlewareDefs, NewMiddlewareDefinition(GetReturnType(middleware), Attached))
+		} else {
+			middlewareDefs = append(middlewareDefs, NewMiddlewareDefinition(middleware, Attached))
+		}
+	}
+
+	providers = append(
+		providers,
+		fx.Annotate(
+			handler,
+			fx.As(handlerInterface),
+			fx.ResultTags(handlerGroup),
+		),
+	)
+
+	handlerDef := NewHandlerDefinition(
+		http.MethodGet,
+		path,
+		GetReturnType(handler),
+		middlewareDefs,
+		options.policies...,
+	)
+
+	return fx.Options(
+		fx.Provide(providers...),
+		fx.Supply(
+			fx.Annotate(
+				handlerDef,
+				fx.As(new(HandlerDefinition)),
+				fx.ResultTags(`group:"httpserver-handler-definitions"`),
+			),
+		),
+		supplyServerBinding(options.server, handlerDef),
+	)
+}
+
+// AsStatic registers a handler serving the static assets of a provided [fs.FS] (an embed.FS, or a directory with
+// os.DirFS) into Fx, for GET and HEAD requests under the provided path.
+func AsStatic(path string, fsys fs.FS, middlewares ...any) fx.Option {
+	return AsStaticWithConfig(path, StaticConfig{Filesystem: fsys}, middlewares...)
+}
+
+// AsStaticWithConfig registers a handler serving static assets, for a provided [StaticConfig], into Fx, for GET and
+// HEAD requests under the provided path.
+func AsStaticWithConfig(path string, config StaticConfig, middlewares ...any) fx.Option {
+	handler := StaticHandlerWithConfig(config)
+
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return AsHandler("GET,HEAD", "/*", handler, middlewares...)
+	}
+
+	return fx.Options(
+		AsHandler("GET,HEAD", path, handler, middlewares...),
+		AsHandler("GET,HEAD", path+"/*", handler, middlewares...),
+	)
+}
+
+// AsTemplatesFS registers the filesystem (like an embed.FS) to look up the HTML templates from, instead of the disk.
+func AsTemplatesFS(fsys fs.FS) fx.Option {
+	return fx.Supply(
+		fx.Annotate(
+			fsys,
+			fx.As(new(fs.FS)),
+			fx.ResultTags(`name:"httpserver-templates-fs"`),
+		),
+	)
+}
+
+// AsErrorHandler replaces the default error handler, of the default http server, or of the named http server of the
+// provided [ServerTarget].
+func AsErrorHandler(errorHandler any, targets ...ServerTarget) fx.Option {
+	provideOption := fx.Provide(
 		fx.Annotate(
 			errorHandler,
 			fx.As(new(ErrorHandler)),
 			fx.ResultTags(`group:"httpserver-error-handlers"`),
 		),
 	)
+
+	server := lastServer(targets)
+	if server == DefaultServerName {
+		return provideOption
+	}
+
+	return fx.Options(provideOption, supplyServerBinding(server, GetReturnType(errorHandler)))
+}
+
+// AsAuthorizer replaces the default [PrincipalAuthorizer] evaluating the handlers authorization policies.
+func AsAuthorizer(authorizer any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			authorizer,
+			fx.As(new(Authorizer)),
+		),
+	)
 }
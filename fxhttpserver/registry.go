@@ -2,6 +2,8 @@ package fxhttpserver
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
@@ -30,6 +32,11 @@ type HttpServerRegistry struct {
 	handlerDefinitions       []HandlerDefinition
 	handlersGroupDefinitions []HandlersGroupDefinition
 	errorHandlers            []ErrorHandler
+	sseHandlers              []SSEHandler
+	webSocketHandlers        []WebSocketHandler
+	authorizer               Authorizer
+	streams                  *StreamManager
+	param                    FxHttpServerRegistryParam
 }
 
 // FxHttpServerRegistryParam allows injection of the required dependencies in [NewFxHttpServerRegistry].
@@ -41,21 +48,58 @@ type FxHttpServerRegistryParam struct {
 	HandlerDefinitions       []HandlerDefinition       `group:"httpserver-handler-definitions"`
 	HandlersGroupDefinitions []HandlersGroupDefinition `group:"httpserver-handlers-group-definitions"`
 	ErrorHandlers            []ErrorHandler            `group:"httpserver-error-handlers"`
+	SSEHandlers              []SSEHandler              `group:"httpserver-sse-handlers"`
+	WebSocketHandlers        []WebSocketHandler        `group:"httpserver-websocket-handlers"`
+	ServerBindings           []serverBinding           `group:"httpserver-server-bindings"`
+	Authorizer               Authorizer                `optional:"true"`
+	Streams                  *StreamManager            `optional:"true"`
 }
 
-// NewFxHttpServerRegistry returns as new [HttpServerRegistry].
+// NewFxHttpServerRegistry returns as new [HttpServerRegistry], for the default http server.
 func NewFxHttpServerRegistry(p FxHttpServerRegistryParam) *HttpServerRegistry {
-	return &HttpServerRegistry{
+	return newHttpServerRegistry(p, DefaultServerName)
+}
+
+func newHttpServerRegistry(p FxHttpServerRegistryParam, server string) *HttpServerRegistry {
+	authorizer := p.Authorizer
+	if authorizer == nil {
+		authorizer = NewPrincipalAuthorizer()
+	}
+
+	bindings := make(map[any]string, len(p.ServerBindings))
+	for _, binding := range p.ServerBindings {
+		bindings[binding.target] = binding.server
+	}
+
+	onServer := func(target any) bool {
+		// the non comparable targets cannot be bound, and belong to the default server
+		if !reflect.TypeOf(target).Comparable() {
+			return server == DefaultServerName
+		}
 
+		return bindings[target] == server
+	}
+
+	return &HttpServerRegistry{
 		middlewares:              p.Middlewares,
-		middlewareDefinitions:    p.MiddlewareDefinitions,
+		middlewareDefinitions:    slices.DeleteFunc(slices.Clone(p.MiddlewareDefinitions), func(d MiddlewareDefinition) bool { return !onServer(d) }),
 		handlers:                 p.Handlers,
-		handlerDefinitions:       p.HandlerDefinitions,
-		handlersGroupDefinitions: p.HandlersGroupDefinitions,
-		errorHandlers:            p.ErrorHandlers,
+		handlerDefinitions:       slices.DeleteFunc(slices.Clone(p.HandlerDefinitions), func(d HandlerDefinition) bool { return !onServer(d) }),
+		handlersGroupDefinitions: slices.DeleteFunc(slices.Clone(p.HandlersGroupDefinitions), func(d HandlersGroupDefinition) bool { return !onServer(d) }),
+		errorHandlers:            slices.DeleteFunc(slices.Clone(p.ErrorHandlers), func(h ErrorHandler) bool { return !onServer(GetType(h)) }),
+		sseHandlers:              p.SSEHandlers,
+		webSocketHandlers:        p.WebSocketHandlers,
+		authorizer:               authorizer,
+		streams:                  p.Streams,
+		param:                    p,
 	}
 }
 
+// forServer returns a [HttpServerRegistry] for the named http server, with the registrations declared on it.
+func (r *HttpServerRegistry) forServer(server string) *HttpServerRegistry {
+	return newHttpServerRegistry(r.param, server)
+}
+
 // ResolveMiddlewares resolves a list of [ResolvedMiddleware] from their definitions.
 func (r *HttpServerRegistry) ResolveMiddlewares() ([]ResolvedMiddleware, error) {
 	var resolvedMiddlewares []ResolvedMiddleware
@@ -90,6 +134,10 @@ func (r *HttpServerRegistry) ResolveHandlers() ([]ResolvedHandler, error) {
 			handlerMiddlewares = append(handlerMiddlewares, handlerMiddleware.Middleware())
 		}
 
+		if policies := definitionPolicies(handlerDef); len(policies) > 0 {
+			handlerMiddlewares = append(handlerMiddlewares, AuthorizationMiddleware(r.authorizer, policies...))
+		}
+
 		resHandler, err := r.resolveHandlerDefinition(handlerDef, handlerMiddlewares)
 		if err != nil {
 			return nil, err
@@ -117,6 +165,10 @@ func (r *HttpServerRegistry) ResolveHandlersGroups() ([]ResolvedHandlersGroup, e
 			groupMiddlewares = append(groupMiddlewares, groupMiddleware.Middleware())
 		}
 
+		if policies := definitionPolicies(handlerGroupDef); len(policies) > 0 {
+			groupMiddlewares = append(groupMiddlewares, AuthorizationMiddleware(r.authorizer, policies...))
+		}
+
 		var groupHandlers []ResolvedHandler
 
 		for _, handlerDef := range handlerGroupDef.Handlers() {
@@ -131,6 +183,10 @@ func (r *HttpServerRegistry) ResolveHandlersGroups() ([]ResolvedHandlersGroup, e
 				resolvedHandlerMiddlewares = append(resolvedHandlerMiddlewares, resolvedHandlerMiddleware.Middleware())
 			}
 
+			if policies := definitionPolicies(handlerDef); len(policies) > 0 {
+				resolvedHandlerMiddlewares = append(resolvedHandlerMiddlewares, AuthorizationMiddleware(r.authorizer, policies...))
+			}
+
 			groupHandler, err := r.resolveHandlerDefinition(handlerDef, resolvedHandlerMiddlewares)
 			if err != nil {
 				return nil, err
@@ -152,6 +208,42 @@ func (r *HttpServerRegistry) ResolveHandlersGroups() ([]ResolvedHandlersGroup, e
 	return resolvedHandlersGroups, nil
 }
 
+// ResolveRoutePolicies resolves the authorization policies matrix of the registered handlers and handlers groups.
+func (r *HttpServerRegistry) ResolveRoutePolicies() []RoutePolicies {
+	var routePolicies []RoutePolicies
+
+	appendRoutePolicies := func(method string, path string, policies []AuthorizationPolicy) {
+		methods, err := ExtractMethods(method)
+		if err != nil || method == AllMethods {
+			methods = []string{method}
+		}
+
+		for _, m := range methods {
+			routePolicies = append(routePolicies, RoutePolicies{
+				Method:   m,
+				Path:     path,
+				Policies: policies,
+			})
+		}
+	}
+
+	for _, handlerDef := range r.handlerDefinitions {
+		appendRoutePolicies(handlerDef.Method(), handlerDef.Path(), definitionPolicies(handlerDef))
+	}
+
+	for _, handlerGroupDef := range r.handlersGroupDefinitions {
+		for _, handlerDef := range handlerGroupDef.Handlers() {
+			appendRoutePolicies(
+				handlerDef.Method(),
+				handlerGroupDef.Prefix()+handlerDef.Path(),
+				append(slices.Clone(definitionPolicies(handlerGroupDef)), definitionPolicies(handlerDef)...),
+			)
+		}
+	}
+
+	return routePolicies
+}
+
 // ResolveErrorHandlers resolves resolves a list of [ErrorHandler].
 func (r *HttpServerRegistry) ResolveErrorHandlers() []ErrorHandler {
 	return r.errorHandlers
@@ -200,6 +292,13 @@ func (r *HttpServerRegistry) resolveHandlerDefinition(handlerDefinition HandlerD
 				castHandler,
 				handlerMiddlewares...,
 			), nil
+		} else if instanceHandler, ok := handlerDefinition.Handler().(Handler); ok {
+			return NewResolvedHandler(
+				handlerDefinition.Method(),
+				handlerDefinition.Path(),
+				instanceHandler.Handle(),
+				handlerMiddlewares...,
+			), nil
 		} else {
 			return nil, fmt.Errorf("cannot cast handler definition as HandlerFunc")
 		}
@@ -210,7 +309,7 @@ func (r *HttpServerRegistry) resolveHandlerDefinition(handlerDefinition HandlerD
 		return nil, fmt.Errorf("handler definition is not a registered handler name")
 	}
 
-	registeredHandler, err := r.lookupRegisteredHandler(handlerName)
+	registeredHandler, err := r.lookupRegisteredHandlerFunc(handlerName)
 	if err != nil {
 		return nil, fmt.Errorf("cannot lookup registered handler")
 	}
@@ -218,7 +317,7 @@ func (r *HttpServerRegistry) resolveHandlerDefinition(handlerDefinition HandlerD
 	return NewResolvedHandler(
 		handlerDefinition.Method(),
 		handlerDefinition.Path(),
-		registeredHandler.Handle(),
+		registeredHandler,
 		handlerMiddlewares...,
 	), nil
 }
@@ -242,3 +341,31 @@ func (r *HttpServerRegistry) lookupRegisteredHandler(handler string) (Handler, e
 
 	return nil, fmt.Errorf("cannot find handler for type %s", handler)
 }
+
+func (r *HttpServerRegistry) lookupRegisteredHandlerFunc(handler string) (echo.HandlerFunc, error) {
+	if registeredHandler, err := r.lookupRegisteredHandler(handler); err == nil {
+		return registeredHandler.Handle(), nil
+	}
+
+	for _, h := range r.sseHandlers {
+		if GetType(h) == handler {
+			if r.streams == nil {
+				return nil, fmt.Errorf("cannot serve sse handler %s without stream manager", handler)
+			}
+
+			return r.streams.SSEHandlerFunc(h), nil
+		}
+	}
+
+	for _, h := range r.webSocketHandlers {
+		if GetType(h) == handler {
+			if r.streams == nil {
+				return nil, fmt.Errorf("cannot serve websocket handler %s without stream manager", handler)
+			}
+
+			return r.streams.WebSocketHandlerFunc(h), nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot find handler for type %s", handler)
+}
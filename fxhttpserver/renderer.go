@@ -0,0 +1,66 @@
+package fxhttpserver
+
+import (
+	"html/template"
+	"io"
+	"io/fs"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HtmlTemplateRenderer allows to render HTML templates, based on [html/template], parsed from the disk or from a
+// filesystem (like an [embed.FS]).
+//
+// [html/template]: https://pkg.go.dev/html/template
+// [embed.FS]: https://pkg.go.dev/embed
+type HtmlTemplateRenderer struct {
+	fsys    fs.FS
+	pattern string
+	reload  bool
+	engine  *template.Template
+}
+
+// NewHtmlTemplateRenderer returns a [HtmlTemplateRenderer], for a file pattern, looked up in the provided filesystem,
+// or on the disk if nil.
+//
+// If reload is true, the templates are parsed again on each render, to reflect their changes without restart.
+func NewHtmlTemplateRenderer(fsys fs.FS, pattern string, reload bool) (*HtmlTemplateRenderer, error) {
+	renderer := &HtmlTemplateRenderer{
+		fsys:    fsys,
+		pattern: pattern,
+		reload:  reload,
+	}
+
+	engine, err := renderer.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	renderer.engine = engine
+
+	return renderer, nil
+}
+
+// Render executes a named template, with provided data, and write the result to the provided [io.Writer].
+func (r *HtmlTemplateRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	engine := r.engine
+
+	if r.reload {
+		var err error
+
+		engine, err = r.parse()
+		if err != nil {
+			return err
+		}
+	}
+
+	return engine.ExecuteTemplate(w, name, data)
+}
+
+func (r *HtmlTemplateRenderer) parse() (*template.Template, error) {
+	if r.fsys == nil {
+		return template.ParseGlob(r.pattern)
+	}
+
+	return template.ParseFS(r.fsys, r.pattern)
+}
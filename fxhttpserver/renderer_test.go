@@ -0,0 +1,126 @@
+package fxhttpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/assets"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithEmbeddedTemplates(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEMPLATES_ENABLED", "true")
+	t.Setenv("TEMPLATES_PATH", "templates/*.html")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsTemplatesFS(assets.Templates),
+		fxhttpserver.AsHandler("GET", "/template", handler.NewTestTemplateHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/template", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Embedded app name: test", rec.Body.String())
+}
+
+func TestModuleWithTemplatesReloadInDevEnv(t *testing.T) {
+	templatesDir := t.TempDir()
+	templatePath := filepath.Join(templatesDir, "test.html")
+
+	err := os.WriteFile(templatePath, []byte(`Before reload: {{index . "name"}}`), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("TEMPLATES_ENABLED", "true")
+	t.Setenv("TEMPLATES_PATH", "*.html")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsTemplatesFS(os.DirFS(templatesDir)),
+		fxhttpserver.AsHandler("GET", "/template", handler.NewTestTemplateHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodGet, "/template", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Before reload: test", rec.Body.String())
+
+	err = os.WriteFile(templatePath, []byte(`After reload: {{index . "name"}}`), 0o600)
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, "/template", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "After reload: test", rec.Body.String())
+}
+
+func TestNewHtmlTemplateRenderer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without reload", func(t *testing.T) {
+		t.Parallel()
+
+		renderer, err := fxhttpserver.NewHtmlTemplateRenderer(assets.Templates, "templates/*.html", false)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		err = renderer.Render(rec, "test.html", map[string]any{"name": "test"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "Embedded app name: test", rec.Body.String())
+	})
+
+	t.Run("with invalid pattern", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := fxhttpserver.NewHtmlTemplateRenderer(assets.Templates, "invalid/*.html", false)
+		assert.Error(t, err)
+
+		_, err = fxhttpserver.NewHtmlTemplateRenderer(nil, "testdata/invalid/*.html", false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package fxhttpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	DefaultCSRFCookieName     = "_csrf"
+	DefaultCSRFCookiePath     = "/"
+	DefaultCSRFTokenLookup    = "header:X-CSRF-Token,form:_csrf"
+	DefaultCSRFContextKey     = "csrf"
+	DefaultFrameOptions       = "SAMEORIGIN"
+	DefaultReferrerPolicy     = "strict-origin-when-cross-origin"
+	DefaultContentTypeNosniff = "nosniff"
+	SameSiteModeDefault       = "default"
+	SameSiteModeLax           = "lax"
+	SameSiteModeStrict        = "strict"
+	SameSiteModeNone          = "none"
+	corsWildcardOrigin        = "*"
+)
+
+// CSRFToken returns the CSRF token of the current request, to render in the forms (in the field or header
+// configured in modules.http.server.security.csrf.token_lookup), or an empty string if the CSRF protection is disabled.
+func CSRFToken(c echo.Context) string {
+	if token, ok := c.Get(DefaultCSRFContextKey).(string); ok {
+		return token
+	}
+
+	return ""
+}
+
+// FetchSameSiteMode returns a [http.SameSite] for a given value.
+func FetchSameSiteMode(mode string) (http.SameSite, error) {
+	switch strings.ToLower(mode) {
+	case "", SameSiteModeDefault:
+		return http.SameSiteDefaultMode, nil
+	case SameSiteModeLax:
+		return http.SameSiteLaxMode, nil
+	case SameSiteModeStrict:
+		return http.SameSiteStrictMode, nil
+	case SameSiteModeNone:
+		return http.SameSiteNoneMode, nil
+	default:
+		return http.SameSiteDefaultMode, fmt.Errorf("invalid same site mode %s, must be default, lax, strict or none", mode)
+	}
+}
+
+func withSecurityMiddlewares(httpServer *echo.Echo, p FxHttpServerParam) (*echo.Echo, error) {
+	// cors middleware
+	if p.Config.GetBool("modules.http.server.security.cors.enabled") {
+		origins := p.Config.GetStringSlice("modules.http.server.security.cors.origins")
+		credentials := p.Config.GetBool("modules.http.server.security.cors.credentials")
+
+		if credentials && (len(origins) == 0 || slices.Contains(origins, corsWildcardOrigin)) {
+			return httpServer, errors.New("invalid security cors configuration, credentials cannot be allowed for all origins")
+		}
+
+		httpServer.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOrigins:     origins,
+			AllowMethods:     p.Config.GetStringSlice("modules.http.server.security.cors.methods"),
+			AllowHeaders:     p.Config.GetStringSlice("modules.http.server.security.cors.headers"),
+			ExposeHeaders:    p.Config.GetStringSlice("modules.http.server.security.cors.expose"),
+			AllowCredentials: credentials,
+			MaxAge:           p.Config.GetInt("modules.http.server.security.cors.max_age"),
+		}))
+	}
+
+	// security headers middleware
+	if p.Config.GetBool("modules.http.server.security.headers.enabled") {
+		frameOptions := p.Config.GetString("modules.http.server.security.headers.frame_options")
+		if frameOptions == "" {
+			frameOptions = DefaultFrameOptions
+		}
+
+		referrerPolicy := p.Config.GetString("modules.http.server.security.headers.referrer_policy")
+		if referrerPolicy == "" {
+			referrerPolicy = DefaultReferrerPolicy
+		}
+
+		excludes := p.Config.GetStringSlice("modules.http.server.security.headers.exclude")
+
+		httpServer.Use(middleware.SecureWithConfig(middleware.SecureConfig{
+			Skipper: func(c echo.Context) bool {
+				return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+			},
+			ContentTypeNosniff:    DefaultContentTypeNosniff,
+			XFrameOptions:         frameOptions,
+			ReferrerPolicy:        referrerPolicy,
+			ContentSecurityPolicy: p.Config.GetString("modules.http.server.security.headers.csp.policy"),
+			CSPReportOnly:         p.Config.GetBool("modules.http.server.security.headers.csp.report_only"),
+			HSTSMaxAge:            p.Config.GetInt("modules.http.server.security.headers.hsts.max_age"),
+			HSTSExcludeSubdomains: !p.Config.GetBool("modules.http.server.security.headers.hsts.include_subdomains"),
+			HSTSPreloadEnabled:    p.Config.GetBool("modules.http.server.security.headers.hsts.preload"),
+		}))
+	}
+
+	// csrf middleware
+	if p.Config.GetBool("modules.http.server.security.csrf.enabled") {
+		sameSite, err := FetchSameSiteMode(p.Config.GetString("modules.http.server.security.csrf.cookie.same_site"))
+		if err != nil {
+			return httpServer, fmt.Errorf("invalid security csrf cookie configuration: %w", err)
+		}
+
+		tokenLookup := p.Config.GetString("modules.http.server.security.csrf.token_lookup")
+		if tokenLookup == "" {
+			tokenLookup = DefaultCSRFTokenLookup
+		}
+
+		cookieName := p.Config.GetString("modules.http.server.security.csrf.cookie.name")
+		if cookieName == "" {
+			cookieName = DefaultCSRFCookieName
+		}
+
+		cookiePath := p.Config.GetString("modules.http.server.security.csrf.cookie.path")
+		if cookiePath == "" {
+			cookiePath = DefaultCSRFCookiePath
+		}
+
+		excludes := p.Config.GetStringSlice("modules.http.server.security.csrf.exclude")
+
+		httpServer.Use(middleware.CSRFWithConfig(middleware.CSRFConfig{
+			Skipper: func(c echo.Context) bool {
+				return httpserver.MatchPrefix(excludes, c.Request().URL.Path)
+			},
+			TokenLookup:    tokenLookup,
+			ContextKey:     DefaultCSRFContextKey,
+			CookieName:     cookieName,
+			CookieDomain:   p.Config.GetString("modules.http.server.security.csrf.cookie.domain"),
+			CookiePath:     cookiePath,
+			CookieMaxAge:   p.Config.GetInt("modules.http.server.security.csrf.cookie.max_age"),
+			CookieSecure:   p.Config.GetBool("modules.http.server.security.csrf.cookie.secure"),
+			CookieHTTPOnly: true,
+			CookieSameSite: sameSite,
+		}))
+	}
+
+	return httpServer, nil
+}
@@ -0,0 +1,206 @@
+package fxhttpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithSecurity(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TEMPLATES_ENABLED", "true")
+	t.Setenv("TEMPLATES_PATH", "testdata/templates/*.html")
+	t.Setenv("SECURITY_CORS_ENABLED", "true")
+	t.Setenv("SECURITY_CORS_ORIGINS", "https://allowed.example.com")
+	t.Setenv("SECURITY_CORS_CREDENTIALS", "true")
+	t.Setenv("SECURITY_HEADERS_ENABLED", "true")
+	t.Setenv("SECURITY_CSRF_ENABLED", "true")
+	t.Setenv("SECURITY_CSRF_COOKIE_SAME_SITE", "strict")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsHandler("GET,POST", "/form", handler.NewTestFormHandler),
+		fxhttpserver.AsHandler("POST", "/security/excluded", handler.NewTestBarHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// cors preflight
+	req := httptest.NewRequest(http.MethodOptions, "/form", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://allowed.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://allowed.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET,POST", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "X-Custom-Header", rec.Header().Get(echo.HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "3600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+
+	// cors not allowed origin
+	req = httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://other.example.com")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+
+	// security headers and csrf token
+	req = httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.Header.Set(echo.HeaderXForwardedProto, "https")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "max-age=31536000; includeSubdomains", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	assert.Equal(t, "default-src 'self'", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+	assert.Equal(t, "DENY", rec.Header().Get(echo.HeaderXFrameOptions))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get(echo.HeaderReferrerPolicy))
+	assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, fxhttpserver.DefaultCSRFCookieName, cookies[0].Name)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	assert.True(t, cookies[0].HttpOnly)
+
+	matches := regexp.MustCompile(`name="_csrf" value="([^"]+)"`).FindStringSubmatch(rec.Body.String())
+	assert.Len(t, matches, 2)
+	assert.Equal(t, cookies[0].Value, matches[1])
+
+	// csrf protected form submission
+	submit := func(token string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		form := url.Values{"_csrf": {token}}
+
+		req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec = submit(matches[1], cookies[0])
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "submitted", rec.Body.String())
+
+	rec = submit("invalid", cookies[0])
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = submit(matches[1], nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// excluded path
+	req = httptest.NewRequest(http.MethodPost, "/security/excluded", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderXFrameOptions))
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestModuleWithInvalidSecurityConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name: "cors credentials for all origins",
+			env: map[string]string{
+				"SECURITY_CORS_ENABLED":     "true",
+				"SECURITY_CORS_ORIGINS":     "*",
+				"SECURITY_CORS_CREDENTIALS": "true",
+			},
+			expected: "invalid security cors configuration, credentials cannot be allowed for all origins",
+		},
+		{
+			name: "csrf invalid same site mode",
+			env: map[string]string{
+				"SECURITY_CSRF_ENABLED":          "true",
+				"SECURITY_CSRF_COOKIE_SAME_SITE": "invalid",
+			},
+			expected: "invalid security csrf cookie configuration: invalid same site mode invalid, must be default, lax, strict or none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_CONFIG_PATH", "testdata/config")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			err := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Invoke(func(*echo.Echo) {}),
+			).Err()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestFetchSameSiteMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode     string
+		expected http.SameSite
+		err      bool
+	}{
+		{"", http.SameSiteDefaultMode, false},
+		{"default", http.SameSiteDefaultMode, false},
+		{"Lax", http.SameSiteLaxMode, false},
+		{"strict", http.SameSiteStrictMode, false},
+		{"none", http.SameSiteNoneMode, false},
+		{"invalid", http.SameSiteDefaultMode, true},
+	}
+
+	for _, tt := range tests {
+		mode, err := fxhttpserver.FetchSameSiteMode(tt.mode)
+		assert.Equal(t, tt.expected, mode)
+		assert.Equal(t, tt.err, err != nil)
+	}
+}
@@ -0,0 +1,27 @@
+package fxhttpserver
+
+import (
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/httpserver"
+)
+
+// serverOptions returns the http server options for the modules.http.server timeouts, max header bytes, body limit and h2c configuration.
+func serverOptions(cfg *config.Config) []httpserver.HttpServerOption {
+	options := []httpserver.HttpServerOption{
+		httpserver.WithReadTimeout(time.Duration(cfg.GetInt("modules.http.server.timeouts.read")) * time.Second),
+		httpserver.WithWriteTimeout(time.Duration(cfg.GetInt("modules.http.server.timeouts.write")) * time.Second),
+		httpserver.WithIdleTimeout(time.Duration(cfg.GetInt("modules.http.server.timeouts.idle")) * time.Second),
+		httpserver.WithMaxHeaderBytes(cfg.GetInt("modules.http.server.max_header_bytes")),
+		httpserver.WithBodyLimit(cfg.GetString("modules.http.server.body_limit")),
+		httpserver.WithH2C(cfg.GetBool("modules.http.server.h2c")),
+	}
+
+	// keep the httpserver default read header timeout if not configured
+	if readHeaderTimeout := cfg.GetInt("modules.http.server.timeouts.read_header"); readHeaderTimeout > 0 {
+		options = append(options, httpserver.WithReadHeaderTimeout(time.Duration(readHeaderTimeout)*time.Second))
+	}
+
+	return options
+}
@@ -0,0 +1,116 @@
+package fxhttpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithServerSettings(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SERVER_TIMEOUT_READ", "10")
+	t.Setenv("SERVER_TIMEOUT_READ_HEADER", "5")
+	t.Setenv("SERVER_TIMEOUT_WRITE", "20")
+	t.Setenv("SERVER_TIMEOUT_IDLE", "60")
+	t.Setenv("SERVER_MAX_HEADER_BYTES", "4096")
+	t.Setenv("SERVER_BODY_LIMIT", "10B")
+	t.Setenv("SERVER_H2C", "true")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Invoke(func(httpServer *echo.Echo) {
+			httpServer.POST("/test", func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			})
+		}),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	for _, server := range []*http.Server{httpServer.Server, httpServer.TLSServer} {
+		assert.Equal(t, 10*time.Second, server.ReadTimeout)
+		assert.Equal(t, 5*time.Second, server.ReadHeaderTimeout)
+		assert.Equal(t, 20*time.Second, server.WriteTimeout)
+		assert.Equal(t, 60*time.Second, server.IdleTimeout)
+		assert.Equal(t, 4096, server.MaxHeaderBytes)
+	}
+
+	assert.True(t, httpServer.Server.Protocols.HTTP1())
+	assert.True(t, httpServer.Server.Protocols.UnencryptedHTTP2())
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("small"))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("this body is too large"))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestModuleWithDefaultServerSettings(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, time.Duration(0), httpServer.Server.ReadTimeout)
+	assert.Equal(t, httpserver.DefaultReadHeaderTimeout, httpServer.Server.ReadHeaderTimeout)
+	assert.Equal(t, 0, httpServer.Server.MaxHeaderBytes)
+	assert.Nil(t, httpServer.Server.Protocols)
+}
+
+func TestModuleWithInvalidBodyLimit(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SERVER_BODY_LIMIT", "invalid")
+
+	var httpServer *echo.Echo
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create http server: invalid body limit invalid")
+}
@@ -0,0 +1,164 @@
+package fxhttpserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+const (
+	DefaultServerName         = ""
+	DefaultServerMetricsLabel = "default"
+	ServerMetricsLabel        = "server"
+	serverBindingGroupTag     = `group:"httpserver-server-bindings"`
+)
+
+// ServerTarget is the named http server targeted by a registration, declared with [OnServer].
+type ServerTarget struct {
+	Name string
+}
+
+// OnServer returns a [ServerTarget], to declare among the registrations middlewares (or options) to register them
+// on the named http server configured in modules.http.servers.<name>, instead of the default one.
+// The name is lowercased, as the configuration keys.
+func OnServer(name string) ServerTarget {
+	return ServerTarget{
+		Name: strings.ToLower(name),
+	}
+}
+
+// serverBinding binds a registration target (definition, or error handler type) to a named http server.
+type serverBinding struct {
+	server string
+	target any
+}
+
+// applyRegistrationOption sets the registration server, the last declared target wins.
+func (t ServerTarget) applyRegistrationOption(options *registrationOptions) {
+	options.server = t.Name
+}
+
+// lastServer returns the name of the last provided [ServerTarget], or the default server name.
+func lastServer(targets []ServerTarget) string {
+	if len(targets) == 0 {
+		return DefaultServerName
+	}
+
+	return targets[len(targets)-1].Name
+}
+
+// supplyServerBinding supplies a [serverBinding] into Fx, if the target is bound to a named http server.
+func supplyServerBinding(server string, target any) fx.Option {
+	if server == DefaultServerName {
+		return fx.Options()
+	}
+
+	return fx.Supply(
+		fx.Annotate(
+			serverBinding{
+				server: server,
+				target: target,
+			},
+			fx.ResultTags(serverBindingGroupTag),
+		),
+	)
+}
+
+// HttpServerPool holds the named http servers, configured in modules.http.servers.<name>.
+type HttpServerPool struct {
+	servers map[string]*echo.Echo
+}
+
+// Get returns the named http server.
+func (p *HttpServerPool) Get(name string) (*echo.Echo, error) {
+	server, ok := p.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("http server %s is not configured", name)
+	}
+
+	return server, nil
+}
+
+// Names returns the sorted names of the http servers.
+func (p *HttpServerPool) Names() []string {
+	names := make([]string, 0, len(p.servers))
+	for name := range p.servers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewFxHttpServerPool returns a new [HttpServerPool], creating an http server for each modules.http.servers.<name>
+// configuration entry.
+//
+// Each named http server is configured like the default one (with the same keys as modules.http.server), serves
+// the registrations declared with [OnServer] for its name, and labels its metrics with its name (the default http
+// server metrics being then labelled with default).
+func NewFxHttpServerPool(p FxHttpServerParam) (*HttpServerPool, error) {
+	pool := &HttpServerPool{
+		servers: map[string]*echo.Echo{},
+	}
+
+	for _, name := range p.Config.SubKeys("modules.http.servers") {
+		if name == DefaultServerMetricsLabel {
+			return nil, fmt.Errorf("http server name %s is reserved", name)
+		}
+
+		serverConfig, err := newServerConfig(p.Config, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if serverConfig.GetString("modules.http.server.address") == "" {
+			return nil, fmt.Errorf("missing address for http server %s", name)
+		}
+
+		serverParam := p
+		serverParam.Config = serverConfig
+		serverParam.Registry = p.Registry.forServer(name)
+		serverParam.OpenApiSpec = NewFxOpenApiSpec(FxOpenApiSpecParam{
+			Config:   serverConfig,
+			Registry: serverParam.Registry,
+		})
+		serverParam.TimeoutRules = filterTimeoutRules(p.TimeoutRules, name)
+
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{ServerMetricsLabel: name}, p.MetricsRegistry)
+
+		httpServer, err := newHttpServer(serverParam, name, registerer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http server %s: %w", name, err)
+		}
+
+		pool.servers[name] = httpServer
+	}
+
+	return pool, nil
+}
+
+// serverMetricsRegisterer returns the [prometheus.Registerer] of the default http server, labelling its metrics if
+// named http servers are configured.
+func serverMetricsRegisterer(cfg *config.Config, registry *prometheus.Registry) prometheus.Registerer {
+	if len(cfg.SubKeys("modules.http.servers")) == 0 {
+		return registry
+	}
+
+	return prometheus.WrapRegistererWith(prometheus.Labels{ServerMetricsLabel: DefaultServerMetricsLabel}, registry)
+}
+
+// newServerConfig returns a [config.Config] where modules.http.server is the modules.http.servers.<name> entry.
+func newServerConfig(cfg *config.Config, name string) (*config.Config, error) {
+	serverConfig, err := cfg.Override("modules.http.server", fmt.Sprintf("modules.http.servers.%s", name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration for http server %s: %w", name, err)
+	}
+
+	return serverConfig, nil
+}
@@ -0,0 +1,165 @@
+package fxhttpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/errorhandler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/middleware"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithNamedServers(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "servers")
+	t.Setenv("SERVERS_PUBLIC_ADDRESS", "127.0.0.1:0")
+
+	var httpServer *echo.Echo
+	var pool *fxhttpserver.HttpServerPool
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsHandler("GET", "/bar", handler.NewTestBarHandler),
+		fxhttpserver.AsHandler("GET", "/public/bar", handler.NewTestBarHandler, fxhttpserver.OnServer("Public")),
+		fxhttpserver.AsHandlersGroup(
+			"/admin",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration("GET", "/error", handler.NewTestErrorHandler),
+				fxhttpserver.NewHandlerRegistration("GET", "/timeout", handler.NewTestTimeoutHandler),
+			},
+			fxhttpserver.OnServer("admin"),
+		),
+		fxhttpserver.AsMiddleware(middleware.NewTestGlobalMiddleware, fxhttpserver.GlobalUse, fxhttpserver.OnServer("admin")),
+		fxhttpserver.AsErrorHandler(errorhandler.NewTestErrorHandler, fxhttpserver.OnServer("admin")),
+		fx.Populate(&httpServer, &pool, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, []string{"admin", "public"}, pool.Names())
+
+	publicServer, err := pool.Get("public")
+	assert.NoError(t, err)
+
+	adminServer, err := pool.Get("admin")
+	assert.NoError(t, err)
+
+	_, err = pool.Get("invalid")
+	assert.Error(t, err)
+	assert.Equal(t, "http server invalid is not configured", err.Error())
+
+	serve := func(server *echo.Echo, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// default server
+	rec := serve(httpServer, "/bar")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("global-middleware"))
+
+	for _, target := range []string{"/public/bar", "/admin/timeout"} {
+		assert.Equal(t, http.StatusNotFound, serve(httpServer, target).Code)
+	}
+
+	// public server
+	rec = serve(publicServer, "/public/bar")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bar: test", rec.Body.String())
+
+	for _, target := range []string{"/bar", "/admin/timeout"} {
+		assert.Equal(t, http.StatusNotFound, serve(publicServer, target).Code)
+	}
+
+	rec = serve(publicServer, "/openapi.json")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var document fxhttpserver.OpenApiDocument
+	err = json.Unmarshal(rec.Body.Bytes(), &document)
+	assert.NoError(t, err)
+	assert.Equal(t, "public api", document.Info.Title)
+	assert.Len(t, document.Paths, 1)
+	assert.Contains(t, document.Paths, "/public/bar")
+
+	// admin server
+	rec = serve(adminServer, "/admin/timeout?delay=1ms")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3s", rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get("global-middleware"))
+
+	rec = serve(adminServer, "/admin/error")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "error handled in test error handler of test: test error", rec.Body.String())
+
+	for _, target := range []string{"/bar", "/public/bar", "/openapi.json"} {
+		rec = serve(adminServer, target)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "error handled in test error handler of test: code=404, message=Not Found", rec.Body.String())
+	}
+
+	// metrics
+	expectedMetric := `
+		# HELP http_server_requests_total Number of processed HTTP requests
+		# TYPE http_server_requests_total counter
+		http_server_requests_total{method="GET",path="/admin/error",server="admin",status="5xx"} 1
+		http_server_requests_total{method="GET",path="/admin/timeout",server="admin",status="2xx"} 1
+		http_server_requests_total{method="GET",path="/bar",server="default",status="2xx"} 1
+		http_server_requests_total{method="GET",path="/not-found",server="admin",status="5xx"} 3
+		http_server_requests_total{method="GET",path="/not-found",server="default",status="4xx"} 2
+		http_server_requests_total{method="GET",path="/not-found",server="public",status="4xx"} 2
+		http_server_requests_total{method="GET",path="/openapi.json",server="public",status="2xx"} 1
+		http_server_requests_total{method="GET",path="/public/bar",server="public",status="2xx"} 1
+	`
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithNamedServerWithoutAddress(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "servers")
+	t.Setenv("SERVERS_PUBLIC_ADDRESS", "")
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing address for http server public")
+}
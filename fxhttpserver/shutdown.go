@@ -0,0 +1,41 @@
+package fxhttpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+)
+
+// shutdownHttpServer stops the http server from accepting new requests, and waits for the in-flight ones up to the
+// context deadline, after which the remaining connections are closed.
+func shutdownHttpServer(ctx context.Context, httpServer *echo.Echo, name string, p FxHttpServerParam) error {
+	loggerContext := p.Logger.ToZerolog().With().Str("module", ModuleName)
+	label := "http server"
+	if name != DefaultServerName {
+		loggerContext = loggerContext.Str("server", name)
+		label = fmt.Sprintf("http server %s", name)
+	}
+
+	logger := log.FromZerolog(loggerContext.Logger())
+
+	healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, label+" stopped accepting new requests, waiting for in-flight requests")
+
+	err := httpServer.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, label+" stop deadline reached, closing in-flight requests")
+
+		return httpServer.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	healthcheck.RecordShutdown(logger, p.ShutdownRecorder, ModuleName, label+" in-flight requests completed, stopped")
+
+	return nil
+}
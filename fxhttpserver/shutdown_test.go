@@ -0,0 +1,141 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/shutdown"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	address := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	return address
+}
+
+func TestModuleShutdown(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "dev")
+
+	tests := []struct {
+		name          string
+		duration      time.Duration
+		expectedError error
+		expected      []string
+	}{
+		{
+			name:     "in-flight requests completed",
+			duration: 50 * time.Millisecond,
+			expected: []string{
+				"httpserver: http server stopped accepting new requests, waiting for in-flight requests",
+				"httpserver: http server in-flight requests completed, stopped",
+			},
+		},
+		{
+			name:          "stop deadline reached",
+			duration:      5 * time.Second,
+			expectedError: context.DeadlineExceeded,
+			expected: []string{
+				"httpserver: http server stopped accepting new requests, waiting for in-flight requests",
+				"httpserver: http server stop deadline reached, closing in-flight requests",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := freeAddress(t)
+			t.Setenv("MODULES_HTTP_SERVER_ADDRESS", address)
+
+			recorder := shutdown.NewTestShutdownRecorder()
+			started := make(chan struct{})
+
+			var httpServer *echo.Echo
+
+			app := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxhttpserver.FxHttpServerModule,
+				fx.Provide(
+					fx.Annotate(
+						func() *shutdown.TestShutdownRecorder {
+							return recorder
+						},
+						fx.As(new(healthcheck.ShutdownRecorder)),
+					),
+				),
+				fxhttpserver.AsHandler("GET", "/slow", func(c echo.Context) error {
+					close(started)
+
+					select {
+					case <-time.After(tt.duration):
+					case <-c.Request().Context().Done():
+					}
+
+					return c.String(http.StatusOK, "ok")
+				}),
+				fx.Populate(&httpServer),
+			)
+
+			assert.NoError(t, app.Start(context.Background()))
+
+			assert.Eventually(t, func() bool {
+				conn, err := net.Dial("tcp", address)
+				if err != nil {
+					return false
+				}
+
+				return conn.Close() == nil
+			}, time.Second, 10*time.Millisecond)
+
+			go func() {
+				//nolint:noctx
+				resp, err := http.Get(fmt.Sprintf("http://%s/slow", address))
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+			}()
+
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			err := app.Stop(ctx)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// the stop hook keeps running after the fx stop deadline, to close the in-flight requests
+			assert.Eventually(t, func() bool {
+				return assert.ObjectsAreEqual(tt.expected, recorder.Messages())
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package fxhttpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+)
+
+// MIMETextEventStream is the Server-Sent Events content type.
+const MIMETextEventStream = "text/event-stream"
+
+// ErrStreamClosed is returned when sending on a closed stream.
+var ErrStreamClosed = errors.New("stream closed")
+
+// SSEHandler is the interface for Server-Sent Events handlers.
+type SSEHandler interface {
+	Stream(c echo.Context, stream *SSEStream) error
+}
+
+// SSEEvent is a Server-Sent Event.
+type SSEEvent struct {
+	Id    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// SSEStream is a Server-Sent Events stream, safe for concurrent use.
+//
+// Sending blocks until the event is flushed to the client (backpressure), or fails after the write timeout.
+type SSEStream struct {
+	ctx          context.Context
+	mutex        sync.Mutex
+	controller   *http.ResponseController
+	writer       http.ResponseWriter
+	writeTimeout time.Duration
+	onSend       func()
+	closed       bool
+}
+
+// Context returns the stream context, carrying the request logger and trace span, and canceled when the client
+// disconnects, or when the server shuts down.
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send sends an event on the stream.
+func (s *SSEStream) Send(event SSEEvent) error {
+	var builder strings.Builder
+
+	if event.Id != "" {
+		builder.WriteString("id: " + event.Id + "\n")
+	}
+
+	if event.Event != "" {
+		builder.WriteString("event: " + event.Event + "\n")
+	}
+
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	for _, line := range strings.Split(event.Data, "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+
+	builder.WriteString("\n")
+
+	err := s.write(builder.String())
+	if err != nil {
+		return err
+	}
+
+	s.onSend()
+
+	return nil
+}
+
+// SendJSON sends an event on the stream, with a JSON encoded data.
+func (s *SSEStream) SendJSON(event string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cannot encode event data: %w", err)
+	}
+
+	return s.Send(SSEEvent{
+		Event: event,
+		Data:  string(encoded),
+	})
+}
+
+func (s *SSEStream) heartbeat() error {
+	return s.write(": heartbeat\n\n")
+}
+
+func (s *SSEStream) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+}
+
+func (s *SSEStream) write(content string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed || s.ctx.Err() != nil {
+		return ErrStreamClosed
+	}
+
+	err := s.controller.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	_, err = s.writer.Write([]byte(content))
+	if err != nil {
+		return err
+	}
+
+	return s.controller.Flush()
+}
+
+// SSEHandlerFunc returns an [echo.HandlerFunc] streaming Server-Sent Events with a provided [SSEHandler].
+func (m *StreamManager) SSEHandlerFunc(handler SSEHandler) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		path := c.Path()
+
+		connCtx, release, ok := m.open(req.Context(), StreamProtocolSSE, path)
+		if !ok {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "server is shutting down")
+		}
+
+		defer release()
+
+		// canceled as well on heartbeat failure, to notify the handler of the client disconnection
+		ctx, cancel := context.WithCancel(connCtx)
+		defer cancel()
+
+		c.SetRequest(req.WithContext(ctx))
+
+		resp := c.Response()
+		resp.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+		resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+		resp.Header().Set(echo.HeaderConnection, "keep-alive")
+		resp.Header().Set("X-Accel-Buffering", "no")
+		resp.WriteHeader(http.StatusOK)
+
+		stream := &SSEStream{
+			ctx:          ctx,
+			controller:   http.NewResponseController(resp),
+			writer:       resp,
+			writeTimeout: m.config.WriteTimeout,
+			onSend: func() {
+				m.countMessage(StreamProtocolSSE, StreamDirectionSent, path)
+			},
+		}
+
+		err := stream.controller.Flush()
+		if err != nil {
+			return err
+		}
+
+		heartbeatCtx, heartbeatCancel := context.WithCancel(ctx)
+		defer heartbeatCancel()
+
+		go func() {
+			ticker := time.NewTicker(m.config.Heartbeat)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-heartbeatCtx.Done():
+					return
+				case <-ticker.C:
+					if hbErr := stream.heartbeat(); hbErr != nil {
+						log.CtxLogger(ctx).Debug().Err(hbErr).Msg("sse heartbeat failure")
+						cancel()
+
+						return
+					}
+				}
+			}
+		}()
+
+		err = handler.Stream(c, stream)
+
+		// prevents writes once the handler returned
+		heartbeatCancel()
+		stream.close()
+
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrStreamClosed) {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/fxhttpservertest"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/ankorstore/yokai/trace/tracetest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithSSEHandler(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("STREAMS_HEARTBEAT", "1")
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+	var logBuffer logtest.TestLogBuffer
+	var traceExporter tracetest.TestTraceExporter
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsSSEHandler("/sse", handler.NewTestSSEHandler),
+		fxhttpserver.AsSSEHandler(
+			"/sse/admin",
+			handler.NewTestSSEHandler,
+			fxhttpserver.NewFxBasicAuthMiddleware,
+			fxhttpserver.RequireRoles("admin"),
+		),
+		fx.Populate(&httpServer, &metricsRegistry, &logBuffer, &traceExporter),
+	).RequireStart()
+
+	srv := fxhttpservertest.NewHttpServerTestServer(httpServer)
+	defer srv.Close()
+
+	headers := http.Header{}
+	headers.Set("x-request-id", testRequestId)
+	headers.Set("traceparent", testTraceParent)
+
+	cli, err := srv.ConnectSSE(context.Background(), "/sse", headers)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, cli.Response().StatusCode)
+	assert.Equal(t, fxhttpserver.MIMETextEventStream, cli.Response().Header.Get(echo.HeaderContentType))
+	assert.Equal(t, "no-cache", cli.Response().Header.Get(echo.HeaderCacheControl))
+
+	// event
+	event, err := cli.Next(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, fxhttpserver.SSEEvent{Id: "1", Event: "greeting", Data: "hello: test"}, event)
+
+	// heartbeat
+	_, err = cli.Next(1500 * time.Millisecond)
+	assert.ErrorIs(t, err, fxhttpservertest.ErrTimeout)
+	assert.GreaterOrEqual(t, cli.Heartbeats(), 1)
+
+	// connection context
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"service":   "test",
+		"module":    "httpserver",
+		"requestID": testRequestId,
+		"traceID":   testTraceId,
+		"message":   "in sse handler",
+	})
+
+	// metrics
+	expectedMetric := `
+		# HELP http_server_stream_connections Number of open HTTP streams connections
+		# TYPE http_server_stream_connections gauge
+		http_server_stream_connections{path="/sse",protocol="sse"} 1
+		# HELP http_server_stream_messages_total Number of HTTP streams messages sent and received
+		# TYPE http_server_stream_messages_total counter
+		http_server_stream_messages_total{direction="sent",path="/sse",protocol="sse"} 1
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_stream_connections",
+		"http_server_stream_messages_total",
+	)
+	assert.NoError(t, err)
+
+	// authorization
+	for _, tc := range []struct {
+		username string
+		password string
+		status   int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"user", "user-password", http.StatusForbidden},
+		{"admin", "admin-password", http.StatusOK},
+	} {
+		authHeaders := http.Header{}
+		if tc.username != "" {
+			req, reqErr := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, reqErr)
+
+			req.SetBasicAuth(tc.username, tc.password)
+			authHeaders = req.Header
+		}
+
+		authCli, authErr := srv.ConnectSSE(context.Background(), "/sse/admin?once=true", authHeaders)
+		assert.NoError(t, authErr)
+		assert.Equal(t, tc.status, authCli.Response().StatusCode)
+		assert.NoError(t, authCli.Close())
+	}
+
+	// graceful close on stop
+	app.RequireStop()
+
+	_, err = cli.Next(time.Second)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.NoError(t, cli.Close())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "sse stream closed",
+	})
+
+	tracetest.AssertHasTraceSpan(
+		t,
+		traceExporter,
+		"sse span",
+		attribute.String(httpserver.TraceSpanAttributeHttpRequestId, testRequestId),
+	)
+
+	expectedMetric = `
+		# HELP http_server_stream_connections Number of open HTTP streams connections
+		# TYPE http_server_stream_connections gauge
+		http_server_stream_connections{path="/sse",protocol="sse"} 0
+		http_server_stream_connections{path="/sse/admin",protocol="sse"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_stream_connections",
+	)
+	assert.NoError(t, err)
+
+	// rejected after stop
+	cli, err = srv.ConnectSSE(context.Background(), "/sse", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, cli.Response().StatusCode)
+	assert.NoError(t, cli.Close())
+}
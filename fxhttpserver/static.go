@@ -0,0 +1,292 @@
+package fxhttpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultStaticIndex           = "index.html"
+	DefaultStaticImmutableMaxAge = 365 * 24 * time.Hour
+	staticCacheControlNoCache    = "no-cache"
+)
+
+// DefaultStaticFingerprintPattern matches the fingerprinted (content hashed) assets names, like app.3f2a9c1b.js.
+var DefaultStaticFingerprintPattern = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[0-9A-Za-z]+$`)
+
+// staticPrecompressedExtensions are the precompressed sidecar files extensions, by encoding.
+var staticPrecompressedExtensions = map[string]string{
+	CompressionEncodingBrotli: ".br",
+	CompressionEncodingGzip:   ".gz",
+}
+
+// StaticConfig is the configuration for the [StaticHandlerWithConfig].
+type StaticConfig struct {
+	Filesystem           fs.FS
+	Index                string
+	SPA                  bool
+	MaxAge               time.Duration
+	ImmutableMaxAge      time.Duration
+	FingerprintPattern   *regexp.Regexp
+	DisablePrecompressed bool
+}
+
+// StaticHandler returns a handler serving the static assets of a provided [fs.FS], with default configuration.
+func StaticHandler(fsys fs.FS) echo.HandlerFunc {
+	return StaticHandlerWithConfig(StaticConfig{
+		Filesystem: fsys,
+	})
+}
+
+// StaticHandlerWithConfig returns a handler serving the static assets of a filesystem (an [embed.FS], or a directory
+// with [os.DirFS]), for a provided [StaticConfig].
+//
+// The requested file is resolved from the route wildcard parameter, and served with range and conditional requests
+// support. The fingerprinted assets get long immutable cache headers, the other ones are cached for the configured max
+// age (or revalidated if 0). If available and accepted, the precompressed .br or .gz sidecar files are served instead.
+// In SPA mode, the not found HTML requests fall back to the index file.
+//
+// [embed.FS]: https://pkg.go.dev/embed
+// [os.DirFS]: https://pkg.go.dev/os#DirFS
+func StaticHandlerWithConfig(config StaticConfig) echo.HandlerFunc {
+	if config.Filesystem == nil {
+		panic("static handler requires a filesystem")
+	}
+
+	if config.Index == "" {
+		config.Index = DefaultStaticIndex
+	}
+
+	if config.ImmutableMaxAge <= 0 {
+		config.ImmutableMaxAge = DefaultStaticImmutableMaxAge
+	}
+
+	if config.FingerprintPattern == nil {
+		config.FingerprintPattern = DefaultStaticFingerprintPattern
+	}
+
+	server := &staticServer{
+		config: config,
+	}
+
+	return server.serve
+}
+
+type staticServer struct {
+	config StaticConfig
+	etags  sync.Map
+}
+
+type staticFile struct {
+	name    string
+	content io.ReadSeeker
+	info    fs.FileInfo
+	closer  io.Closer
+}
+
+func (s *staticServer) serve(c echo.Context) error {
+	req := c.Request()
+
+	name := strings.TrimPrefix(path.Clean("/"+c.Param("*")), "/")
+	if name == "" {
+		name = "."
+	}
+
+	file, err := s.open(name)
+	if errors.Is(err, fs.ErrNotExist) && s.config.SPA && s.acceptsFallback(req, name) {
+		file, err = s.open(s.config.Index)
+	}
+
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return echo.ErrNotFound
+		}
+
+		return fmt.Errorf("cannot open static file %s: %w", name, err)
+	}
+
+	//nolint:errcheck
+	defer file.closer.Close()
+
+	resp := c.Response()
+
+	resp.Header().Set(echo.HeaderCacheControl, s.cacheControl(file.name))
+
+	if !s.config.DisablePrecompressed {
+		resp.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+		if precompressed, encoding := s.openPrecompressed(req, file.name); precompressed != nil {
+			//nolint:errcheck
+			defer precompressed.closer.Close()
+
+			resp.Header().Set(echo.HeaderContentEncoding, encoding)
+
+			// the content type is detected from the original file name
+			file.content = precompressed.content
+			file.info = precompressed.info
+		}
+	}
+
+	etag, err := s.etag(file)
+	if err != nil {
+		return fmt.Errorf("cannot compute static file %s etag: %w", file.name, err)
+	}
+
+	resp.Header().Set(HeaderETag, etag)
+
+	http.ServeContent(resp, req, path.Base(file.name), file.info.ModTime(), file.content)
+
+	return nil
+}
+
+// open opens a file, or the index file of a directory.
+func (s *staticServer) open(name string) (*staticFile, error) {
+	file, err := s.openFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !file.info.IsDir() {
+		return file, nil
+	}
+
+	//nolint:errcheck
+	file.closer.Close()
+
+	return s.openFile(path.Join(name, s.config.Index))
+}
+
+func (s *staticServer) openFile(name string) (*staticFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, fs.ErrNotExist
+	}
+
+	file, err := s.config.Filesystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		//nolint:errcheck
+		file.Close()
+
+		return nil, err
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok && !info.IsDir() {
+		data, readErr := io.ReadAll(file)
+		if readErr != nil {
+			//nolint:errcheck
+			file.Close()
+
+			return nil, readErr
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	return &staticFile{
+		name:    name,
+		content: content,
+		info:    info,
+		closer:  file,
+	}, nil
+}
+
+// openPrecompressed opens the first accepted and available precompressed sidecar file of a provided file if any, and
+// returns its encoding.
+func (s *staticServer) openPrecompressed(req *http.Request, name string) (*staticFile, string) {
+	acceptEncoding := req.Header.Get(echo.HeaderAcceptEncoding)
+
+	for _, encoding := range []string{CompressionEncodingBrotli, CompressionEncodingGzip} {
+		if NegotiateCompressionEncoding(acceptEncoding, []string{encoding}) == "" {
+			continue
+		}
+
+		file, err := s.openFile(name + staticPrecompressedExtensions[encoding])
+		if err != nil {
+			continue
+		}
+
+		if file.info.IsDir() {
+			//nolint:errcheck
+			file.closer.Close()
+
+			continue
+		}
+
+		return file, encoding
+	}
+
+	return nil, ""
+}
+
+// acceptsFallback returns true if the request accepts the SPA index file fallback: HTML requests, or paths without
+// extension (client side routes).
+func (s *staticServer) acceptsFallback(req *http.Request, name string) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	return path.Ext(name) == "" || strings.Contains(req.Header.Get(echo.HeaderAccept), echo.MIMETextHTML)
+}
+
+func (s *staticServer) cacheControl(name string) string {
+	if path.Base(name) == s.config.Index {
+		return staticCacheControlNoCache
+	}
+
+	if s.config.FingerprintPattern.MatchString(path.Base(name)) {
+		return "public, max-age=" + strconv.Itoa(int(s.config.ImmutableMaxAge.Seconds())) + ", immutable"
+	}
+
+	if s.config.MaxAge > 0 {
+		return "public, max-age=" + strconv.Itoa(int(s.config.MaxAge.Seconds()))
+	}
+
+	return staticCacheControlNoCache
+}
+
+// etag returns the strong ETag of a file content, cached by name, size and modification time.
+func (s *staticServer) etag(file *staticFile) (string, error) {
+	key := fmt.Sprintf("%s:%s:%d:%d", file.name, file.info.Name(), file.info.Size(), file.info.ModTime().UnixNano())
+
+	if etag, ok := s.etags.Load(key); ok {
+		//nolint:forcetypeassert
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+
+	_, err := io.Copy(hash, file.content)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.content.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+	s.etags.Store(key, etag)
+
+	return etag, nil
+}
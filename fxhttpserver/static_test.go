@@ -0,0 +1,174 @@
+package fxhttpserver_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/assets"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithStatic(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	public, err := fs.Sub(assets.Public, "public")
+	assert.NoError(t, err)
+
+	asset, err := os.ReadFile("testdata/assets/public/app.3f2a9c1b.js")
+	assert.NoError(t, err)
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsStaticWithConfig("/app", fxhttpserver.StaticConfig{
+			Filesystem: public,
+			SPA:        true,
+			MaxAge:     time.Hour,
+		}),
+		fxhttpserver.AsStatic("/static", os.DirFS("testdata/assets/public")),
+		fxhttpserver.AsStatic("/private", public, fxhttpserver.NewFxBasicAuthMiddleware),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	serve := func(method string, target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// index
+	for _, target := range []string{"/app", "/app/", "/app/index.html"} {
+		rec := serve(http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<html><body>index</body></html>\n", rec.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "no-cache", rec.Header().Get(echo.HeaderCacheControl))
+	}
+
+	// directory index
+	rec := serve(http.MethodGet, "/app/docs", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<html><body>docs</body></html>\n", rec.Body.String())
+
+	// fingerprinted asset
+	rec = serve(http.MethodGet, "/app/app.3f2a9c1b.js", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, string(asset), rec.Body.String())
+	assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(t, echo.HeaderAcceptEncoding, rec.Header().Get(echo.HeaderVary))
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+
+	etag := rec.Header().Get(fxhttpserver.HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	// not fingerprinted asset
+	rec = serve(http.MethodGet, "/app/robots.txt", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get(echo.HeaderCacheControl))
+
+	// conditional request
+	rec = serve(http.MethodGet, "/app/app.3f2a9c1b.js", map[string]string{fxhttpserver.HeaderIfNoneMatch: etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// precompressed sidecar files
+	for acceptEncoding, expectedEncoding := range map[string]string{
+		"gzip":           fxhttpserver.CompressionEncodingGzip,
+		"gzip, br":       fxhttpserver.CompressionEncodingBrotli,
+		"br;q=0, gzip":   fxhttpserver.CompressionEncodingGzip,
+		"zstd, identity": "",
+	} {
+		rec = serve(http.MethodGet, "/app/app.3f2a9c1b.js", map[string]string{echo.HeaderAcceptEncoding: acceptEncoding})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, expectedEncoding, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+
+		if expectedEncoding == "" {
+			assert.Equal(t, string(asset), rec.Body.String())
+		} else {
+			assert.NotEqual(t, etag, rec.Header().Get(fxhttpserver.HeaderETag))
+			assert.Equal(t, string(asset), decompress(t, expectedEncoding, rec.Body.Bytes()))
+		}
+	}
+
+	// range request
+	rec = serve(http.MethodGet, "/app/app.3f2a9c1b.js", map[string]string{"Range": "bytes=0-10"})
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, string(asset[:11]), rec.Body.String())
+	assert.Equal(t, "bytes 0-10/577", rec.Header().Get("Content-Range"))
+
+	// head request
+	rec = serve(http.MethodHead, "/app/robots.txt", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "14", rec.Header().Get(echo.HeaderContentLength))
+	assert.Empty(t, rec.Body.String())
+
+	// spa fallback
+	for target, headers := range map[string]map[string]string{
+		"/app/users/123":      nil,
+		"/app/users/john.doe": {echo.HeaderAccept: "text/html,application/xhtml+xml"},
+	} {
+		rec = serve(http.MethodGet, target, headers)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<html><body>index</body></html>\n", rec.Body.String())
+		assert.Equal(t, "no-cache", rec.Header().Get(echo.HeaderCacheControl))
+	}
+
+	// not found
+	for _, target := range []string{"/app/missing.js", "/app/../../go.mod", "/static/missing"} {
+		rec = serve(http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	// directory
+	rec = serve(http.MethodGet, "/static/robots.txt", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "User-agent: *\n", rec.Body.String())
+	assert.Equal(t, "no-cache", rec.Header().Get(echo.HeaderCacheControl))
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderLastModified))
+
+	// middlewares
+	for _, target := range []string{"/private", "/private/robots.txt"} {
+		rec = serve(http.MethodGet, target, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetBasicAuth("admin", "admin-password")
+		rec = httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
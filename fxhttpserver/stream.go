@@ -0,0 +1,201 @@
+package fxhttpserver
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+const (
+	StreamProtocolSSE               = "sse"
+	StreamProtocolWebSocket         = "websocket"
+	StreamDirectionSent             = "sent"
+	StreamDirectionReceived         = "received"
+	StreamMetricsConnections        = "http_server_stream_connections"
+	StreamMetricsMessagesCount      = "http_server_stream_messages_total"
+	DefaultStreamHeartbeat          = 15 * time.Second
+	DefaultStreamWriteTimeout       = 10 * time.Second
+	DefaultStreamReadLimit          = 1 << 20
+	streamAllowAnyOrigin            = "*"
+	streamWebSocketCloseGracePeriod = time.Second
+)
+
+// StreamManagerConfig is the configuration of the [StreamManager].
+type StreamManagerConfig struct {
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+	ReadLimit    int64
+	Origins      []string
+	Registry     prometheus.Registerer
+	Namespace    string
+	Subsystem    string
+}
+
+// StreamManager manages the Server-Sent Events and WebSocket connections lifecycle: heartbeats, writes timeouts,
+// metrics and graceful close on shutdown.
+type StreamManager struct {
+	config      StreamManagerConfig
+	ctx         context.Context
+	cancel      context.CancelFunc
+	mutex       sync.Mutex
+	wg          sync.WaitGroup
+	upgrader    websocket.Upgrader
+	connections *prometheus.GaugeVec
+	messages    *prometheus.CounterVec
+}
+
+// FxStreamManagerParam allows injection of the required dependencies in [NewFxStreamManager].
+type FxStreamManagerParam struct {
+	fx.In
+	Config          *config.Config
+	MetricsRegistry *prometheus.Registry
+}
+
+// NewFxStreamManager returns a new [StreamManager], configured from modules.http.server.streams.
+func NewFxStreamManager(p FxStreamManagerParam) *StreamManager {
+	return NewStreamManager(StreamManagerConfig{
+		Heartbeat:    time.Duration(p.Config.GetInt("modules.http.server.streams.heartbeat")) * time.Second,
+		WriteTimeout: time.Duration(p.Config.GetInt("modules.http.server.streams.write_timeout")) * time.Second,
+		ReadLimit:    p.Config.GetInt64("modules.http.server.streams.read_limit"),
+		Origins:      p.Config.GetStringSlice("modules.http.server.streams.origins"),
+		Registry:     p.MetricsRegistry,
+		Namespace:    Sanitize(p.Config.GetString("modules.http.server.metrics.collect.namespace")),
+		Subsystem:    Sanitize(p.Config.GetString("modules.http.server.metrics.collect.subsystem")),
+	})
+}
+
+// NewStreamManager returns a new [StreamManager], for a provided [StreamManagerConfig].
+func NewStreamManager(config StreamManagerConfig) *StreamManager {
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = DefaultStreamHeartbeat
+	}
+
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = DefaultStreamWriteTimeout
+	}
+
+	if config.ReadLimit <= 0 {
+		config.ReadLimit = DefaultStreamReadLimit
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	connections := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      StreamMetricsConnections,
+			Help:      "Number of open HTTP streams connections",
+		},
+		[]string{
+			"protocol",
+			"path",
+		},
+	)
+
+	messages := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      StreamMetricsMessagesCount,
+			Help:      "Number of HTTP streams messages sent and received",
+		},
+		[]string{
+			"protocol",
+			"direction",
+			"path",
+		},
+	)
+
+	config.Registry.MustRegister(connections, messages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	manager := &StreamManager{
+		config:      config,
+		ctx:         ctx,
+		cancel:      cancel,
+		connections: connections,
+		messages:    messages,
+	}
+
+	manager.upgrader = websocket.Upgrader{
+		CheckOrigin: manager.checkOrigin,
+	}
+
+	return manager
+}
+
+// Shutdown closes gracefully the open connections, and waits for their handlers to return, or for the context to be done.
+func (m *StreamManager) Shutdown(ctx context.Context) error {
+	m.mutex.Lock()
+	m.cancel()
+	m.mutex.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// open registers a new connection, and returns its context, canceled on shutdown, and its release function.
+func (m *StreamManager) open(ctx context.Context, protocol string, path string) (context.Context, func(), bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.ctx.Err() != nil {
+		return nil, nil, false
+	}
+
+	m.wg.Add(1)
+
+	connCtx, connCancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(m.ctx, connCancel)
+
+	m.connections.WithLabelValues(protocol, path).Inc()
+
+	return connCtx, func() {
+		stop()
+		connCancel()
+
+		m.connections.WithLabelValues(protocol, path).Dec()
+		m.wg.Done()
+	}, true
+}
+
+func (m *StreamManager) countMessage(protocol string, direction string, path string) {
+	m.messages.WithLabelValues(protocol, direction, path).Inc()
+}
+
+func (m *StreamManager) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+
+	// non browser clients do not send origins
+	if origin == "" {
+		return true
+	}
+
+	if len(m.config.Origins) == 0 {
+		return origin == "http://"+r.Host || origin == "https://"+r.Host
+	}
+
+	return slices.Contains(m.config.Origins, streamAllowAnyOrigin) || slices.Contains(m.config.Origins, origin)
+}
@@ -0,0 +1,9 @@
+package assets
+
+import "embed"
+
+//go:embed public
+var Public embed.FS
+
+//go:embed templates
+var Templates embed.FS
@@ -0,0 +1 @@
+console.log("fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content fingerprinted asset content ");
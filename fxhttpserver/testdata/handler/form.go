@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/labstack/echo/v4"
+)
+
+type TestFormHandler struct{}
+
+func NewTestFormHandler() *TestFormHandler {
+	return &TestFormHandler{}
+}
+
+func (h *TestFormHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodPost {
+			return c.String(http.StatusOK, "submitted")
+		}
+
+		return c.Render(http.StatusOK, "form.html", map[string]interface{}{
+			"csrf": fxhttpserver.CSRFToken(c),
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/labstack/echo/v4"
+)
+
+type TestAddress struct {
+	City    string `json:"city" validate:"required"`
+	Country string `json:"country" validate:"len=2"`
+}
+
+type TestUser struct {
+	Id        int          `json:"id"`
+	Name      string       `json:"name" validate:"required,min=2,max=50"`
+	Email     string       `json:"email,omitempty" validate:"email"`
+	Role      string       `json:"role" validate:"oneof=admin user"`
+	Age       int          `json:"age" validate:"gte=18,lt=150"`
+	Tags      []string     `json:"tags" validate:"max=5"`
+	Address   *TestAddress `json:"address"`
+	Friends   []*TestUser  `json:"friends"`
+	CreatedAt time.Time    `json:"createdAt"`
+	internal  string
+}
+
+type TestUpdateUserRequest struct {
+	Id      int          `param:"id"`
+	DryRun  bool         `query:"dryRun"`
+	Tenant  string       `header:"X-Tenant" validate:"required"`
+	Name    string       `json:"name" validate:"required"`
+	Address *TestAddress `json:"address"`
+}
+
+type TestOpenApiHandler struct{}
+
+func NewTestOpenApiHandler() *TestOpenApiHandler {
+	return &TestOpenApiHandler{}
+}
+
+func (h *TestOpenApiHandler) OpenApi() *fxhttpserver.OpenApiOperation {
+	return &fxhttpserver.OpenApiOperation{
+		OperationId: "updateUser",
+		Summary:     "Update a user",
+		Tags:        []string{"users"},
+		Request:     TestUpdateUserRequest{},
+		Responses: map[int]any{
+			http.StatusOK:        TestUser{},
+			http.StatusNoContent: nil,
+		},
+	}
+}
+
+func (h *TestOpenApiHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, TestUser{Id: 1, Name: "test"})
+	}
+}
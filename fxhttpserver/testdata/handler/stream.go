@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+)
+
+type TestSSEHandler struct {
+	service *service.TestService
+}
+
+func NewTestSSEHandler(service *service.TestService) *TestSSEHandler {
+	return &TestSSEHandler{
+		service: service,
+	}
+}
+
+func (h *TestSSEHandler) Stream(c echo.Context, stream *fxhttpserver.SSEStream) error {
+	ctx, span := httpserver.CtxTracer(c).Start(stream.Context(), "sse span")
+	defer span.End()
+
+	log.CtxLogger(ctx).Info().Msg("in sse handler")
+
+	err := stream.Send(fxhttpserver.SSEEvent{
+		Id:    "1",
+		Event: "greeting",
+		Data:  fmt.Sprintf("hello: %s", h.service.GetAppName()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.QueryParam("once") != "" {
+		return nil
+	}
+
+	<-ctx.Done()
+
+	log.CtxLogger(ctx).Info().Msg("sse stream closed")
+
+	return ctx.Err()
+}
+
+type TestWebSocketHandler struct {
+	service *service.TestService
+}
+
+func NewTestWebSocketHandler(service *service.TestService) *TestWebSocketHandler {
+	return &TestWebSocketHandler{
+		service: service,
+	}
+}
+
+func (h *TestWebSocketHandler) Serve(c echo.Context, conn *fxhttpserver.WebSocketConn) error {
+	ctx, span := httpserver.CtxTracer(c).Start(conn.Context(), "websocket span")
+	defer span.End()
+
+	log.CtxLogger(ctx).Info().Msg("in websocket handler")
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			log.CtxLogger(ctx).Info().Msg("websocket connection closed")
+
+			return err
+		}
+
+		err = conn.WriteMessage(messageType, []byte(fmt.Sprintf("%s: %s", h.service.GetAppName(), data)))
+		if err != nil {
+			return err
+		}
+	}
+}
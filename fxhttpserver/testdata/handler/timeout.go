@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TestTimeoutHandler struct{}
+
+func NewTestTimeoutHandler() *TestTimeoutHandler {
+	return &TestTimeoutHandler{}
+}
+
+func (h *TestTimeoutHandler) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		delay, err := time.ParseDuration(c.QueryParam("delay"))
+		if err != nil {
+			delay = time.Minute
+		}
+
+		select {
+		case <-time.After(delay):
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				return c.String(http.StatusOK, "no deadline")
+			}
+
+			return c.String(http.StatusOK, time.Until(deadline).Round(time.Second).String())
+		case <-ctx.Done():
+			c.Logger().Info("in timeout handler")
+
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TestTypedRequest struct {
+	Id     int    `param:"id"`
+	Notify bool   `query:"notify"`
+	Tenant string `header:"X-Tenant" validate:"required"`
+	Name   string `json:"name" xml:"name" validate:"required,min=2"`
+}
+
+type TestTypedResponse struct {
+	Id     int    `json:"id" xml:"id"`
+	Notify bool   `json:"notify" xml:"notify"`
+	Tenant string `json:"tenant" xml:"tenant"`
+	Name   string `json:"name" xml:"name"`
+}
+
+func TestTypedHandler(ctx context.Context, req TestTypedRequest) (*TestTypedResponse, error) {
+	if req.Name == "conflict" {
+		return nil, echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("user %d already exists", req.Id))
+	}
+
+	return &TestTypedResponse{
+		Id:     req.Id,
+		Notify: req.Notify,
+		Tenant: req.Tenant,
+		Name:   req.Name,
+	}, nil
+}
+
+type TestTypedDeleteRequest struct {
+	Id int `param:"id" validate:"min=1"`
+}
+
+func TestTypedDeleteHandler(ctx context.Context, req *TestTypedDeleteRequest) (any, error) {
+	return nil, nil
+}
@@ -0,0 +1,266 @@
+package fxhttpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+const (
+	HeaderRequestTimeout      = "X-Request-Timeout"
+	DefaultTimeoutStatus      = http.StatusServiceUnavailable
+	TimeoutMetricsCount       = "http_server_request_timeouts_total"
+	timeoutRulesGroupTag      = `group:"httpserver-timeout-rules"`
+	timeoutProblemDescription = "request timeout of %s exceeded"
+)
+
+// RouteTimeout is a route timeout, declared among the handlers registrations middlewares with [Timeout].
+type RouteTimeout struct {
+	Timeout time.Duration
+}
+
+// Timeout returns a [RouteTimeout], to declare among the handlers registrations middlewares.
+func Timeout(timeout time.Duration) RouteTimeout {
+	return RouteTimeout{
+		Timeout: timeout,
+	}
+}
+
+// TimeoutRule is a timeout rule, applying a timeout to the requests matching a method and a route path.
+// Empty method or path match any request, and the method can be a comma separated list or [AllMethods].
+type TimeoutRule struct {
+	Method  string
+	Path    string
+	Timeout time.Duration
+	server  string
+}
+
+func (r TimeoutRule) match(c echo.Context) bool {
+	if r.Path != "" && r.Path != c.Path() {
+		return false
+	}
+
+	if r.Method == "" || r.Method == AllMethods {
+		return true
+	}
+
+	return Contains(Split(strings.ToUpper(r.Method)), c.Request().Method)
+}
+
+// TimeoutMiddlewareConfig is the configuration for the [TimeoutMiddlewareWithConfig].
+type TimeoutMiddlewareConfig struct {
+	Skipper   middleware.Skipper
+	Rules     []TimeoutRule
+	Status    int
+	Propagate bool
+	Registry  prometheus.Registerer
+	Namespace string
+	Subsystem string
+}
+
+// TimeoutMiddlewareWithConfig returns a middleware applying timeouts to the requests, for a provided
+// [TimeoutMiddlewareConfig].
+//
+// The first rule matching the request sets the request context deadline, shortened by the incoming X-Request-Timeout
+// header (in milliseconds) if Propagate is enabled. If the deadline expires before a response is sent, a problem
+// response is sent with the configured status (503 by default), and the timeout is counted by method and path.
+// The streaming (Server-Sent Events and WebSocket) requests are not subject to timeouts.
+func TimeoutMiddlewareWithConfig(config TimeoutMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	if config.Status == 0 {
+		config.Status = DefaultTimeoutStatus
+	}
+
+	if config.Registry == nil {
+		config.Registry = prometheus.DefaultRegisterer
+	}
+
+	timeoutsCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: config.Subsystem,
+			Name:      TimeoutMetricsCount,
+			Help:      "Number of HTTP requests that exceeded their timeout",
+		},
+		[]string{
+			"method",
+			"path",
+		},
+	)
+
+	timeoutsCounter = transport.RegisterCollector(config.Registry, timeoutsCounter)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if config.Skipper(c) || c.IsWebSocket() || strings.Contains(req.Header.Get(echo.HeaderAccept), MIMETextEventStream) {
+				return next(c)
+			}
+
+			timeout := time.Duration(0)
+			for _, rule := range config.Rules {
+				if rule.match(c) {
+					timeout = rule.Timeout
+
+					break
+				}
+			}
+
+			if config.Propagate {
+				if requestTimeout, ok := parseRequestTimeout(req.Header.Get(HeaderRequestTimeout)); ok {
+					if timeout <= 0 || requestTimeout < timeout {
+						timeout = requestTimeout
+					}
+				}
+			}
+
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return err
+			}
+
+			timeoutsCounter.WithLabelValues(req.Method, c.Path()).Inc()
+
+			log.CtxLogger(ctx).Warn().Err(err).Str("timeout", timeout.String()).Msg("request timeout exceeded")
+
+			// the handler already responded, despite the timeout
+			if c.Response().Committed {
+				return err
+			}
+
+			return problemResponse(c, config.Status, fmt.Sprintf(timeoutProblemDescription, timeout))
+		}
+	}
+}
+
+// parseRequestTimeout parses a X-Request-Timeout header value, in milliseconds.
+func parseRequestTimeout(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// applyRegistrationOption sets the registration timeout, the last declared timeout wins.
+func (t RouteTimeout) applyRegistrationOption(options *registrationOptions) {
+	options.timeout = &t
+}
+
+// supplyTimeoutRule supplies a registration [TimeoutRule] into Fx, if a timeout was declared.
+func supplyTimeoutRule(server string, method string, path string, timeout *RouteTimeout) fx.Option {
+	if timeout == nil {
+		return fx.Options()
+	}
+
+	return fx.Supply(
+		fx.Annotate(
+			TimeoutRule{
+				Method:  method,
+				Path:    path,
+				Timeout: timeout.Timeout,
+				server:  server,
+			},
+			fx.ResultTags(timeoutRulesGroupTag),
+		),
+	)
+}
+
+// filterTimeoutRules returns the registrations [TimeoutRule] of a http server.
+func filterTimeoutRules(rules []TimeoutRule, server string) []TimeoutRule {
+	var filteredRules []TimeoutRule
+
+	for _, rule := range rules {
+		if rule.server == server {
+			filteredRules = append(filteredRules, rule)
+		}
+	}
+
+	return filteredRules
+}
+
+// timeoutRouteConfig is a timeout route configuration entry.
+type timeoutRouteConfig struct {
+	Method  string `mapstructure:"method"`
+	Path    string `mapstructure:"path"`
+	Timeout int    `mapstructure:"timeout"`
+}
+
+func withTimeoutMiddleware(httpServer *echo.Echo, p FxHttpServerParam, registerer prometheus.Registerer) (*echo.Echo, error) {
+	var routes []timeoutRouteConfig
+	if err := p.Config.UnmarshalKey("modules.http.server.handler_timeout.routes", &routes); err != nil {
+		return httpServer, fmt.Errorf("invalid handler timeout routes: %w", err)
+	}
+
+	// the configured routes take precedence over the registrations ones, and the default timeout comes last
+	rules := make([]TimeoutRule, 0, len(routes)+len(p.TimeoutRules)+1)
+	for _, route := range routes {
+		if route.Timeout <= 0 {
+			return httpServer, fmt.Errorf("invalid timeout %d for route %s, must be positive", route.Timeout, route.Path)
+		}
+
+		rules = append(rules, TimeoutRule{
+			Method:  route.Method,
+			Path:    route.Path,
+			Timeout: time.Duration(route.Timeout) * time.Second,
+		})
+	}
+
+	rules = append(rules, p.TimeoutRules...)
+
+	if handlerTimeout := p.Config.GetInt("modules.http.server.handler_timeout.default"); handlerTimeout > 0 {
+		rules = append(rules, TimeoutRule{Timeout: time.Duration(handlerTimeout) * time.Second})
+	}
+
+	propagate := p.Config.GetBool("modules.http.server.handler_timeout.propagate")
+
+	if len(rules) == 0 && !propagate {
+		return httpServer, nil
+	}
+
+	status := p.Config.GetInt("modules.http.server.handler_timeout.status")
+	if status != 0 && status != http.StatusServiceUnavailable && status != http.StatusGatewayTimeout {
+		return httpServer, fmt.Errorf("invalid handler timeout status %d, must be 503 or 504", status)
+	}
+
+	httpServer.Use(TimeoutMiddlewareWithConfig(TimeoutMiddlewareConfig{
+		Rules:     rules,
+		Status:    status,
+		Propagate: propagate,
+		Registry:  registerer,
+		Namespace: Sanitize(p.Config.GetString("modules.http.server.metrics.collect.namespace")),
+		Subsystem: Sanitize(p.Config.GetString("modules.http.server.metrics.collect.subsystem")),
+	}))
+
+	return httpServer, nil
+}
@@ -0,0 +1,233 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModuleWithTimeouts(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+	var metricsRegistry *prometheus.Registry
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/timeout/registered", handler.NewTestTimeoutHandler, fxhttpserver.Timeout(50*time.Millisecond)),
+		fxhttpserver.AsHandler("GET", "/timeout/configured", handler.NewTestTimeoutHandler, fxhttpserver.Timeout(time.Minute)),
+		fxhttpserver.AsHandler("GET", "/timeout/none", handler.NewTestTimeoutHandler),
+		fxhttpserver.AsHandlersGroup(
+			"/group",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration("GET", "/default", handler.NewTestTimeoutHandler),
+				fxhttpserver.NewHandlerRegistration("GET", "/own", handler.NewTestTimeoutHandler, fxhttpserver.Timeout(2*time.Second)),
+			},
+			fxhttpserver.Timeout(50*time.Millisecond),
+		),
+		fx.Populate(&httpServer, &logBuffer, &metricsRegistry),
+	).RequireStart().RequireStop()
+
+	serve := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// registration and group timeouts
+	for _, target := range []string{"/timeout/registered", "/group/default"} {
+		rec := serve(target, nil)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+		var problem map[string]any
+		err := json.Unmarshal(rec.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		assert.Equal(t, "Service Unavailable", problem["title"])
+		assert.Equal(t, "request timeout of 50ms exceeded", problem["detail"])
+		assert.Equal(t, target, problem["instance"])
+	}
+
+	// deadlines set before the timeout
+	for target, expected := range map[string]string{
+		"/timeout/registered?delay=1ms": "0s",
+		"/group/own?delay=1ms":          "2s",
+		"/timeout/configured?delay=1ms": "1s",
+		"/timeout/none?delay=1ms":       "no deadline",
+	} {
+		rec := serve(target, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, expected, rec.Body.String())
+	}
+
+	// request timeout header ignored without propagation
+	rec := serve("/timeout/none?delay=1ms", map[string]string{fxhttpserver.HeaderRequestTimeout: "50"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no deadline", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"service": "test",
+		"timeout": "50ms",
+		"message": "request timeout exceeded",
+	})
+
+	expectedMetric := `
+		# HELP http_server_request_timeouts_total Number of HTTP requests that exceeded their timeout
+		# TYPE http_server_request_timeouts_total counter
+		http_server_request_timeouts_total{method="GET",path="/group/default"} 1
+		http_server_request_timeouts_total{method="GET",path="/timeout/registered"} 1
+	`
+	err := testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_request_timeouts_total",
+	)
+	assert.NoError(t, err)
+}
+
+func TestModuleWithTimeoutsFromConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SERVER_HANDLER_TIMEOUT_DEFAULT", "3")
+	t.Setenv("SERVER_HANDLER_TIMEOUT_STATUS", "504")
+	t.Setenv("SERVER_HANDLER_TIMEOUT_PROPAGATE", "true")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("GET", "/timeout/none", handler.NewTestTimeoutHandler),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	// default handler timeout
+	req := httptest.NewRequest(http.MethodGet, "/timeout/none?delay=1ms", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3s", rec.Body.String())
+
+	// propagated request timeout
+	req = httptest.NewRequest(http.MethodGet, "/timeout/none", nil)
+	req.Header.Set(fxhttpserver.HeaderRequestTimeout, "50")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request timeout of 50ms exceeded")
+}
+
+func TestModuleWithInvalidTimeoutsConfig(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("SERVER_HANDLER_TIMEOUT_STATUS", "500")
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Invoke(func(*echo.Echo) {}),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid handler timeout status 500, must be 503 or 504")
+}
+
+func TestTimeoutMiddlewareWithConfig(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.Use(fxhttpserver.TimeoutMiddlewareWithConfig(fxhttpserver.TimeoutMiddlewareConfig{
+		Rules: []fxhttpserver.TimeoutRule{
+			{Method: "POST", Path: "/test", Timeout: 50 * time.Millisecond},
+		},
+		Registry: prometheus.NewRegistry(),
+	}))
+
+	committed := false
+	httpServer.Any("/test", func(c echo.Context) error {
+		if c.QueryParam("commit") != "" {
+			err := c.String(http.StatusOK, "committed")
+			<-c.Request().Context().Done()
+			committed = true
+
+			return err
+		}
+
+		<-c.Request().Context().Done()
+
+		return c.Request().Context().Err()
+	})
+
+	// matching rule
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// already committed response
+	req = httptest.NewRequest(http.MethodPost, "/test?commit=true", nil)
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.True(t, committed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "committed", rec.Body.String())
+
+	// not matching rule
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+	req.Header.Set(fxhttpserver.HeaderRequestTimeout, "10")
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
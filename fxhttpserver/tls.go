@@ -0,0 +1,325 @@
+package fxhttpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultTlsMinVersion     = "1.2"
+	DefaultTlsReloadInterval = 60
+	LogFieldClientIdentity   = "clientIdentity"
+)
+
+// CtxClientCertificateKey is a contextual struct key.
+type CtxClientCertificateKey struct{}
+
+// CtxClientCertificate returns the contextual verified client [x509.Certificate], if any.
+func CtxClientCertificate(c echo.Context) *x509.Certificate {
+	if cert, ok := c.Request().Context().Value(CtxClientCertificateKey{}).(*x509.Certificate); ok {
+		return cert
+	}
+
+	return nil
+}
+
+// CtxClientIdentity returns the contextual verified client certificate identity (subject common name), if any.
+func CtxClientIdentity(c echo.Context) string {
+	if cert := CtxClientCertificate(c); cert != nil {
+		return cert.Subject.CommonName
+	}
+
+	return ""
+}
+
+// TlsClientCertificateMiddleware is a middleware exposing the verified client certificate on the request context,
+// and adding its identity to the contextual logger.
+func TlsClientCertificateMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+				return next(c)
+			}
+
+			cert := req.TLS.VerifiedChains[0][0]
+
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), CtxClientCertificateKey{}, cert)))
+
+			if echoLogger, ok := c.Logger().(*httpserver.EchoLogger); ok {
+				c.SetLogger(httpserver.NewEchoLogger(log.FromZerolog(
+					echoLogger.ToZerolog().With().Str(LogFieldClientIdentity, cert.Subject.CommonName).Logger(),
+				)))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// TlsCertificateReloader loads TLS certificate, key and client CA files, and reloads them when they change on disk.
+type TlsCertificateReloader struct {
+	mutex        sync.RWMutex
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	certificate  *tls.Certificate
+	clientCAs    *x509.CertPool
+	modTimes     map[string]time.Time
+}
+
+// NewTlsCertificateReloader returns a new [TlsCertificateReloader], with files initially loaded.
+func NewTlsCertificateReloader(certFile string, keyFile string, clientCAFile string) (*TlsCertificateReloader, error) {
+	reloader := &TlsCertificateReloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     map[string]time.Time{},
+	}
+
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Certificate returns the currently loaded server certificate.
+func (r *TlsCertificateReloader) Certificate() *tls.Certificate {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.certificate
+}
+
+// ClientCAs returns the currently loaded client CAs pool, nil if no client CA file is configured.
+func (r *TlsCertificateReloader) ClientCAs() *x509.CertPool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.clientCAs
+}
+
+// GetCertificate returns the currently loaded server certificate, to be used as [tls.Config] GetCertificate.
+func (r *TlsCertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// Reload reloads the files if they changed on disk since the last load, and returns true if a reload happened.
+func (r *TlsCertificateReloader) Reload() (bool, error) {
+	changed := false
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, fmt.Errorf("cannot stat tls file %s: %w", file, err)
+		}
+
+		r.mutex.RLock()
+		modTime, ok := r.modTimes[file]
+		r.mutex.RUnlock()
+
+		if !ok || !info.ModTime().Equal(modTime) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	if err := r.load(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Watch checks for files changes on the provided interval, until the provided context is canceled.
+func (r *TlsCertificateReloader) Watch(ctx context.Context, interval time.Duration, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logger.Error().Err(err).Str("module", ModuleName).Msg("tls certificate reload failure")
+			} else if reloaded {
+				logger.Info().Str("module", ModuleName).Msg("tls certificate reloaded")
+			}
+		}
+	}
+}
+
+func (r *TlsCertificateReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+
+	return files
+}
+
+func (r *TlsCertificateReloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("cannot stat tls file %s: %w", file, err)
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load tls certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("cannot read tls client ca: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("cannot parse tls client ca %s", r.clientCAFile)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// NewTlsConfig returns a new [tls.Config] from the modules.http.server.tls.* configuration, backed by the provided [TlsCertificateReloader].
+func NewTlsConfig(cfg *config.Config, reloader *TlsCertificateReloader) (*tls.Config, error) {
+	minVersion, err := ResolveTlsVersion(cfg.GetString("modules.http.server.tls.min_version"))
+	if err != nil {
+		return nil, err
+	}
+
+	cipherSuites, err := ResolveTlsCipherSuites(cfg.GetStringSlice("modules.http.server.tls.ciphers"))
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := ResolveTlsClientAuth(
+		cfg.GetString("modules.http.server.tls.client_auth"),
+		cfg.GetString("modules.http.server.tls.client_ca") != "",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		ClientAuth:     clientAuth,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	// client CAs are resolved per connection, to follow reloads
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientConfig := tlsConfig.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = reloader.ClientCAs()
+
+		return clientConfig, nil
+	}
+
+	return tlsConfig, nil
+}
+
+// ResolveTlsVersion resolves a TLS version from its name (1.0, 1.1, 1.2, 1.3), defaults to [DefaultTlsMinVersion].
+func ResolveTlsVersion(version string) (uint16, error) {
+	if version == "" {
+		version = DefaultTlsMinVersion
+	}
+
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid tls version %s", version)
+	}
+}
+
+// ResolveTlsCipherSuites resolves TLS cipher suites ids from their names, nil to use Go defaults.
+func ResolveTlsCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	available := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range names {
+		id, ok := available[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls cipher suite %s", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ResolveTlsClientAuth resolves a [tls.ClientAuthType] from its name
+// (none, request, require, verify_if_given, require_and_verify).
+// If empty, defaults to require_and_verify if a client CA is configured, none otherwise.
+func ResolveTlsClientAuth(clientAuth string, hasClientCA bool) (tls.ClientAuthType, error) {
+	switch strings.ToLower(clientAuth) {
+	case "":
+		if hasClientCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+
+		return tls.NoClientCert, nil
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("invalid tls client auth %s", clientAuth)
+	}
+}
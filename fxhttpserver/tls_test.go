@@ -0,0 +1,398 @@
+package fxhttpserver_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testTlsFiles struct {
+	ca         *x509.Certificate
+	caKey      *ecdsa.PrivateKey
+	caFile     string
+	certFile   string
+	keyFile    string
+	clientCert tls.Certificate
+}
+
+func writePem(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	require.NoError(t, err)
+}
+
+func signCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string, serial int64, client bool) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	return der, key
+}
+
+func writeServerCertificate(t *testing.T, files *testTlsFiles, commonName string, serial int64) {
+	t.Helper()
+
+	der, key := signCertificate(t, files.ca, files.caKey, commonName, serial, false)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writePem(t, files.certFile, "CERTIFICATE", der)
+	writePem(t, files.keyFile, "EC PRIVATE KEY", keyDer)
+}
+
+func prepareTlsFiles(t *testing.T) *testTlsFiles {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDer)
+	require.NoError(t, err)
+
+	files := &testTlsFiles{
+		ca:       ca,
+		caKey:    caKey,
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "cert.pem"),
+		keyFile:  filepath.Join(dir, "key.pem"),
+	}
+
+	writePem(t, files.caFile, "CERTIFICATE", caDer)
+	writeServerCertificate(t, files, "server-1", 2)
+
+	clientDer, clientKey := signCertificate(t, ca, caKey, "test-client", 3, true)
+	files.clientCert = tls.Certificate{Certificate: [][]byte{clientDer}, PrivateKey: clientKey}
+
+	return files
+}
+
+func TestTlsCertificateReloader(t *testing.T) {
+	t.Parallel()
+
+	files := prepareTlsFiles(t)
+
+	reloader, err := fxhttpserver.NewTlsCertificateReloader(files.certFile, files.keyFile, files.caFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, reloader.ClientCAs())
+
+	leaf, err := x509.ParseCertificate(reloader.Certificate().Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "server-1", leaf.Subject.CommonName)
+
+	reloaded, err := reloader.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// rotation
+	writeServerCertificate(t, files, "server-2", 4)
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(files.certFile, future, future))
+	assert.NoError(t, os.Chtimes(files.keyFile, future, future))
+
+	reloaded, err = reloader.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "server-2", leaf.Subject.CommonName)
+}
+
+func TestTlsCertificateReloaderWithInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	files := prepareTlsFiles(t)
+
+	_, err := fxhttpserver.NewTlsCertificateReloader("invalid.pem", files.keyFile, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot stat tls file invalid.pem")
+
+	_, err = fxhttpserver.NewTlsCertificateReloader(files.certFile, files.caFile, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot load tls certificate")
+
+	_, err = fxhttpserver.NewTlsCertificateReloader(files.certFile, files.keyFile, files.keyFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse tls client ca")
+}
+
+func TestResolveTlsVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version  string
+		expected uint16
+		err      bool
+	}{
+		{"", tls.VersionTLS12, false},
+		{"1.0", tls.VersionTLS10, false},
+		{"1.1", tls.VersionTLS11, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"TLS1.3", tls.VersionTLS13, false},
+		{"invalid", 0, true},
+	}
+
+	for _, test := range tests {
+		version, err := fxhttpserver.ResolveTlsVersion(test.version)
+
+		assert.Equal(t, test.expected, version)
+		assert.Equal(t, test.err, err != nil)
+	}
+}
+
+func TestResolveTlsCipherSuites(t *testing.T) {
+	t.Parallel()
+
+	ids, err := fxhttpserver.ResolveTlsCipherSuites(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, ids)
+
+	ids, err = fxhttpserver.ResolveTlsCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", " tls_ecdhe_rsa_with_aes_256_gcm_sha384 "})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, ids)
+
+	_, err = fxhttpserver.ResolveTlsCipherSuites([]string{"invalid"})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid tls cipher suite invalid", err.Error())
+}
+
+func TestResolveTlsClientAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		clientAuth  string
+		hasClientCA bool
+		expected    tls.ClientAuthType
+		err         bool
+	}{
+		{"", false, tls.NoClientCert, false},
+		{"", true, tls.RequireAndVerifyClientCert, false},
+		{"none", true, tls.NoClientCert, false},
+		{"request", false, tls.RequestClientCert, false},
+		{"require", false, tls.RequireAnyClientCert, false},
+		{"verify_if_given", true, tls.VerifyClientCertIfGiven, false},
+		{"require_and_verify", true, tls.RequireAndVerifyClientCert, false},
+		{"invalid", true, tls.NoClientCert, true},
+	}
+
+	for _, test := range tests {
+		clientAuth, err := fxhttpserver.ResolveTlsClientAuth(test.clientAuth, test.hasClientCA)
+
+		assert.Equal(t, test.expected, clientAuth)
+		assert.Equal(t, test.err, err != nil)
+	}
+}
+
+func TestTlsConfigWithMutualTls(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	files := prepareTlsFiles(t)
+	t.Setenv("TLS_CLIENT_CA", files.caFile)
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("./testdata/config"))
+	assert.NoError(t, err)
+
+	reloader, err := fxhttpserver.NewTlsCertificateReloader(files.certFile, files.keyFile, files.caFile)
+	assert.NoError(t, err)
+
+	tlsConfig, err := fxhttpserver.NewTlsConfig(cfg, reloader)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+	e := echo.New()
+	e.Use(fxhttpserver.TlsClientCertificateMiddleware())
+	e.GET("/identity", func(c echo.Context) error {
+		return c.String(http.StatusOK, fxhttpserver.CtxClientIdentity(c))
+	})
+
+	server := httptest.NewUnstartedServer(e)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(files.ca)
+
+	// with client certificate
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      rootCAs,
+				Certificates: []tls.Certificate{files.clientCert},
+				MinVersion:   tls.VersionTLS12,
+			},
+		},
+	}
+
+	resp, err := client.Get(server.URL + "/identity")
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "test-client", string(body))
+
+	// without client certificate
+	client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    rootCAs,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+
+	//nolint:bodyclose
+	_, err = client.Get(server.URL + "/identity")
+	assert.Error(t, err)
+}
+
+func TestModuleWithTls(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	files := prepareTlsFiles(t)
+	t.Setenv("TLS_ENABLED", "true")
+	t.Setenv("TLS_CERT", files.certFile)
+	t.Setenv("TLS_KEY", files.keyFile)
+	t.Setenv("TLS_CLIENT_CA", files.caFile)
+
+	var httpServer *echo.Echo
+	var logBuffer logtest.TestLogBuffer
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Invoke(func(httpServer *echo.Echo) {
+			httpServer.GET("/identity", func(c echo.Context) error {
+				log.CtxLogger(c.Request().Context()).Info().Msg("in identity handler")
+
+				return c.String(http.StatusOK, fxhttpserver.CtxClientIdentity(c))
+			})
+		}),
+		fx.Populate(&httpServer, &logBuffer),
+	).RequireStart().RequireStop()
+
+	assert.NotNil(t, httpServer.TLSServer.TLSConfig)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, httpServer.TLSServer.TLSConfig.ClientAuth)
+
+	clientCert, err := x509.ParseCertificate(files.clientCert.Certificate[0])
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/identity", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{clientCert, files.ca}}}
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "test-client", rec.Body.String())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":          "info",
+		"clientIdentity": "test-client",
+		"message":        "in identity handler",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":          "info",
+		"clientIdentity": "test-client",
+		"uri":            "/identity",
+		"status":         http.StatusOK,
+		"message":        "request logger",
+	})
+}
+
+func TestModuleWithInvalidTls(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("TLS_ENABLED", "true")
+	t.Setenv("TLS_CERT", "invalid.pem")
+	t.Setenv("TLS_KEY", "invalid.pem")
+
+	var httpServer *echo.Echo
+
+	err := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Populate(&httpServer),
+	).Err()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load http server tls certificate")
+}
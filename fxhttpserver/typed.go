@@ -0,0 +1,262 @@
+package fxhttpserver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	MIMEApplicationMsgpack  = "application/msgpack"
+	MIMEApplicationXMsgpack = "application/x-msgpack"
+)
+
+// TypedMediaTypes are the media types supported by the [TypedHandler], in preference order.
+var TypedMediaTypes = []string{
+	echo.MIMEApplicationJSON,
+	echo.MIMEApplicationXML,
+	MIMEApplicationMsgpack,
+	MIMEApplicationXMsgpack,
+}
+
+// TypedOptions are the options of a [TypedHandler].
+type TypedOptions struct {
+	Status    int
+	Operation OpenApiOperation
+}
+
+// TypedOption are functional options for the [TypedHandler].
+type TypedOption func(o *TypedOptions)
+
+// WithTypedStatus is used to specify the response status of a [TypedHandler], 200 by default.
+func WithTypedStatus(status int) TypedOption {
+	return func(o *TypedOptions) {
+		o.Status = status
+	}
+}
+
+// WithTypedOpenApi is used to specify the OpenAPI operation metadata of a [TypedHandler].
+// Its Request and Responses are deduced from the [TypedHandler] types if not provided.
+func WithTypedOpenApi(operation OpenApiOperation) TypedOption {
+	return func(o *TypedOptions) {
+		o.Operation = operation
+	}
+}
+
+// TypedHandler is a [Handler] adapting a typed function, created with [Typed].
+type TypedHandler[Req any, Res any] struct {
+	fn      func(context.Context, Req) (Res, error)
+	options TypedOptions
+}
+
+// Typed returns a [TypedHandler] for a function handling a Req request, and returning a Res response.
+//
+// On each request, the path, query, header and body values are bound into a Req value (using the param, query, header
+// and json / xml / msgpack tags), validated with the server validator if any, and the function response is serialized
+// according to the request Accept header (JSON, XML or msgpack, JSON by default).
+// The binding and validation failures are returned as 400 [echo.HTTPError], and the function errors are returned as
+// is, to be handled by the server error handler.
+func Typed[Req any, Res any](fn func(context.Context, Req) (Res, error), options ...TypedOption) *TypedHandler[Req, Res] {
+	typedOptions := TypedOptions{
+		Status: http.StatusOK,
+	}
+
+	for _, opt := range options {
+		opt(&typedOptions)
+	}
+
+	return &TypedHandler[Req, Res]{
+		fn:      fn,
+		options: typedOptions,
+	}
+}
+
+// Handle returns the [echo.HandlerFunc] binding and validating the request, calling the typed function, and
+// serializing its response.
+func (h *TypedHandler[Req, Res]) Handle() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req Req
+
+		// pointer request types are allocated before binding
+		target := any(&req)
+		if reqType := reflect.TypeOf(req); reqType != nil && reqType.Kind() == reflect.Pointer {
+			//nolint:forcetypeassert
+			req = reflect.New(reqType.Elem()).Interface().(Req)
+			target = req
+		}
+
+		err := bindTypedRequest(c, target)
+		if err != nil {
+			return err
+		}
+
+		if c.Echo().Validator != nil {
+			err = c.Validate(target)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+		}
+
+		res, err := h.fn(c.Request().Context(), req)
+		if err != nil {
+			return err
+		}
+
+		return writeTypedResponse(c, h.options.Status, res)
+	}
+}
+
+// OpenApi returns the [OpenApiOperation] of the handler, deduced from its request and response types.
+func (h *TypedHandler[Req, Res]) OpenApi() *OpenApiOperation {
+	operation := h.options.Operation
+
+	if operation.Request == nil {
+		if reqType := derefType(reflect.TypeOf((*Req)(nil)).Elem()); reqType.Kind() == reflect.Struct {
+			operation.Request = reflect.New(reqType).Interface()
+		}
+	}
+
+	if operation.Responses == nil {
+		var res any
+		if h.options.Status != http.StatusNoContent {
+			res = reflect.New(derefType(reflect.TypeOf((*Res)(nil)).Elem())).Interface()
+		}
+
+		operation.Responses = map[int]any{
+			h.options.Status: res,
+		}
+	}
+
+	return &operation
+}
+
+// NegotiateMediaType returns the first of the provided media types accepted by an Accept header value, or an empty
+// string if none is accepted.
+func NegotiateMediaType(accept string, mediaTypes []string) string {
+	if accept == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		accepted[name] = quality > 0
+	}
+
+	for _, mediaType := range mediaTypes {
+		mainType, _, _ := strings.Cut(mediaType, "/")
+
+		for _, candidate := range []string{mediaType, mainType + "/*", "*/*"} {
+			if acceptedMediaType, ok := accepted[candidate]; ok {
+				if acceptedMediaType {
+					return mediaType
+				}
+
+				break
+			}
+		}
+	}
+
+	return ""
+}
+
+// EchoValidator is an [echo.Validator] based on a [validator.Validate] instance, as provided by the fxvalidator module.
+type EchoValidator struct {
+	validate *validator.Validate
+}
+
+// NewEchoValidator returns a new [EchoValidator].
+func NewEchoValidator(validate *validator.Validate) *EchoValidator {
+	return &EchoValidator{
+		validate: validate,
+	}
+}
+
+// Validate validates the provided struct.
+func (v *EchoValidator) Validate(i interface{}) error {
+	return v.validate.Struct(i)
+}
+
+func bindTypedRequest(c echo.Context, target any) error {
+	binder := &echo.DefaultBinder{}
+
+	err := binder.BindPathParams(c, target)
+	if err != nil {
+		return err
+	}
+
+	err = binder.BindQueryParams(c, target)
+	if err != nil {
+		return err
+	}
+
+	err = binder.BindHeaders(c, target)
+	if err != nil {
+		return err
+	}
+
+	req := c.Request()
+
+	contentType := req.Header.Get(echo.HeaderContentType)
+	if req.ContentLength != 0 && isMsgpackMediaType(contentType) {
+		decoder := msgpack.NewDecoder(req.Body)
+		decoder.SetCustomStructTag("json")
+
+		err = decoder.Decode(target)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+
+		return nil
+	}
+
+	return binder.BindBody(c, target)
+}
+
+func writeTypedResponse(c echo.Context, status int, res any) error {
+	if status == http.StatusNoContent {
+		return c.NoContent(status)
+	}
+
+	mediaType := NegotiateMediaType(c.Request().Header.Get(echo.HeaderAccept), TypedMediaTypes)
+
+	switch mediaType {
+	case echo.MIMEApplicationXML:
+		return c.XML(status, res)
+	case MIMEApplicationMsgpack, MIMEApplicationXMsgpack:
+		var buf bytes.Buffer
+
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetCustomStructTag("json")
+
+		err := encoder.Encode(res)
+		if err != nil {
+			return err
+		}
+
+		return c.Blob(status, mediaType, buf.Bytes())
+	default:
+		return c.JSON(status, res)
+	}
+}
+
+func isMsgpackMediaType(contentType string) bool {
+	return strings.HasPrefix(contentType, MIMEApplicationMsgpack) || strings.HasPrefix(contentType, MIMEApplicationXMsgpack)
+}
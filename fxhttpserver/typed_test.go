@@ -0,0 +1,224 @@
+package fxhttpserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithTypedHandlers(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("OPENAPI_EXPOSE", "true")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Supply(validator.New(validator.WithRequiredStructEnabled())),
+		fxhttpserver.AsHandler(
+			"POST",
+			"/users/:id",
+			fxhttpserver.Typed(
+				handler.TestTypedHandler,
+				fxhttpserver.WithTypedStatus(http.StatusCreated),
+				fxhttpserver.WithTypedOpenApi(fxhttpserver.OpenApiOperation{
+					OperationId: "createUser",
+					Tags:        []string{"users"},
+				}),
+			),
+		),
+		fxhttpserver.AsHandlersGroup(
+			"/group",
+			[]*fxhttpserver.HandlerRegistration{
+				fxhttpserver.NewHandlerRegistration(
+					"DELETE",
+					"/users/:id",
+					fxhttpserver.Typed(handler.TestTypedDeleteHandler, fxhttpserver.WithTypedStatus(http.StatusNoContent)),
+				),
+			},
+		),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	serve := func(method string, target string, contentType string, accept string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("X-Tenant", "tenant")
+		req.Header.Set(echo.HeaderAccept, accept)
+		if contentType != "" {
+			req.Header.Set(echo.HeaderContentType, contentType)
+		}
+
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	expected := handler.TestTypedResponse{
+		Id:     123,
+		Notify: true,
+		Tenant: "tenant",
+		Name:   "john",
+	}
+
+	// json
+	rec := serve(http.MethodPost, "/users/123?notify=true", echo.MIMEApplicationJSON, "", []byte(`{"name":"john"}`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var jsonResponse handler.TestTypedResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &jsonResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, jsonResponse)
+
+	// xml
+	rec = serve(http.MethodPost, "/users/123?notify=true", echo.MIMEApplicationXML, "application/xml;q=0.9, application/json;q=0", []byte(`<request><name>john</name></request>`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationXMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+
+	var xmlResponse handler.TestTypedResponse
+	err = xml.Unmarshal(rec.Body.Bytes(), &xmlResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, xmlResponse)
+
+	// msgpack
+	msgpackRequest, err := msgpack.Marshal(map[string]any{"name": "john"})
+	assert.NoError(t, err)
+
+	rec = serve(http.MethodPost, "/users/123?notify=true", fxhttpserver.MIMEApplicationMsgpack, fxhttpserver.MIMEApplicationXMsgpack, msgpackRequest)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, fxhttpserver.MIMEApplicationXMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+	var msgpackResponse map[string]any
+	err = msgpack.Unmarshal(rec.Body.Bytes(), &msgpackResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, "john", msgpackResponse["name"])
+	assert.Equal(t, "tenant", msgpackResponse["tenant"])
+
+	// invalid binding
+	rec = serve(http.MethodPost, "/users/invalid", echo.MIMEApplicationJSON, "", []byte(`{"name":"john"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(http.MethodPost, "/users/123", fxhttpserver.MIMEApplicationMsgpack, "", []byte(`invalid`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// invalid request
+	rec = serve(http.MethodPost, "/users/123", echo.MIMEApplicationJSON, "", []byte(`{"name":"j"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// handler error
+	rec = serve(http.MethodPost, "/users/123", echo.MIMEApplicationJSON, "", []byte(`{"name":"conflict"}`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// no content
+	rec = serve(http.MethodDelete, "/group/users/123", "", "", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = serve(http.MethodDelete, "/group/users/0", "", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// openapi
+	rec = serve(http.MethodGet, "/openapi.json", "", "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var document fxhttpserver.OpenApiDocument
+	err = json.Unmarshal(rec.Body.Bytes(), &document)
+	assert.NoError(t, err)
+
+	create := document.Paths["/users/{id}"]["post"]
+	assert.NotNil(t, create)
+	assert.Equal(t, "createUser", create.OperationId)
+	assert.Equal(t, []string{"users"}, create.Tags)
+	assert.Len(t, create.Parameters, 3)
+	assert.Equal(t, "integer", create.Parameters[0].Schema.Type)
+	assert.Equal(t, []string{"name"}, create.RequestBody.Content["application/json"].Schema.Required)
+	assert.Equal(t, "#/components/schemas/TestTypedResponse", create.Responses["201"].Content["application/json"].Schema.Ref)
+
+	remove := document.Paths["/group/users/{id}"]["delete"]
+	assert.NotNil(t, remove)
+	assert.Nil(t, remove.RequestBody)
+	assert.Nil(t, remove.Responses["204"].Content)
+}
+
+func TestModuleWithTypedHandlersWithoutValidator(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+
+	var httpServer *echo.Echo
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fxhttpserver.AsHandler("POST", "/users/:id", fxhttpserver.Typed(handler.TestTypedHandler)),
+		fx.Populate(&httpServer),
+	).RequireStart().RequireStop()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/123", strings.NewReader(`{"name":"j"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":123,"notify":false,"tenant":"","name":"j"}`, rec.Body.String())
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", ""},
+		{"application/json", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/html, application/msgpack;q=0.5", "application/msgpack"},
+		{"application/*", "application/json"},
+		{"*/*", "application/json"},
+		{"application/json;q=0, */*", "application/xml"},
+		{"application/*;q=0, application/xml", "application/xml"},
+		{"text/html", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.accept, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, fxhttpserver.NegotiateMediaType(tt.accept, fxhttpserver.TypedMediaTypes))
+		})
+	}
+}
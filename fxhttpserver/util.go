@@ -1,6 +1,8 @@
 package fxhttpserver
 
-import "strings"
+import (
+	"strings"
+)
 
 // Contains returns true if a given string can be found in a given slice of strings.
 func Contains(list []string, str string) bool {
@@ -0,0 +1,207 @@
+package fxhttpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// WebSocketHandler is the interface for WebSocket handlers.
+type WebSocketHandler interface {
+	Serve(c echo.Context, conn *WebSocketConn) error
+}
+
+// WebSocketConn is a WebSocket connection.
+//
+// Writes are safe for concurrent use, and block until the message is written (backpressure), or fail after the
+// write timeout. Reads must be done from a single goroutine.
+type WebSocketConn struct {
+	ctx          context.Context
+	conn         *websocket.Conn
+	mutex        sync.Mutex
+	writeTimeout time.Duration
+	onSend       func()
+	onReceive    func()
+}
+
+// Context returns the connection context, carrying the request logger and trace span, and canceled when the client
+// disconnects, or when the server shuts down.
+func (w *WebSocketConn) Context() context.Context {
+	return w.ctx
+}
+
+// Conn returns the underlying [websocket.Conn].
+func (w *WebSocketConn) Conn() *websocket.Conn {
+	return w.conn
+}
+
+// ReadMessage reads a message, and returns its type and data.
+func (w *WebSocketConn) ReadMessage() (int, []byte, error) {
+	messageType, data, err := w.conn.ReadMessage()
+	if err != nil {
+		return messageType, data, err
+	}
+
+	w.onReceive()
+
+	return messageType, data, nil
+}
+
+// ReadJSON reads a message, and decodes its JSON data into the provided target.
+func (w *WebSocketConn) ReadJSON(target any) error {
+	_, data, err := w.ReadMessage()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// WriteMessage writes a message, of a provided type (text or binary).
+func (w *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.ctx.Err() != nil {
+		return ErrStreamClosed
+	}
+
+	err := w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+	if err != nil {
+		return err
+	}
+
+	err = w.conn.WriteMessage(messageType, data)
+	if err != nil {
+		return err
+	}
+
+	w.onSend()
+
+	return nil
+}
+
+// WriteJSON writes a text message, with the JSON encoded data.
+func (w *WebSocketConn) WriteJSON(data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cannot encode message data: %w", err)
+	}
+
+	return w.WriteMessage(websocket.TextMessage, encoded)
+}
+
+func (w *WebSocketConn) close(code int, text string) {
+	//nolint:errcheck
+	w.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, text),
+		time.Now().Add(w.writeTimeout),
+	)
+}
+
+// WebSocketHandlerFunc returns an [echo.HandlerFunc] upgrading the connection to WebSocket, and serving it with a
+// provided [WebSocketHandler].
+func (m *StreamManager) WebSocketHandlerFunc(handler WebSocketHandler) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		path := c.Path()
+
+		connCtx, release, ok := m.open(req.Context(), StreamProtocolWebSocket, path)
+		if !ok {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "server is shutting down")
+		}
+
+		defer release()
+
+		logger := log.CtxLogger(connCtx)
+
+		// on failure, the upgrader already responded with an error status
+		conn, err := m.upgrader.Upgrade(c.Response(), req, nil)
+		if err != nil {
+			logger.Warn().Err(err).Msg("websocket upgrade failure")
+
+			return nil
+		}
+
+		//nolint:errcheck
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(connCtx)
+		defer cancel()
+
+		c.SetRequest(req.WithContext(ctx))
+
+		pongWait := 2 * m.config.Heartbeat
+
+		conn.SetReadLimit(m.config.ReadLimit)
+
+		//nolint:errcheck
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+
+		wsConn := &WebSocketConn{
+			ctx:          ctx,
+			conn:         conn,
+			writeTimeout: m.config.WriteTimeout,
+			onSend: func() {
+				m.countMessage(StreamProtocolWebSocket, StreamDirectionSent, path)
+			},
+			onReceive: func() {
+				m.countMessage(StreamProtocolWebSocket, StreamDirectionReceived, path)
+			},
+		}
+
+		go func() {
+			ticker := time.NewTicker(m.config.Heartbeat)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					if m.ctx.Err() != nil {
+						// graceful close on shutdown, leaving a grace period to the handler to read the client close reply
+						wsConn.close(websocket.CloseGoingAway, "server shutdown")
+
+						//nolint:errcheck
+						conn.SetReadDeadline(time.Now().Add(streamWebSocketCloseGracePeriod))
+					}
+
+					return
+				case <-ticker.C:
+					pingErr := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(m.config.WriteTimeout))
+					if pingErr != nil {
+						logger.Debug().Err(pingErr).Msg("websocket heartbeat failure")
+						cancel()
+
+						return
+					}
+				}
+			}
+		}()
+
+		err = handler.Serve(c, wsConn)
+
+		cancel()
+
+		wsConn.close(websocket.CloseNormalClosure, "")
+
+		// the connection is hijacked, errors cannot be handled by the error handler
+		var closeErr *websocket.CloseError
+		if err != nil && !errors.As(err, &closeErr) && !errors.Is(err, ErrStreamClosed) && m.ctx.Err() == nil {
+			logger.Error().Err(err).Msg("websocket handler failure")
+		}
+
+		return nil
+	}
+}
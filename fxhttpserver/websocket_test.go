@@ -0,0 +1,169 @@
+package fxhttpserver_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxhttpserver"
+	"github.com/ankorstore/yokai/fxhttpserver/fxhttpservertest"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/handler"
+	"github.com/ankorstore/yokai/fxhttpserver/testdata/service"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+//nolint:maintidx
+func TestModuleWithWebSocketHandler(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("STREAMS_HEARTBEAT", "1")
+
+	var httpServer *echo.Echo
+	var metricsRegistry *prometheus.Registry
+	var logBuffer logtest.TestLogBuffer
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxtrace.FxTraceModule,
+		fxmetrics.FxMetricsModule,
+		fxgenerate.FxGenerateModule,
+		fxhttpserver.FxHttpServerModule,
+		fx.Provide(service.NewTestService),
+		fxhttpserver.AsWebSocketHandler("/ws", handler.NewTestWebSocketHandler),
+		fxhttpserver.AsWebSocketHandler(
+			"/ws/admin",
+			handler.NewTestWebSocketHandler,
+			fxhttpserver.NewFxBasicAuthMiddleware,
+			fxhttpserver.RequireRoles("admin"),
+		),
+		fx.Populate(&httpServer, &metricsRegistry, &logBuffer),
+	).RequireStart()
+
+	srv := fxhttpservertest.NewHttpServerTestServer(httpServer)
+	defer srv.Close()
+
+	headers := http.Header{}
+	headers.Set("x-request-id", testRequestId)
+	headers.Set("traceparent", testTraceParent)
+	headers.Set("Origin", "http://allowed.example.com")
+
+	cli, resp, err := srv.ConnectWebSocket(context.Background(), "/ws", headers)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// messages
+	assert.NoError(t, cli.Send("hello"))
+
+	message, err := cli.Receive(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "test: hello", message)
+
+	// connection kept alive by heartbeats
+	_, err = cli.Receive(2500 * time.Millisecond)
+	assert.ErrorIs(t, err, fxhttpservertest.ErrTimeout)
+	assert.GreaterOrEqual(t, cli.Pings(), 1)
+
+	assert.NoError(t, cli.Send("still there"))
+
+	message, err = cli.Receive(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "test: still there", message)
+
+	// connection context
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"service":   "test",
+		"module":    "httpserver",
+		"requestID": testRequestId,
+		"traceID":   testTraceId,
+		"message":   "in websocket handler",
+	})
+
+	// metrics
+	expectedMetric := `
+		# HELP http_server_stream_connections Number of open HTTP streams connections
+		# TYPE http_server_stream_connections gauge
+		http_server_stream_connections{path="/ws",protocol="websocket"} 1
+		# HELP http_server_stream_messages_total Number of HTTP streams messages sent and received
+		# TYPE http_server_stream_messages_total counter
+		http_server_stream_messages_total{direction="received",path="/ws",protocol="websocket"} 2
+		http_server_stream_messages_total{direction="sent",path="/ws",protocol="websocket"} 2
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_stream_connections",
+		"http_server_stream_messages_total",
+	)
+	assert.NoError(t, err)
+
+	// origin check
+	invalidOriginHeaders := http.Header{}
+	invalidOriginHeaders.Set("Origin", "http://invalid.example.com")
+
+	_, resp, err = srv.ConnectWebSocket(context.Background(), "/ws", invalidOriginHeaders)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// authorization
+	_, resp, err = srv.ConnectWebSocket(context.Background(), "/ws/admin", nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.SetBasicAuth("admin", "admin-password")
+
+	adminCli, resp, err := srv.ConnectWebSocket(context.Background(), "/ws/admin", req.Header)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.NoError(t, adminCli.Close())
+
+	// graceful close on stop
+	app.RequireStop()
+
+	_, err = cli.Receive(time.Second)
+
+	var closeErr *websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	}
+	assert.NoError(t, cli.Close())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":     "info",
+		"requestID": testRequestId,
+		"message":   "websocket connection closed",
+	})
+
+	expectedMetric = `
+		# HELP http_server_stream_connections Number of open HTTP streams connections
+		# TYPE http_server_stream_connections gauge
+		http_server_stream_connections{path="/ws",protocol="websocket"} 0
+		http_server_stream_connections{path="/ws/admin",protocol="websocket"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		metricsRegistry,
+		strings.NewReader(expectedMetric),
+		"http_server_stream_connections",
+	)
+	assert.NoError(t, err)
+}
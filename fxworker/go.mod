@@ -12,11 +12,12 @@ require (
 	github.com/ankorstore/yokai/fxmetrics v1.1.0
 	github.com/ankorstore/yokai/fxtrace v1.2.0
 	github.com/ankorstore/yokai/generate v1.2.0
+	github.com/ankorstore/yokai/healthcheck v1.4.0
 	github.com/ankorstore/yokai/log v1.2.0
 	github.com/ankorstore/yokai/trace v1.3.0
 	github.com/ankorstore/yokai/worker v1.3.0
 	github.com/prometheus/client_golang v1.19.1
-	github.com/stretchr/testify v1.9.0
+	github.com/stretchr/testify v1.11.1
 	go.opentelemetry.io/otel/trace v1.24.0
 	go.uber.org/fx v1.22.0
 )
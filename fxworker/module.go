@@ -5,6 +5,7 @@ import (
 
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/generate/uuid"
+	"github.com/ankorstore/yokai/healthcheck"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/trace"
 	"github.com/ankorstore/yokai/worker"
@@ -36,14 +37,15 @@ var FxWorkerModule = fx.Module(
 // FxWorkerPoolParam allows injection of the required dependencies in [NewFxWorkerPool].
 type FxWorkerPoolParam struct {
 	fx.In
-	LifeCycle       fx.Lifecycle
-	Generator       uuid.UuidGenerator
-	TracerProvider  oteltrace.TracerProvider
-	Factory         worker.WorkerPoolFactory
-	Config          *config.Config
-	Registry        *WorkerRegistry
-	Logger          *log.Logger
-	MetricsRegistry *prometheus.Registry
+	LifeCycle        fx.Lifecycle
+	Generator        uuid.UuidGenerator
+	TracerProvider   oteltrace.TracerProvider
+	Factory          worker.WorkerPoolFactory
+	Config           *config.Config
+	Registry         *WorkerRegistry
+	Logger           *log.Logger
+	MetricsRegistry  *prometheus.Registry
+	ShutdownRecorder healthcheck.ShutdownRecorder `optional:"true"`
 }
 
 // NewFxWorkerPool returns a new [worker.WorkerPool].
@@ -114,10 +116,8 @@ func NewFxWorkerPool(p FxWorkerPoolParam) (*worker.WorkerPool, error) {
 
 			return workerPool.Start(workerPoolCtx)
 		},
-		OnStop: func(context.Context) error {
-			logger.Debug().Msg("stopping worker pool")
-
-			return workerPool.Stop()
+		OnStop: func(ctx context.Context) error {
+			return shutdownWorkerPool(ctx, workerPool, logger, p.ShutdownRecorder)
 		},
 	})
 
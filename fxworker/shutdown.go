@@ -0,0 +1,39 @@
+package fxworker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/worker"
+)
+
+// shutdownWorkerPool stops the worker pool from starting new executions, and waits for the in-flight ones up to the
+// context deadline.
+func shutdownWorkerPool(ctx context.Context, workerPool *worker.WorkerPool, logger *log.Logger, recorder healthcheck.ShutdownRecorder) error {
+	healthcheck.RecordShutdown(logger, recorder, ModuleName, "worker pool stopped starting new executions, waiting for in-flight executions")
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- workerPool.Stop()
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			healthcheck.RecordShutdown(logger, recorder, ModuleName, fmt.Sprintf("worker pool stopped with error: %v", err))
+
+			return err
+		}
+
+		healthcheck.RecordShutdown(logger, recorder, ModuleName, "worker pool in-flight executions completed, stopped")
+
+		return nil
+	case <-ctx.Done():
+		healthcheck.RecordShutdown(logger, recorder, ModuleName, "worker pool stop deadline reached, abandoning in-flight executions")
+
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package fxworker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxgenerate"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/fxmetrics"
+	"github.com/ankorstore/yokai/fxtrace"
+	"github.com/ankorstore/yokai/fxworker"
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/shutdown"
+	"github.com/ankorstore/yokai/worker"
+	"github.com/ankorstore/yokai/worker/testdata/workers"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+type blockingWorker struct{}
+
+func (w *blockingWorker) Name() string {
+	return "BlockingWorker"
+}
+
+func (w *blockingWorker) Run(context.Context) error {
+	// ignores the context cancellation, to simulate an execution exceeding the stop deadline
+	time.Sleep(time.Second)
+
+	return nil
+}
+
+func TestModuleShutdown(t *testing.T) {
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("APP_ENV", "test")
+
+	tests := []struct {
+		name          string
+		worker        any
+		expectedError error
+		expected      []string
+	}{
+		{
+			name:   "in-flight executions completed",
+			worker: workers.NewCancellableWorker,
+			expected: []string{
+				"worker: worker pool stopped starting new executions, waiting for in-flight executions",
+				"worker: worker pool in-flight executions completed, stopped",
+			},
+		},
+		{
+			name: "stop deadline reached",
+			worker: func() *blockingWorker {
+				return &blockingWorker{}
+			},
+			expectedError: context.DeadlineExceeded,
+			expected: []string{
+				"worker: worker pool stopped starting new executions, waiting for in-flight executions",
+				"worker: worker pool stop deadline reached, abandoning in-flight executions",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := shutdown.NewTestShutdownRecorder()
+
+			app := fx.New(
+				fx.NopLogger,
+				fxconfig.FxConfigModule,
+				fxlog.FxLogModule,
+				fxtrace.FxTraceModule,
+				fxmetrics.FxMetricsModule,
+				fxgenerate.FxGenerateModule,
+				fxworker.FxWorkerModule,
+				fx.Provide(
+					fx.Annotate(
+						func() *shutdown.TestShutdownRecorder {
+							return recorder
+						},
+						fx.As(new(healthcheck.ShutdownRecorder)),
+					),
+				),
+				fxworker.AsWorker(tt.worker),
+				fx.Invoke(func(*worker.WorkerPool) {}),
+			)
+
+			assert.NoError(t, app.Start(context.Background()))
+
+			// time for the worker to start its execution
+			time.Sleep(50 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := app.Stop(ctx)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Eventually(t, func() bool {
+				return assert.ObjectsAreEqual(tt.expected, recorder.Messages())
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorStatusResolver is the interface for resolvers of errors into an HTTP status code and title,
+// for example the httpserver module ErrorMapper.
+type ErrorStatusResolver interface {
+	ResolveStatus(err error) (int, string, bool)
+}
+
+// GrpcErrorStatusInterceptor is a gRPC unary and stream server interceptor converting the handlers errors into
+// gRPC statuses, using the same error mapping as the HTTP server.
+type GrpcErrorStatusInterceptor struct {
+	resolver ErrorStatusResolver
+}
+
+// NewGrpcErrorStatusInterceptor returns a new [GrpcErrorStatusInterceptor] instance.
+func NewGrpcErrorStatusInterceptor(resolver ErrorStatusResolver) *GrpcErrorStatusInterceptor {
+	return &GrpcErrorStatusInterceptor{
+		resolver: resolver,
+	}
+}
+
+// UnaryInterceptor handles the unary requests.
+func (i *GrpcErrorStatusInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		return resp, i.Convert(err)
+	}
+}
+
+// StreamInterceptor handles the stream requests.
+func (i *GrpcErrorStatusInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i.Convert(handler(srv, ss))
+	}
+}
+
+// Convert converts an error into a gRPC status error, if it's not already one and if the resolver maps it.
+func (i *GrpcErrorStatusInterceptor) Convert(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	httpStatus, _, ok := i.resolver.ResolveStatus(err)
+	if !ok {
+		return err
+	}
+
+	return status.Error(HttpStatusToGrpcCode(httpStatus), err.Error())
+}
+
+// HttpStatusToGrpcCode converts an HTTP status code into a gRPC code.
+//
+//nolint:cyclop
+func HttpStatusToGrpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,101 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ankorstore/yokai/grpcserver"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errTestNotFound = errors.New("resource not found")
+
+type testErrorStatusResolver struct{}
+
+func (r *testErrorStatusResolver) ResolveStatus(err error) (int, string, bool) {
+	if errors.Is(err, errTestNotFound) {
+		return http.StatusNotFound, "Not Found", true
+	}
+
+	return 0, "", false
+}
+
+func TestGrpcErrorStatusInterceptorUnary(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcErrorStatusInterceptor(&testErrorStatusResolver{}).UnaryInterceptor()
+
+	tests := []struct {
+		name          string
+		err           error
+		expectedCode  codes.Code
+		expectedError string
+	}{
+		{"without error", nil, codes.OK, ""},
+		{"with mapped error", fmt.Errorf("cannot find user: %w", errTestNotFound), codes.NotFound, "cannot find user: resource not found"},
+		{"with status error", status.Error(codes.Aborted, "aborted"), codes.Aborted, "aborted"},
+		{"with unmapped error", errors.New("custom error"), codes.Unknown, "custom error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := interceptor(
+				context.Background(),
+				"request",
+				&grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return "response", tt.err
+				},
+			)
+
+			assert.Equal(t, "response", resp)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, status.Convert(err).Message())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGrpcErrorStatusInterceptorStream(t *testing.T) {
+	t.Parallel()
+
+	interceptor := grpcserver.NewGrpcErrorStatusInterceptor(&testErrorStatusResolver{}).StreamInterceptor()
+
+	err := interceptor(
+		nil,
+		nil,
+		&grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			return errTestNotFound
+		},
+	)
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestHttpStatusToGrpcCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, codes.OK, grpcserver.HttpStatusToGrpcCode(http.StatusOK))
+	assert.Equal(t, codes.InvalidArgument, grpcserver.HttpStatusToGrpcCode(http.StatusBadRequest))
+	assert.Equal(t, codes.Unauthenticated, grpcserver.HttpStatusToGrpcCode(http.StatusUnauthorized))
+	assert.Equal(t, codes.PermissionDenied, grpcserver.HttpStatusToGrpcCode(http.StatusForbidden))
+	assert.Equal(t, codes.NotFound, grpcserver.HttpStatusToGrpcCode(http.StatusNotFound))
+	assert.Equal(t, codes.AlreadyExists, grpcserver.HttpStatusToGrpcCode(http.StatusConflict))
+	assert.Equal(t, codes.ResourceExhausted, grpcserver.HttpStatusToGrpcCode(http.StatusTooManyRequests))
+	assert.Equal(t, codes.Unavailable, grpcserver.HttpStatusToGrpcCode(http.StatusServiceUnavailable))
+	assert.Equal(t, codes.Internal, grpcserver.HttpStatusToGrpcCode(http.StatusInternalServerError))
+	assert.Equal(t, codes.Unknown, grpcserver.HttpStatusToGrpcCode(http.StatusTeapot))
+}
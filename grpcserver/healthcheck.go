@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ankorstore/yokai/healthcheck"
 	"google.golang.org/grpc/codes"
@@ -14,13 +16,40 @@ import (
 // GrpcHealthCheckService is a default gRPC health check server implementation working with the [healthcheck.Checker].
 type GrpcHealthCheckService struct {
 	grpc_health_v1.UnimplementedHealthServer
-	checker *healthcheck.Checker
+	checker      *healthcheck.Checker
+	options      HealthCheckServiceOptions
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
-// NewGrpcHealthCheckService returns a new [GrpcHealthCheckService] instance.
-func NewGrpcHealthCheckService(checker *healthcheck.Checker) *GrpcHealthCheckService {
+// NewGrpcHealthCheckService returns a new [GrpcHealthCheckService] instance, with optional [HealthCheckServiceOption].
+func NewGrpcHealthCheckService(checker *healthcheck.Checker, options ...HealthCheckServiceOption) *GrpcHealthCheckService {
+	serviceOptions := DefaultHealthCheckServiceOptions()
+	for _, opt := range options {
+		opt(&serviceOptions)
+	}
+
 	return &GrpcHealthCheckService{
-		checker: checker,
+		checker:  checker,
+		options:  serviceOptions,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Shutdown sets the [GrpcHealthCheckService] as not serving: the active Watch streams are sent a NOT_SERVING status
+// and ended, so they do not block the gRPC server graceful stop, and further checks are NOT_SERVING.
+func (s *GrpcHealthCheckService) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdown)
+	})
+}
+
+func (s *GrpcHealthCheckService) isShutdown() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -30,14 +59,18 @@ func (s *GrpcHealthCheckService) Check(ctx context.Context, in *grpc_health_v1.H
 
 	serviceName := strings.ToLower(in.Service)
 
-	var kind healthcheck.ProbeKind
-	switch {
-	case strings.Contains(serviceName, healthcheck.Liveness.String()):
-		kind = healthcheck.Liveness
-	case strings.Contains(serviceName, healthcheck.Readiness.String()):
-		kind = healthcheck.Readiness
-	default:
-		kind = healthcheck.Startup
+	kind := resolveProbeKind(serviceName)
+
+	if s.isShutdown() {
+		logger.
+			Info().
+			Str("kind", kind.String()).
+			Str("caller", serviceName).
+			Msg("grpc health check on shutdown")
+
+		return &grpc_health_v1.HealthCheckResponse{
+			Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		}, nil
 	}
 
 	result := s.checker.Check(ctx, kind)
@@ -69,12 +102,87 @@ func (s *GrpcHealthCheckService) Check(ctx context.Context, in *grpc_health_v1.H
 	}, nil
 }
 
-// Watch is not implemented.
+// Watch performs checks on the registered [healthcheck.CheckerProbe] on a regular interval, and streams the status changes.
+// The current status is sent immediately, then only when it changes, until the client cancels the stream or the
+// service is shut down (a last NOT_SERVING status is then sent).
 func (s *GrpcHealthCheckService) Watch(in *grpc_health_v1.HealthCheckRequest, watchServer grpc_health_v1.Health_WatchServer) error {
-	CtxLogger(watchServer.Context()).
-		Warn().
-		Str("caller", in.Service).
-		Msg("grpc health watch not implemented")
+	ctx := watchServer.Context()
+
+	logger := CtxLogger(ctx)
+
+	serviceName := strings.ToLower(in.Service)
+
+	kind := resolveProbeKind(serviceName)
+
+	logger.
+		Info().
+		Str("kind", kind.String()).
+		Str("caller", serviceName).
+		Msg("grpc health watch start")
+
+	ticker := time.NewTicker(s.options.WatchInterval)
+	defer ticker.Stop()
+
+	lastStatus := grpc_health_v1.HealthCheckResponse_UNKNOWN
+
+	for {
+		currentStatus := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+
+		shutdown := s.isShutdown()
+		if !shutdown && s.checker.Check(ctx, kind).Success {
+			currentStatus = grpc_health_v1.HealthCheckResponse_SERVING
+		}
+
+		if currentStatus != lastStatus {
+			logger.
+				Info().
+				Str("kind", kind.String()).
+				Str("caller", serviceName).
+				Str("status", currentStatus.String()).
+				Msg("grpc health watch status change")
+
+			err := watchServer.Send(&grpc_health_v1.HealthCheckResponse{
+				Status: currentStatus,
+			})
+			if err != nil {
+				return err
+			}
+
+			lastStatus = currentStatus
+		}
+
+		if shutdown {
+			logger.
+				Info().
+				Str("kind", kind.String()).
+				Str("caller", serviceName).
+				Msg("grpc health watch end on shutdown")
 
-	return status.Error(codes.Unimplemented, "watch is not implemented")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.
+				Info().
+				Str("kind", kind.String()).
+				Str("caller", serviceName).
+				Msg("grpc health watch end")
+
+			return status.Error(codes.Canceled, "watch stream ended")
+		case <-s.shutdown:
+		case <-ticker.C:
+		}
+	}
+}
+
+func resolveProbeKind(serviceName string) healthcheck.ProbeKind {
+	switch {
+	case strings.Contains(serviceName, healthcheck.Liveness.String()):
+		return healthcheck.Liveness
+	case strings.Contains(serviceName, healthcheck.Readiness.String()):
+		return healthcheck.Readiness
+	default:
+		return healthcheck.Startup
+	}
 }
@@ -2,13 +2,16 @@ package grpcserver_test
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/generate/generatetest/uuid"
 	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/ankorstore/yokai/grpcserver/grpcservertest"
 	"github.com/ankorstore/yokai/grpcserver/testdata/probes"
 	"github.com/ankorstore/yokai/healthcheck"
+	healthcheckprobes "github.com/ankorstore/yokai/healthcheck/testdata/probes"
 	"github.com/ankorstore/yokai/log"
 	"github.com/ankorstore/yokai/log/logtest"
 	"github.com/stretchr/testify/assert"
@@ -120,8 +123,11 @@ func TestWatch(t *testing.T) {
 	t.Parallel()
 
 	// checker
+	switchProbe := healthcheckprobes.NewSwitchProbe(true)
+
 	checker, err := healthcheck.NewDefaultCheckerFactory().Create(
 		healthcheck.WithProbe(probes.NewSuccessProbe()),
+		healthcheck.WithProbe(switchProbe, healthcheck.Readiness),
 	)
 	assert.NoError(t, err)
 
@@ -133,26 +139,151 @@ func TestWatch(t *testing.T) {
 	assert.NoError(t, err)
 
 	// client
-	client, closer := prepareHealthCheckServiceGrpcServerAndClient(t, checker, logger)
+	client, closer := prepareHealthCheckServiceGrpcServerAndClient(
+		t,
+		checker,
+		logger,
+		grpcserver.WithWatchInterval(5*time.Millisecond),
+	)
 	defer closer()
 
 	// call assertions
-	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	switchProbe.Switch(false)
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	switchProbe.Switch(true)
+
+	response, err = stream.Recv()
 	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	cancel()
 
 	_, err = stream.Recv()
 	assert.Error(t, err)
-	assert.Equal(t, "rpc error: code = Unimplemented desc = watch is not implemented", err.Error())
 
 	// logs assertions
 	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
-		"level":   "warn",
-		"caller":  "test",
-		"message": "grpc health watch not implemented",
+		"level":   "info",
+		"kind":    "readiness",
+		"caller":  "test::readiness",
+		"message": "grpc health watch start",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"kind":    "readiness",
+		"caller":  "test::readiness",
+		"status":  "NOT_SERVING",
+		"message": "grpc health watch status change",
+	})
+}
+
+func TestWatchOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	// checker
+	checker, err := healthcheck.NewDefaultCheckerFactory().Create(
+		healthcheck.WithProbe(probes.NewSuccessProbe()),
+	)
+	assert.NoError(t, err)
+
+	// logger
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	// server
+	lis := grpcservertest.NewBufconnListener(1024 * 1024)
+
+	loggerInterceptor := grpcserver.NewGrpcLoggerInterceptor(uuid.NewTestUuidGenerator("test"), logger)
+
+	server := grpc.NewServer(grpc.StreamInterceptor(loggerInterceptor.StreamInterceptor()))
+	defer server.Stop()
+
+	service := grpcserver.NewGrpcHealthCheckService(checker, grpcserver.WithWatchInterval(time.Hour))
+	server.RegisterService(&grpc_health_v1.Health_ServiceDesc, service)
+
+	go func() {
+		//nolint:errcheck
+		server.Serve(lis)
+	}()
+
+	// client
+	conn, err := grpcservertest.NewDefaultTestBufconnConnectionFactory(lis).Create(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	// shutdown assertions
+	service.Shutdown()
+
+	response, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	checkResponse, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "test::readiness"})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, checkResponse.Status)
+
+	// the graceful stop is not blocked by the ended watch stream
+	stopped := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("grpc server graceful stop blocked by the watch stream")
+	}
+
+	// logs assertions
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"kind":    "readiness",
+		"caller":  "test::readiness",
+		"message": "grpc health watch end on shutdown",
 	})
 }
 
-func prepareHealthCheckServiceGrpcServerAndClient(t *testing.T, checker *healthcheck.Checker, logger *log.Logger) (grpc_health_v1.HealthClient, func()) {
+func prepareHealthCheckServiceGrpcServerAndClient(
+	t *testing.T,
+	checker *healthcheck.Checker,
+	logger *log.Logger,
+	options ...grpcserver.HealthCheckServiceOption,
+) (grpc_health_v1.HealthClient, func()) {
 	t.Helper()
 
 	// bufconn listener preparation
@@ -168,7 +299,7 @@ func prepareHealthCheckServiceGrpcServerAndClient(t *testing.T, checker *healthc
 
 	server.RegisterService(
 		&grpc_health_v1.Health_ServiceDesc,
-		grpcserver.NewGrpcHealthCheckService(checker),
+		grpcserver.NewGrpcHealthCheckService(checker, options...),
 	)
 
 	go func() {
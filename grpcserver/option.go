@@ -1,9 +1,13 @@
 package grpcserver
 
 import (
+	"time"
+
 	"google.golang.org/grpc"
 )
 
+const DefaultHealthCheckWatchInterval = 5 * time.Second
+
 // Options are options for the [GrpcServerFactory] implementations.
 type Options struct {
 	ServerOptions []grpc.ServerOption
@@ -34,3 +38,27 @@ func WithReflection(r bool) GrpcServerOption {
 		o.Reflection = r
 	}
 }
+
+// HealthCheckServiceOptions are options for the [GrpcHealthCheckService].
+type HealthCheckServiceOptions struct {
+	WatchInterval time.Duration
+}
+
+// DefaultHealthCheckServiceOptions are the default options used in the [GrpcHealthCheckService].
+func DefaultHealthCheckServiceOptions() HealthCheckServiceOptions {
+	return HealthCheckServiceOptions{
+		WatchInterval: DefaultHealthCheckWatchInterval,
+	}
+}
+
+// HealthCheckServiceOption are functional options for the [GrpcHealthCheckService].
+type HealthCheckServiceOption func(o *HealthCheckServiceOptions)
+
+// WithWatchInterval is used to specify the interval between two checks performed for the Watch streams.
+func WithWatchInterval(interval time.Duration) HealthCheckServiceOption {
+	return func(o *HealthCheckServiceOptions) {
+		if interval > 0 {
+			o.WatchInterval = interval
+		}
+	}
+}
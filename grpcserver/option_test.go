@@ -2,6 +2,7 @@ package grpcserver_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/grpcserver"
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,16 @@ func TestWithReflection(t *testing.T) {
 
 	assert.True(t, opt.Reflection)
 }
+
+func TestWithWatchInterval(t *testing.T) {
+	t.Parallel()
+
+	opt := grpcserver.DefaultHealthCheckServiceOptions()
+	assert.Equal(t, grpcserver.DefaultHealthCheckWatchInterval, opt.WatchInterval)
+
+	grpcserver.WithWatchInterval(time.Second)(&opt)
+	assert.Equal(t, time.Second, opt.WatchInterval)
+
+	grpcserver.WithWatchInterval(0)(&opt)
+	assert.Equal(t, time.Second, opt.WatchInterval)
+}
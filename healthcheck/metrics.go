@@ -0,0 +1,82 @@
+package healthcheck
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	MonitorMetricsProbeStatus   = "healthcheck_probe_status"
+	MonitorMetricsProbeDuration = "healthcheck_probe_duration_seconds"
+)
+
+// MonitorMetrics allows the [Monitor] to send probes metrics to a [prometheus.Registerer].
+type MonitorMetrics struct {
+	registered bool
+	status     *prometheus.GaugeVec
+	duration   *prometheus.GaugeVec
+}
+
+// NewMonitorMetrics returns a new [MonitorMetrics], and accepts metrics namespace and subsystem.
+func NewMonitorMetrics(namespace string, subsystem string) *MonitorMetrics {
+	status := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      MonitorMetricsProbeStatus,
+			Help:      "Status of the last background probe execution (1 for success, 0 for failure)",
+		},
+		[]string{
+			"probe",
+		},
+	)
+
+	duration := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      MonitorMetricsProbeDuration,
+			Help:      "Duration of the last background probe execution",
+		},
+		[]string{
+			"probe",
+		},
+	)
+
+	return &MonitorMetrics{
+		registered: false,
+		status:     status,
+		duration:   duration,
+	}
+}
+
+// Register registers the [MonitorMetrics] against a [prometheus.Registerer].
+func (m *MonitorMetrics) Register(registry prometheus.Registerer) error {
+	err := registry.Register(m.status)
+	if err != nil {
+		return err
+	}
+
+	err = registry.Register(m.duration)
+	if err != nil {
+		return err
+	}
+
+	m.registered = true
+
+	return nil
+}
+
+// Observe records the result of a probe execution in the [MonitorMetrics].
+func (m *MonitorMetrics) Observe(record *MonitorRecord) *MonitorMetrics {
+	if m.registered {
+		status := 0.0
+		if record.Success {
+			status = 1.0
+		}
+
+		m.status.WithLabelValues(record.Probe).Set(status)
+		m.duration.WithLabelValues(record.Probe).Set(record.Duration.Seconds())
+	}
+
+	return m
+}
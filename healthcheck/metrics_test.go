@@ -0,0 +1,48 @@
+package healthcheck_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitorMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	metrics := healthcheck.NewMonitorMetrics("", "")
+
+	// not registered: no-op
+	metrics.Observe(&healthcheck.MonitorRecord{Probe: "probe", Success: true, Duration: time.Second})
+
+	err := metrics.Register(registry)
+	assert.NoError(t, err)
+
+	err = metrics.Register(registry)
+	assert.Error(t, err)
+
+	metrics.Observe(&healthcheck.MonitorRecord{Probe: "probe", Success: false, Duration: 2 * time.Second})
+
+	expectedMetric := `
+		# HELP healthcheck_probe_duration_seconds Duration of the last background probe execution
+		# TYPE healthcheck_probe_duration_seconds gauge
+		healthcheck_probe_duration_seconds{probe="probe"} 2
+		# HELP healthcheck_probe_status Status of the last background probe execution (1 for success, 0 for failure)
+		# TYPE healthcheck_probe_status gauge
+		healthcheck_probe_status{probe="probe"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"healthcheck_probe_status",
+		"healthcheck_probe_duration_seconds",
+	)
+	assert.NoError(t, err)
+}
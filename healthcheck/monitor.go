@@ -0,0 +1,228 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+)
+
+// MonitorRecord is the record of a [CheckerProbe] execution performed by the [Monitor].
+type MonitorRecord struct {
+	Probe    string        `json:"probe"`
+	Success  bool          `json:"success"`
+	Message  string        `json:"message"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+}
+
+// MonitorEvent is the event emitted by the [Monitor] when a [CheckerProbe] status changes.
+// The Previous record is nil if the probe was never executed before.
+type MonitorEvent struct {
+	Previous *MonitorRecord
+	Current  *MonitorRecord
+}
+
+// MonitorStatusChangeFunc is the callback invoked by the [Monitor] on [CheckerProbe] status changes.
+type MonitorStatusChangeFunc func(ctx context.Context, event *MonitorEvent)
+
+// Monitor executes in background, on a regular interval, the [CheckerProbe] registered in a [Checker].
+// It keeps a bounded history of executions per probe, and notifies about probes status changes.
+//
+//nolint:containedctx
+type Monitor struct {
+	mutex             sync.RWMutex
+	waitGroup         sync.WaitGroup
+	context           context.Context
+	contextCancelFunc context.CancelFunc
+	checker           *Checker
+	options           MonitorOptions
+	history           map[string][]*MonitorRecord
+	started           bool
+}
+
+// NewMonitor returns a new [Monitor] for a provided [Checker], with optional [MonitorOption].
+func NewMonitor(checker *Checker, options ...MonitorOption) *Monitor {
+	monitorOptions := DefaultMonitorOptions()
+	for _, opt := range options {
+		opt(&monitorOptions)
+	}
+
+	return &Monitor{
+		checker: checker,
+		options: monitorOptions,
+		history: make(map[string][]*MonitorRecord),
+	}
+}
+
+// Options returns the [MonitorOptions] of the [Monitor].
+func (m *Monitor) Options() MonitorOptions {
+	return m.options
+}
+
+// Start starts the background execution of the probes, until the [Monitor] is stopped or the provided context is cancelled.
+// A first execution round is performed immediately. It returns an error if the [Monitor] is already started.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.started {
+		return errors.New("health check monitor already started")
+	}
+
+	m.started = true
+	m.context, m.contextCancelFunc = context.WithCancel(ctx)
+
+	m.waitGroup.Add(1)
+
+	go func(ctx context.Context) {
+		defer m.waitGroup.Done()
+
+		ticker := time.NewTicker(m.options.Interval)
+		defer ticker.Stop()
+
+		m.Run(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Run(ctx)
+			}
+		}
+	}(m.context)
+
+	return nil
+}
+
+// Stop gracefully stops the background execution of the probes.
+func (m *Monitor) Stop() error {
+	m.mutex.Lock()
+	if m.contextCancelFunc != nil {
+		m.contextCancelFunc()
+	}
+	m.mutex.Unlock()
+
+	m.waitGroup.Wait()
+
+	m.mutex.Lock()
+	m.started = false
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// Run performs a single execution round of all the registered probes, and records their results.
+func (m *Monitor) Run(ctx context.Context) {
+	for _, probe := range m.checker.Probes() {
+		m.record(ctx, m.execute(ctx, probe))
+	}
+}
+
+// History returns the recorded executions of a [CheckerProbe], from the oldest to the most recent.
+func (m *Monitor) History(probeName string) []*MonitorRecord {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	history := make([]*MonitorRecord, len(m.history[probeName]))
+	copy(history, m.history[probeName])
+
+	return history
+}
+
+// Histories returns the recorded executions of all the [CheckerProbe], indexed by probe name.
+func (m *Monitor) Histories() map[string][]*MonitorRecord {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	histories := make(map[string][]*MonitorRecord, len(m.history))
+	for name, history := range m.history {
+		histories[name] = append([]*MonitorRecord{}, history...)
+	}
+
+	return histories
+}
+
+// Last returns the most recent execution record of a [CheckerProbe], or nil if it was never executed.
+func (m *Monitor) Last(probeName string) *MonitorRecord {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if history := m.history[probeName]; len(history) > 0 {
+		return history[len(history)-1]
+	}
+
+	return nil
+}
+
+func (m *Monitor) execute(ctx context.Context, probe CheckerProbe) *MonitorRecord {
+	probeCtx := ctx
+	if m.options.Timeout > 0 {
+		var probeCtxCancel context.CancelFunc
+		probeCtx, probeCtxCancel = context.WithTimeout(ctx, m.options.Timeout)
+		defer probeCtxCancel()
+	}
+
+	start := time.Now()
+	result := probe.Check(probeCtx)
+
+	return &MonitorRecord{
+		Probe:    probe.Name(),
+		Success:  result.Success,
+		Message:  result.Message,
+		Time:     start,
+		Duration: time.Since(start),
+	}
+}
+
+func (m *Monitor) record(ctx context.Context, record *MonitorRecord) {
+	m.mutex.Lock()
+
+	var previous *MonitorRecord
+	if history := m.history[record.Probe]; len(history) > 0 {
+		previous = history[len(history)-1]
+	}
+
+	history := append(m.history[record.Probe], record)
+	if len(history) > m.options.HistorySize {
+		history = history[len(history)-m.options.HistorySize:]
+	}
+
+	m.history[record.Probe] = history
+
+	m.mutex.Unlock()
+
+	if m.options.Metrics != nil {
+		m.options.Metrics.Observe(record)
+	}
+
+	if (previous == nil && !record.Success) || (previous != nil && previous.Success != record.Success) {
+		m.notify(ctx, &MonitorEvent{
+			Previous: previous,
+			Current:  record,
+		})
+	}
+}
+
+func (m *Monitor) notify(ctx context.Context, event *MonitorEvent) {
+	logger := log.CtxLogger(ctx)
+
+	if event.Current.Success {
+		logger.Info().
+			Str("probe", event.Current.Probe).
+			Str("probeMessage", event.Current.Message).
+			Msg("health check probe recovered")
+	} else {
+		logger.Warn().
+			Str("probe", event.Current.Probe).
+			Str("probeMessage", event.Current.Message).
+			Msg("health check probe failed")
+	}
+
+	for _, fn := range m.options.StatusChangeFuncs {
+		fn(ctx, event)
+	}
+}
@@ -0,0 +1,204 @@
+package healthcheck_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/probes"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMonitor(t *testing.T) {
+	t.Parallel()
+
+	monitor := healthcheck.NewMonitor(
+		healthcheck.NewChecker(),
+		healthcheck.WithMonitorInterval(time.Second),
+		healthcheck.WithMonitorTimeout(0),
+		healthcheck.WithMonitorHistorySize(5),
+	)
+
+	assert.IsType(t, &healthcheck.Monitor{}, monitor)
+	assert.Equal(t, time.Second, monitor.Options().Interval)
+	assert.Equal(t, time.Duration(0), monitor.Options().Timeout)
+	assert.Equal(t, 5, monitor.Options().HistorySize)
+	assert.Len(t, monitor.Histories(), 0)
+	assert.Nil(t, monitor.Last("invalid"))
+}
+
+func TestMonitorRun(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	ctx := logger.WithContext(context.Background())
+
+	registry := prometheus.NewPedanticRegistry()
+	metrics := healthcheck.NewMonitorMetrics("foo", "bar")
+	err = metrics.Register(registry)
+	assert.NoError(t, err)
+
+	var events []*healthcheck.MonitorEvent
+
+	switchProbe := probes.NewSwitchProbe(true)
+
+	checker := healthcheck.NewChecker().
+		RegisterProbe(probes.NewSuccessProbe()).
+		RegisterProbe(switchProbe, healthcheck.Readiness)
+
+	monitor := healthcheck.NewMonitor(
+		checker,
+		healthcheck.WithMonitorHistorySize(2),
+		healthcheck.WithMonitorMetrics(metrics),
+		healthcheck.WithMonitorStatusChangeFunc(func(ctx context.Context, event *healthcheck.MonitorEvent) {
+			events = append(events, event)
+		}),
+	)
+
+	// first round: all success, no event
+	monitor.Run(ctx)
+	assert.Len(t, events, 0)
+	assert.True(t, monitor.Last("switchProbe").Success)
+
+	// second round: switch probe failure
+	switchProbe.Switch(false)
+	monitor.Run(ctx)
+	assert.Len(t, events, 1)
+	assert.True(t, events[0].Previous.Success)
+	assert.False(t, events[0].Current.Success)
+	assert.Equal(t, "switch off", events[0].Current.Message)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "warn",
+		"probe":   "switchProbe",
+		"message": "health check probe failed",
+	})
+
+	expectedMetric := `
+		# HELP foo_bar_healthcheck_probe_status Status of the last background probe execution (1 for success, 0 for failure)
+		# TYPE foo_bar_healthcheck_probe_status gauge
+		foo_bar_healthcheck_probe_status{probe="successProbe"} 1
+		foo_bar_healthcheck_probe_status{probe="switchProbe"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_healthcheck_probe_status",
+	)
+	assert.NoError(t, err)
+
+	// third round: switch probe recovery
+	switchProbe.Switch(true)
+	monitor.Run(ctx)
+	assert.Len(t, events, 2)
+	assert.False(t, events[1].Previous.Success)
+	assert.True(t, events[1].Current.Success)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"probe":   "switchProbe",
+		"message": "health check probe recovered",
+	})
+
+	// bounded history
+	history := monitor.History("switchProbe")
+	assert.Len(t, history, 2)
+	assert.False(t, history[0].Success)
+	assert.True(t, history[1].Success)
+
+	histories := monitor.Histories()
+	assert.Len(t, histories, 2)
+	assert.Len(t, histories["successProbe"], 2)
+}
+
+func TestMonitorRunWithInitialFailure(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	var events []*healthcheck.MonitorEvent
+
+	monitor := healthcheck.NewMonitor(
+		healthcheck.NewChecker().RegisterProbe(probes.NewFailureProbe()),
+		healthcheck.WithMonitorStatusChangeFunc(func(ctx context.Context, event *healthcheck.MonitorEvent) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			events = append(events, event)
+		}),
+	)
+
+	monitor.Run(context.Background())
+	monitor.Run(context.Background())
+
+	assert.Len(t, events, 1)
+	assert.Nil(t, events[0].Previous)
+	assert.False(t, events[0].Current.Success)
+	assert.Len(t, monitor.History("failureProbe"), 2)
+}
+
+func TestMonitorStartAndStop(t *testing.T) {
+	t.Parallel()
+
+	monitor := healthcheck.NewMonitor(
+		healthcheck.NewChecker().RegisterProbe(probes.NewSuccessProbe()),
+		healthcheck.WithMonitorInterval(5*time.Millisecond),
+	)
+
+	err := monitor.Start(context.Background())
+	assert.NoError(t, err)
+
+	err = monitor.Start(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "health check monitor already started", err.Error())
+
+	assert.Eventually(
+		t,
+		func() bool {
+			return len(monitor.History("successProbe")) >= 2
+		},
+		time.Second,
+		5*time.Millisecond,
+	)
+
+	err = monitor.Stop()
+	assert.NoError(t, err)
+
+	count := len(monitor.History("successProbe"))
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, monitor.History("successProbe"), count)
+
+	// restart after stop
+	err = monitor.Start(context.Background())
+	assert.NoError(t, err)
+
+	err = monitor.Stop()
+	assert.NoError(t, err)
+}
+
+func TestMonitorRunWithoutMetrics(t *testing.T) {
+	t.Parallel()
+
+	monitor := healthcheck.NewMonitor(
+		healthcheck.NewChecker().RegisterProbe(probes.NewSuccessProbe()),
+		healthcheck.WithMonitorMetrics(nil),
+	)
+
+	assert.NotPanics(t, func() {
+		monitor.Run(context.Background())
+	})
+
+	assert.True(t, monitor.Last("successProbe").Success)
+}
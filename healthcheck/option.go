@@ -1,5 +1,7 @@
 package healthcheck
 
+import "time"
+
 // Options are options for the [CheckerFactory] implementations.
 type Options struct {
 	Registrations map[string]*CheckerProbeRegistration
@@ -30,3 +32,73 @@ func WithProbe(probe CheckerProbe, kinds ...ProbeKind) CheckerOption {
 		}
 	}
 }
+
+const (
+	DefaultMonitorInterval    = 10 * time.Second
+	DefaultMonitorTimeout     = 5 * time.Second
+	DefaultMonitorHistorySize = 100
+	DefaultMetricsNamespace   = ""
+	DefaultMetricsSubsystem   = ""
+)
+
+// MonitorOptions are options for the [Monitor].
+type MonitorOptions struct {
+	Interval          time.Duration
+	Timeout           time.Duration
+	HistorySize       int
+	Metrics           *MonitorMetrics
+	StatusChangeFuncs []MonitorStatusChangeFunc
+}
+
+// DefaultMonitorOptions are the default options used by the [Monitor].
+func DefaultMonitorOptions() MonitorOptions {
+	return MonitorOptions{
+		Interval:          DefaultMonitorInterval,
+		Timeout:           DefaultMonitorTimeout,
+		HistorySize:       DefaultMonitorHistorySize,
+		Metrics:           NewMonitorMetrics(DefaultMetricsNamespace, DefaultMetricsSubsystem),
+		StatusChangeFuncs: []MonitorStatusChangeFunc{},
+	}
+}
+
+// MonitorOption are functional options for the [Monitor].
+type MonitorOption func(o *MonitorOptions)
+
+// WithMonitorInterval is used to specify the interval between two executions rounds of the [Monitor].
+func WithMonitorInterval(interval time.Duration) MonitorOption {
+	return func(o *MonitorOptions) {
+		if interval > 0 {
+			o.Interval = interval
+		}
+	}
+}
+
+// WithMonitorTimeout is used to specify the timeout of each probe execution of the [Monitor] (0 to disable).
+func WithMonitorTimeout(timeout time.Duration) MonitorOption {
+	return func(o *MonitorOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithMonitorHistorySize is used to specify the max number of executions records kept per probe by the [Monitor].
+func WithMonitorHistorySize(size int) MonitorOption {
+	return func(o *MonitorOptions) {
+		if size > 0 {
+			o.HistorySize = size
+		}
+	}
+}
+
+// WithMonitorMetrics is used to specify the [MonitorMetrics] to use by the [Monitor].
+func WithMonitorMetrics(metrics *MonitorMetrics) MonitorOption {
+	return func(o *MonitorOptions) {
+		o.Metrics = metrics
+	}
+}
+
+// WithMonitorStatusChangeFunc is used to register a [MonitorStatusChangeFunc] invoked on probes status changes.
+func WithMonitorStatusChangeFunc(fn MonitorStatusChangeFunc) MonitorOption {
+	return func(o *MonitorOptions) {
+		o.StatusChangeFuncs = append(o.StatusChangeFuncs, fn)
+	}
+}
@@ -0,0 +1,20 @@
+package healthcheck
+
+import (
+	"github.com/ankorstore/yokai/log"
+)
+
+// ShutdownRecorder records the steps reported by the modules while stopping (like an http server waiting for its
+// in-flight requests), to expose them next to the failing readiness during the application shutdown.
+type ShutdownRecorder interface {
+	Record(module string, message string)
+}
+
+// RecordShutdown logs a module shutdown step with the module logger, and records it on the [ShutdownRecorder] if provided.
+func RecordShutdown(logger *log.Logger, recorder ShutdownRecorder, module string, message string) {
+	logger.Info().Msg(message)
+
+	if recorder != nil {
+		recorder.Record(module, message)
+	}
+}
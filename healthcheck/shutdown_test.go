@@ -0,0 +1,36 @@
+package healthcheck_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/healthcheck"
+	"github.com/ankorstore/yokai/healthcheck/testdata/shutdown"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordShutdown(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(log.WithOutputWriter(logBuffer))
+	assert.NoError(t, err)
+
+	recorder := shutdown.NewTestShutdownRecorder()
+
+	healthcheck.RecordShutdown(logger, recorder, "test", "stopped")
+	healthcheck.RecordShutdown(logger, nil, "test", "stopped without recorder")
+
+	assert.Equal(t, []string{"test: stopped"}, recorder.Messages())
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "stopped",
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "info",
+		"message": "stopped without recorder",
+	})
+}
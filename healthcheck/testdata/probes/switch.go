@@ -0,0 +1,35 @@
+package probes
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ankorstore/yokai/healthcheck"
+)
+
+type SwitchProbe struct {
+	success atomic.Bool
+}
+
+func NewSwitchProbe(success bool) *SwitchProbe {
+	p := &SwitchProbe{}
+	p.success.Store(success)
+
+	return p
+}
+
+func (p *SwitchProbe) Name() string {
+	return "switchProbe"
+}
+
+func (p *SwitchProbe) Switch(success bool) {
+	p.success.Store(success)
+}
+
+func (p *SwitchProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	if p.success.Load() {
+		return healthcheck.NewCheckerProbeResult(true, "switch on")
+	}
+
+	return healthcheck.NewCheckerProbeResult(false, "switch off")
+}
@@ -0,0 +1,29 @@
+package shutdown
+
+import (
+	"fmt"
+	"sync"
+)
+
+type TestShutdownRecorder struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func NewTestShutdownRecorder() *TestShutdownRecorder {
+	return &TestShutdownRecorder{}
+}
+
+func (r *TestShutdownRecorder) Record(module string, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.messages = append(r.messages, fmt.Sprintf("%s: %s", module, message))
+}
+
+func (r *TestShutdownRecorder) Messages() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string{}, r.messages...)
+}
@@ -0,0 +1,22 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+)
+
+// SecretFunc returns a secret (token, API key, client secret, ...) used by the auth transports, resolved at request
+// time, for example from a secrets manager.
+type SecretFunc func(ctx context.Context) (string, error)
+
+// StaticSecret returns a [SecretFunc] returning the provided static secret.
+func StaticSecret(secret string) SecretFunc {
+	return func(context.Context) (string, error) {
+		return secret, nil
+	}
+}
+
+// replayable returns true if the request body can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	DefaultBearerScheme = "Bearer"
+)
+
+// ErrMissingSecret is returned by the auth transports when their secret is empty.
+var ErrMissingSecret = errors.New("missing secret")
+
+// BearerTransport is a wrapper around [http.RoundTripper] with some [BearerTransportConfig] configuration.
+type BearerTransport struct {
+	transport http.RoundTripper
+	config    *BearerTransportConfig
+}
+
+// BearerTransportConfig is the configuration of the [BearerTransport].
+type BearerTransportConfig struct {
+	Secret SecretFunc
+	Header string
+	Scheme string
+}
+
+// NewBearerTransport returns a [BearerTransport] instance sending the provided secret as Authorization: Bearer token.
+func NewBearerTransport(base http.RoundTripper, secret SecretFunc) *BearerTransport {
+	return NewBearerTransportWithConfig(
+		base,
+		&BearerTransportConfig{
+			Secret: secret,
+			Header: HeaderAuthorization,
+			Scheme: DefaultBearerScheme,
+		},
+	)
+}
+
+// NewBearerTransportWithConfig returns a [BearerTransport] instance for a provided [BearerTransportConfig]
+// configuration. For example, an API key can be sent with the X-Api-Key Header and an empty Scheme.
+func NewBearerTransportWithConfig(base http.RoundTripper, config *BearerTransportConfig) *BearerTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.Secret == nil {
+		config.Secret = StaticSecret("")
+	}
+
+	if config.Header == "" {
+		config.Header = HeaderAuthorization
+	}
+
+	return &BearerTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *BearerTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The secret is resolved for each request, and sent in the configured Header, prefixed by the Scheme if any. The
+// requests already having this header are sent untouched.
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(t.config.Header) != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	secret, err := t.config.Secret(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve secret: %w", err)
+	}
+
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+
+	if t.config.Scheme != "" {
+		secret = fmt.Sprintf("%s %s", t.config.Scheme, secret)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(t.config.Header, secret)
+
+	return t.transport.RoundTrip(req)
+}
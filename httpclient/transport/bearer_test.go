@@ -0,0 +1,145 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/httpclienttest"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httpclienttest.NewTestHTTPServer(
+		t,
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				assert.Equal(tb, "Bearer token", req.Header.Get("Authorization"))
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			},
+		),
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				assert.Equal(tb, "Basic other", req.Header.Get("Authorization"))
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			},
+		),
+	)
+	defer server.Close()
+
+	trans := transport.NewBearerTransport(nil, transport.StaticSecret("token"))
+	assert.IsType(t, &transport.BearerTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	// resolved secret
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// existing header
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	req.Header.Set("Authorization", "Basic other")
+
+	resp, err = trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestBearerTransportRoundTripWithConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httpclienttest.NewTestHTTPServer(
+		t,
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				assert.Equal(tb, "key", req.Header.Get("X-Api-Key"))
+				assert.Empty(tb, req.Header.Get("Authorization"))
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			},
+		),
+	)
+	defer server.Close()
+
+	secret := "key"
+
+	trans := transport.NewBearerTransportWithConfig(
+		nil,
+		&transport.BearerTransportConfig{
+			Secret: func(context.Context) (string, error) {
+				if secret == "" {
+					return "", errors.New("resolver error")
+				}
+
+				return secret, nil
+			},
+			Header: "X-Api-Key",
+		},
+	)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// resolver error
+	secret = ""
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Equal(t, "cannot resolve secret: resolver error", err.Error())
+
+	// missing secret
+	//nolint:bodyclose
+	_, err = transport.NewBearerTransportWithConfig(nil, &transport.BearerTransportConfig{}).RoundTrip(req)
+	assert.ErrorIs(t, err, transport.ErrMissingSecret)
+}
@@ -0,0 +1,449 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+)
+
+const (
+	HeaderCacheStatus      = "X-Cache-Status"
+	CacheStatusHit         = "hit"
+	CacheStatusMiss        = "miss"
+	CacheStatusRevalidated = "revalidated"
+)
+
+// DefaultCacheStatusCodes are the response status codes cached by default by the [CacheTransport].
+var DefaultCacheStatusCodes = []int{
+	http.StatusOK,
+	http.StatusNonAuthoritativeInfo,
+	http.StatusMultipleChoices,
+	http.StatusMovedPermanently,
+	http.StatusNotFound,
+	http.StatusGone,
+}
+
+// CacheTransport is a wrapper around [http.RoundTripper] with some [CacheTransportConfig] configuration.
+type CacheTransport struct {
+	transport http.RoundTripper
+	config    *CacheTransportConfig
+}
+
+// CacheTransportConfig is the configuration of the [CacheTransport].
+type CacheTransportConfig struct {
+	Store       CacheStore
+	StatusCodes []int
+	MaxBodySize int64
+	KeyPrefix   string
+}
+
+// NewCacheTransport returns a [CacheTransport] instance with default [CacheTransportConfig] configuration, caching
+// the responses in a [MemoryCacheStore] of 1000 entries.
+func NewCacheTransport(base http.RoundTripper) *CacheTransport {
+	return NewCacheTransportWithConfig(
+		base,
+		&CacheTransportConfig{
+			Store:       NewMemoryCacheStore(1000),
+			StatusCodes: DefaultCacheStatusCodes,
+			MaxBodySize: 1 << 20,
+		},
+	)
+}
+
+// NewCacheTransportWithConfig returns a [CacheTransport] instance for a provided [CacheTransportConfig] configuration.
+// The KeyPrefix isolates the cached responses of several transports sharing a same [CacheStore].
+func NewCacheTransportWithConfig(base http.RoundTripper, config *CacheTransportConfig) *CacheTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore(1000)
+	}
+
+	if config.StatusCodes == nil {
+		config.StatusCodes = DefaultCacheStatusCodes
+	}
+
+	return &CacheTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *CacheTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The GET responses are cached as a private cache, according to their Cache-Control (or Expires) freshness. The fresh
+// cached responses are served without calling the wrapped [http.RoundTripper], and the stale ones are revalidated
+// with their ETag and Last-Modified validators. The responses cache status (hit, miss or revalidated) is provided in
+// the X-Cache-Status header. The successful requests with unsafe methods invalidate the cached response of their url.
+//
+//nolint:cyclop
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if req.Method != http.MethodGet {
+		resp, err := t.transport.RoundTrip(req)
+		if err == nil && !safeMethod(req.Method) && resp.StatusCode < http.StatusBadRequest {
+			t.delete(ctx, t.cacheKey(req))
+		}
+
+		return resp, err
+	}
+
+	reqCacheControl := parseCacheControl(req.Header)
+	if reqCacheControl.has("no-store") || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" || req.Header.Get("Range") != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := t.cacheKey(req)
+
+	entry := t.load(ctx, key)
+	if entry != nil && !entry.matches(req) {
+		entry = nil
+	}
+
+	now := time.Now()
+
+	if entry != nil && !reqCacheControl.has("no-cache") && !entry.NoCache && now.Before(entry.ExpiresAt) {
+		resp, err := entry.response(req, now, CacheStatusHit)
+		if err == nil {
+			t.observe(req, CacheStatusHit)
+
+			return resp, nil
+		}
+	}
+
+	// conditional request, to revalidate the cached response
+	outReq := req
+	if entry != nil && (entry.ETag != "" || entry.LastModified != "") {
+		outReq = req.Clone(ctx)
+
+		if entry.ETag != "" {
+			outReq.Header.Set("If-None-Match", entry.ETag)
+		}
+
+		if entry.LastModified != "" {
+			outReq.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(outReq)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && outReq != req {
+		drainBody(resp.Body)
+
+		entry.refresh(resp.Header, now)
+		t.store(ctx, key, entry)
+
+		cachedResp, err := entry.response(req, now, CacheStatusRevalidated)
+		if err != nil {
+			return nil, err
+		}
+
+		t.observe(req, CacheStatusRevalidated)
+
+		return cachedResp, nil
+	}
+
+	t.observe(req, CacheStatusMiss)
+
+	resp.Header.Set(HeaderCacheStatus, CacheStatusMiss)
+
+	if !slices.Contains(t.config.StatusCodes, resp.StatusCode) {
+		return resp, nil
+	}
+
+	newEntry, ok := newCacheEntry(req, resp, now)
+	if !ok {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.config.MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	if int64(len(body)) > t.config.MaxBodySize {
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+
+		return resp, nil
+	}
+
+	err = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot close response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	newEntry.Response, err = dumpResponse(resp, body)
+	if err == nil {
+		t.store(ctx, key, newEntry)
+	}
+
+	return resp, nil
+}
+
+func (t *CacheTransport) load(ctx context.Context, key string) *cacheEntry {
+	value, ok, err := t.config.Store.Get(ctx, key)
+	if err != nil {
+		log.CtxLogger(ctx).Warn().Err(err).Str("key", key).Msg("http client cache load failure")
+
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+
+	var entry cacheEntry
+
+	err = json.Unmarshal(value, &entry)
+	if err != nil {
+		log.CtxLogger(ctx).Warn().Err(err).Str("key", key).Msg("http client cache decode failure")
+
+		return nil
+	}
+
+	return &entry
+}
+
+func (t *CacheTransport) store(ctx context.Context, key string, entry *cacheEntry) {
+	value, err := json.Marshal(entry)
+	if err == nil {
+		err = t.config.Store.Set(ctx, key, value)
+	}
+
+	if err != nil {
+		log.CtxLogger(ctx).Warn().Err(err).Str("key", key).Msg("http client cache store failure")
+	}
+}
+
+func (t *CacheTransport) delete(ctx context.Context, key string) {
+	err := t.config.Store.Delete(ctx, key)
+	if err != nil {
+		log.CtxLogger(ctx).Warn().Err(err).Str("key", key).Msg("http client cache delete failure")
+	}
+}
+
+func (t *CacheTransport) observe(req *http.Request, status string) {
+	log.CtxLogger(req.Context()).Debug().
+		Str("method", req.Method).
+		Str("url", req.URL.String()).
+		Str("cache", status).
+		Msg("http client cache")
+}
+
+// cacheEntry is a cached response, with its freshness and validators.
+type cacheEntry struct {
+	Response     []byte            `json:"response"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	StoredAt     time.Time         `json:"stored_at"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	NoCache      bool              `json:"no_cache,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+}
+
+// newCacheEntry returns a [cacheEntry] for a cacheable response, without its dump, and false if not cacheable.
+func newCacheEntry(req *http.Request, resp *http.Response, now time.Time) (*cacheEntry, bool) {
+	respCacheControl := parseCacheControl(resp.Header)
+	if respCacheControl.has("no-store") {
+		return nil, false
+	}
+
+	entry := &cacheEntry{
+		StoredAt:     now,
+		NoCache:      respCacheControl.has("no-cache"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	for _, name := range resp.Header.Values("Vary") {
+		for _, header := range strings.Split(name, ",") {
+			header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+			if header == "*" {
+				return nil, false
+			}
+
+			if header != "" {
+				if entry.Vary == nil {
+					entry.Vary = map[string]string{}
+				}
+
+				entry.Vary[header] = req.Header.Get(header)
+			}
+		}
+	}
+
+	lifetime, ok := freshnessLifetime(resp.Header, respCacheControl)
+	if !ok && entry.ETag == "" && entry.LastModified == "" {
+		return nil, false
+	}
+
+	entry.ExpiresAt = now.Add(lifetime - responseAge(resp.Header))
+
+	return entry, true
+}
+
+// matches returns true if the request matches the cached response Vary headers.
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// refresh updates the cached response freshness and headers from a 304 response.
+func (e *cacheEntry) refresh(header http.Header, now time.Time) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(e.Response)), nil)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	for name, values := range header {
+		resp.Header[name] = values
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	dump, err := dumpResponse(resp, body)
+	if err != nil {
+		return
+	}
+
+	cacheControl := parseCacheControl(resp.Header)
+	lifetime, _ := freshnessLifetime(resp.Header, cacheControl)
+
+	e.Response = dump
+	e.StoredAt = now
+	e.ExpiresAt = now.Add(lifetime - responseAge(header))
+	e.NoCache = cacheControl.has("no-cache")
+	e.ETag = resp.Header.Get("ETag")
+	e.LastModified = resp.Header.Get("Last-Modified")
+}
+
+// response returns the cached response for the provided request, with its cache status.
+func (e *cacheEntry) response(req *http.Request, now time.Time, status string) (*http.Response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(e.Response)), req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cached response: %w", err)
+	}
+
+	age := max(now.Sub(e.StoredAt), 0)
+	resp.Header.Set("Age", strconv.FormatInt(int64(age.Seconds()), 10))
+	resp.Header.Set(HeaderCacheStatus, status)
+
+	return resp, nil
+}
+
+// cacheControl holds the parsed Cache-Control directives.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	directives := cacheControl{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+
+	return directives
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+
+	return ok
+}
+
+// freshnessLifetime returns the response freshness lifetime, from its max-age or Expires header, and false if none.
+func freshnessLifetime(header http.Header, cacheControl cacheControl) (time.Duration, bool) {
+	if maxAge, ok := cacheControl["max-age"]; ok {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0, true
+		}
+
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+
+		return max(expiresAt.Sub(date), 0), true
+	}
+
+	return 0, false
+}
+
+// responseAge returns the response Age header, if any.
+func responseAge(header http.Header) time.Duration {
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func dumpResponse(resp *http.Response, body []byte) ([]byte, error) {
+	clone := *resp
+	clone.Header = resp.Header.Clone()
+	clone.Header.Del(HeaderCacheStatus)
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	clone.TransferEncoding = nil
+
+	return httputil.DumpResponse(&clone, true)
+}
+
+func (t *CacheTransport) cacheKey(req *http.Request) string {
+	if t.config.KeyPrefix != "" {
+		return fmt.Sprintf("%s:%s %s", t.config.KeyPrefix, http.MethodGet, req.URL.String())
+	}
+
+	return fmt.Sprintf("%s %s", http.MethodGet, req.URL.String())
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || method == http.MethodTrace
+}
@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheStore is the interface for the [CacheTransport] responses storage.
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCacheStore is an in memory [CacheStore], evicting the least recently used entries.
+type MemoryCacheStore struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value []byte
+}
+
+// NewMemoryCacheStore returns a [MemoryCacheStore] instance, holding up to maxEntries entries (unlimited if 0).
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get returns the value stored for a key, and false if not found.
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	s.lru.MoveToFront(element)
+
+	//nolint:forcetypeassert
+	return element.Value.(*memoryCacheEntry).value, true, nil
+}
+
+// Set stores a value for a key, evicting the least recently used entry if the store is full.
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		//nolint:forcetypeassert
+		element.Value.(*memoryCacheEntry).value = value
+		s.lru.MoveToFront(element)
+
+		return nil
+	}
+
+	s.entries[key] = s.lru.PushFront(&memoryCacheEntry{key: key, value: value})
+
+	if s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+
+		//nolint:forcetypeassert
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+// Delete removes the value stored for a key.
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.lru.Remove(element)
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+// Len returns the number of stored entries.
+func (s *MemoryCacheStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.lru.Len()
+}
+
+// FileCacheStore is a file based [CacheStore], storing each entry in a file of a directory.
+type FileCacheStore struct {
+	dir string
+}
+
+// NewFileCacheStore returns a [FileCacheStore] instance, storing the entries in the provided directory.
+func NewFileCacheStore(dir string) (*FileCacheStore, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %w", err)
+	}
+
+	return &FileCacheStore{
+		dir: dir,
+	}, nil
+}
+
+// Get returns the value stored for a key, and false if not found.
+func (s *FileCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("cannot read cache file: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// Set stores a value for a key, replacing its file atomically.
+func (s *FileCacheStore) Set(_ context.Context, key string, value []byte) error {
+	file, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot create cache file: %w", err)
+	}
+
+	_, err = file.Write(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), s.path(key))
+	}
+
+	if err != nil {
+		//nolint:errcheck
+		os.Remove(file.Name())
+
+		return fmt.Errorf("cannot write cache file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the value stored for a key.
+func (s *FileCacheStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot delete cache file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileCacheStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
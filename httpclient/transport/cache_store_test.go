@@ -0,0 +1,88 @@
+package transport_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCacheStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := transport.NewMemoryCacheStore(2)
+	assert.Implements(t, (*transport.CacheStore)(nil), store)
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("a")))
+	assert.NoError(t, store.Set(ctx, "b", []byte("b")))
+
+	// a becomes the most recently used entry
+	value, ok, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), value)
+
+	// b is evicted
+	assert.NoError(t, store.Set(ctx, "c", []byte("c")))
+	assert.Equal(t, 2, store.Len())
+
+	_, ok, err = store.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// update
+	assert.NoError(t, store.Set(ctx, "c", []byte("updated")))
+
+	value, ok, err = store.Get(ctx, "c")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("updated"), value)
+
+	// delete
+	assert.NoError(t, store.Delete(ctx, "c"))
+	assert.NoError(t, store.Delete(ctx, "invalid"))
+
+	_, ok, err = store.Get(ctx, "c")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestFileCacheStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	store, err := transport.NewFileCacheStore(dir)
+	assert.NoError(t, err)
+	assert.Implements(t, (*transport.CacheStore)(nil), store)
+
+	_, ok, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("a")))
+	assert.NoError(t, store.Set(ctx, "a", []byte("updated")))
+
+	value, ok, err := store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("updated"), value)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, store.Delete(ctx, "a"))
+	assert.NoError(t, store.Delete(ctx, "a"))
+
+	_, ok, err = store.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
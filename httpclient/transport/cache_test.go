@@ -0,0 +1,300 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+		case "/last-modified":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+
+			if r.Header.Get("If-Modified-Since") == "Mon, 01 Jan 2024 00:00:00 GMT" {
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		case "/error":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		_, err := w.Write([]byte("body " + r.URL.Path + " " + r.Header.Get("Accept-Language")))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewCacheTransportWithConfig(
+		nil,
+		&transport.CacheTransportConfig{
+			Store:       transport.NewMemoryCacheStore(10),
+			MaxBodySize: 1024,
+		},
+	)
+	assert.IsType(t, &transport.CacheTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	roundTrip := func(method string, path string, header map[string]string) (*http.Response, string) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL+path, nil)
+		assert.NoError(t, err)
+
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		return resp, string(body)
+	}
+
+	// fresh response
+	resp, body := roundTrip(http.MethodGet, "/fresh", nil)
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /fresh ", body)
+
+	resp, body = roundTrip(http.MethodGet, "/fresh", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, transport.CacheStatusHit, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /fresh ", body)
+	assert.Equal(t, "max-age=60", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// request no-cache forces a new request
+	resp, _ = roundTrip(http.MethodGet, "/fresh", map[string]string{"Cache-Control": "no-cache"})
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// unsafe method invalidation
+	resp, _ = roundTrip(http.MethodPost, "/fresh", nil)
+	assert.Empty(t, resp.Header.Get(transport.HeaderCacheStatus))
+
+	resp, _ = roundTrip(http.MethodGet, "/fresh", nil)
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(4), calls.Load())
+
+	// etag revalidation
+	resp, body = roundTrip(http.MethodGet, "/etag", nil)
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /etag ", body)
+
+	resp, body = roundTrip(http.MethodGet, "/etag", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, transport.CacheStatusRevalidated, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /etag ", body)
+	assert.Equal(t, int32(6), calls.Load())
+
+	// last modified revalidation
+	roundTrip(http.MethodGet, "/last-modified", nil)
+
+	resp, body = roundTrip(http.MethodGet, "/last-modified", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, transport.CacheStatusRevalidated, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /last-modified ", body)
+	assert.Equal(t, int32(8), calls.Load())
+
+	// no-store response
+	roundTrip(http.MethodGet, "/no-store", nil)
+
+	resp, _ = roundTrip(http.MethodGet, "/no-store", nil)
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(10), calls.Load())
+
+	// vary response
+	roundTrip(http.MethodGet, "/vary", map[string]string{"Accept-Language": "en"})
+
+	resp, body = roundTrip(http.MethodGet, "/vary", map[string]string{"Accept-Language": "en"})
+	assert.Equal(t, transport.CacheStatusHit, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /vary en", body)
+
+	resp, body = roundTrip(http.MethodGet, "/vary", map[string]string{"Accept-Language": "fr"})
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, "body /vary fr", body)
+	assert.Equal(t, int32(12), calls.Load())
+
+	// not cacheable status
+	roundTrip(http.MethodGet, "/error", nil)
+
+	resp, _ = roundTrip(http.MethodGet, "/error", nil)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(14), calls.Load())
+
+}
+
+func TestCacheTransportRoundTripWithMaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		_, err := w.Write([]byte(strings.Repeat("a", 20)))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	trans := transport.NewCacheTransportWithConfig(
+		nil,
+		&transport.CacheTransportConfig{
+			MaxBodySize: 10,
+		},
+	)
+
+	for range 2 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, strings.Repeat("a", 20), string(body))
+		assert.Equal(t, transport.CacheStatusMiss, resp.Header.Get(transport.HeaderCacheStatus))
+	}
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCacheTransportWithFileCacheStore(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		_, err := w.Write([]byte("body"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	store, err := transport.NewFileCacheStore(t.TempDir())
+	assert.NoError(t, err)
+
+	client := &http.Client{
+		Transport: transport.NewCacheTransportWithConfig(
+			nil,
+			&transport.CacheTransportConfig{
+				Store:       store,
+				MaxBodySize: 1024,
+			},
+		),
+	}
+
+	for _, expectedStatus := range []string{transport.CacheStatusMiss, transport.CacheStatusHit} {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, "body", string(body))
+		assert.Equal(t, expectedStatus, resp.Header.Get(transport.HeaderCacheStatus))
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCacheTransportRoundTripWithKeyPrefix(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		_, err := w.Write([]byte("body"))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	store := transport.NewMemoryCacheStore(10)
+
+	fooTrans := transport.NewCacheTransportWithConfig(nil, &transport.CacheTransportConfig{
+		Store:       store,
+		MaxBodySize: 1024,
+		KeyPrefix:   "foo",
+	})
+
+	barTrans := transport.NewCacheTransportWithConfig(nil, &transport.CacheTransportConfig{
+		Store:       store,
+		MaxBodySize: 1024,
+		KeyPrefix:   "bar",
+	})
+
+	roundTrip := func(trans http.RoundTripper) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		return resp
+	}
+
+	assert.Equal(t, transport.CacheStatusMiss, roundTrip(fooTrans).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusHit, roundTrip(fooTrans).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusMiss, roundTrip(barTrans).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, transport.CacheStatusHit, roundTrip(barTrans).Header.Get(transport.HeaderCacheStatus))
+	assert.Equal(t, int32(2), calls.Load())
+
+	value, ok, err := store.Get(context.Background(), "foo:GET "+server.URL)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NotEmpty(t, value)
+}
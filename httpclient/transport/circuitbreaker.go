@@ -0,0 +1,338 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HttpClientMetricsCircuitBreakerState      = "http_client_circuit_breaker_state"
+	HttpClientMetricsCircuitBreakerRejections = "http_client_circuit_breaker_rejections_total"
+	CircuitBreakerRejectionSpanEventName      = "http client circuit breaker rejection"
+	CircuitBreakerTransitionSpanEventName     = "http client circuit breaker transition"
+)
+
+// ErrCircuitBreakerOpen is returned by the [CircuitBreakerTransport] when the circuit of the request host is open.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+// DefaultCircuitBreakerFailureStatusCodes are the response status codes counted as failures by default by the
+// [CircuitBreakerTransport].
+var DefaultCircuitBreakerFailureStatusCodes = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// CircuitBreakerState is the state of a circuit, for a host.
+type CircuitBreakerState int
+
+const (
+	CircuitBreakerClosed CircuitBreakerState = iota
+	CircuitBreakerHalfOpen
+	CircuitBreakerOpen
+)
+
+// String returns a string representation of the [CircuitBreakerState].
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	case CircuitBreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerTransport is a wrapper around [http.RoundTripper] with some [CircuitBreakerTransportConfig] configuration.
+type CircuitBreakerTransport struct {
+	transport       http.RoundTripper
+	config          *CircuitBreakerTransportConfig
+	circuits        map[string]*circuit
+	mutex           sync.Mutex
+	stateGauge      *prometheus.GaugeVec
+	rejectedCounter *prometheus.CounterVec
+}
+
+// CircuitBreakerTransportConfig is the configuration of the [CircuitBreakerTransport].
+type CircuitBreakerTransportConfig struct {
+	FailureThreshold   int
+	OpenTimeout        time.Duration
+	HalfOpenRequests   int
+	FailureStatusCodes []int
+	Registry           prometheus.Registerer
+	Namespace          string
+	Subsystem          string
+}
+
+// NewCircuitBreakerTransport returns a [CircuitBreakerTransport] instance with default [CircuitBreakerTransportConfig] configuration.
+func NewCircuitBreakerTransport(base http.RoundTripper) *CircuitBreakerTransport {
+	return NewCircuitBreakerTransportWithConfig(
+		base,
+		&CircuitBreakerTransportConfig{
+			FailureThreshold:   5,
+			OpenTimeout:        30 * time.Second,
+			HalfOpenRequests:   1,
+			FailureStatusCodes: DefaultCircuitBreakerFailureStatusCodes,
+		},
+	)
+}
+
+// NewCircuitBreakerTransportWithConfig returns a [CircuitBreakerTransport] instance for a provided
+// [CircuitBreakerTransportConfig] configuration. The circuits states and rejections are observed in the
+// http_client_circuit_breaker_state and http_client_circuit_breaker_rejections_total metrics if a Registry is provided.
+func NewCircuitBreakerTransportWithConfig(base http.RoundTripper, config *CircuitBreakerTransportConfig) *CircuitBreakerTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+
+	circuitBreakerTransport := &CircuitBreakerTransport{
+		transport: base,
+		config:    config,
+		circuits:  make(map[string]*circuit),
+	}
+
+	if config.Registry != nil {
+		circuitBreakerTransport.stateGauge = RegisterCollector(
+			config.Registry,
+			prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: config.Namespace,
+					Subsystem: config.Subsystem,
+					Name:      HttpClientMetricsCircuitBreakerState,
+					Help:      "State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)",
+				},
+				[]string{
+					"host",
+				},
+			),
+		)
+
+		circuitBreakerTransport.rejectedCounter = RegisterCollector(
+			config.Registry,
+			prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: config.Namespace,
+					Subsystem: config.Subsystem,
+					Name:      HttpClientMetricsCircuitBreakerRejections,
+					Help:      "Number of HTTP requests rejected by open circuit breakers",
+				},
+				[]string{
+					"host",
+				},
+			),
+		)
+	}
+
+	return circuitBreakerTransport
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *CircuitBreakerTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// State returns the [CircuitBreakerState] of a host circuit, for example https://example.com.
+func (t *CircuitBreakerTransport) State(host string) CircuitBreakerState {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if c, ok := t.circuits[host]; ok {
+		return c.currentState(time.Now(), t.config)
+	}
+
+	return CircuitBreakerClosed
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The circuit of the request host opens after FailureThreshold consecutive failures (transport errors or failure
+// response status codes), and the requests are then rejected with [ErrCircuitBreakerOpen] during OpenTimeout. After
+// that, HalfOpenRequests requests are let through: the circuit closes if they succeed, and opens again otherwise.
+func (t *CircuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := requestHost(req)
+	c := t.circuit(host)
+
+	from, to, allowed := c.allow(time.Now(), t.config)
+	t.observeTransition(req, host, from, to)
+
+	if !allowed {
+		if t.rejectedCounter != nil {
+			t.rejectedCounter.WithLabelValues(host).Inc()
+		}
+
+		trace.SpanFromContext(req.Context()).AddEvent(
+			CircuitBreakerRejectionSpanEventName,
+			trace.WithAttributes(attribute.String("http.circuit_breaker.host", host)),
+		)
+
+		return nil, fmt.Errorf("%w for host %s", ErrCircuitBreakerOpen, host)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		from, to = c.release()
+	case err != nil || slices.Contains(t.config.FailureStatusCodes, resp.StatusCode):
+		from, to = c.record(false, time.Now(), t.config)
+	default:
+		from, to = c.record(true, time.Now(), t.config)
+	}
+
+	t.observeTransition(req, host, from, to)
+
+	return resp, err
+}
+
+func (t *CircuitBreakerTransport) circuit(host string) *circuit {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	c, ok := t.circuits[host]
+	if !ok {
+		c = &circuit{}
+		t.circuits[host] = c
+
+		if t.stateGauge != nil {
+			t.stateGauge.WithLabelValues(host).Set(float64(CircuitBreakerClosed))
+		}
+	}
+
+	return c
+}
+
+func (t *CircuitBreakerTransport) observeTransition(req *http.Request, host string, from CircuitBreakerState, to CircuitBreakerState) {
+	if from == to {
+		return
+	}
+
+	if t.stateGauge != nil {
+		t.stateGauge.WithLabelValues(host).Set(float64(to))
+	}
+
+	trace.SpanFromContext(req.Context()).AddEvent(
+		CircuitBreakerTransitionSpanEventName,
+		trace.WithAttributes(
+			attribute.String("http.circuit_breaker.host", host),
+			attribute.String("http.circuit_breaker.from", from.String()),
+			attribute.String("http.circuit_breaker.to", to.String()),
+		),
+	)
+}
+
+// circuit is the circuit breaker state machine of a host.
+type circuit struct {
+	mutex             sync.Mutex
+	state             CircuitBreakerState
+	failures          int
+	openedAt          time.Time
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+func (c *circuit) currentState(now time.Time, config *CircuitBreakerTransportConfig) CircuitBreakerState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state == CircuitBreakerOpen && now.Sub(c.openedAt) >= config.OpenTimeout {
+		return CircuitBreakerHalfOpen
+	}
+
+	return c.state
+}
+
+func (c *circuit) allow(now time.Time, config *CircuitBreakerTransportConfig) (CircuitBreakerState, CircuitBreakerState, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	from := c.state
+
+	if c.state == CircuitBreakerOpen {
+		if now.Sub(c.openedAt) < config.OpenTimeout {
+			return from, c.state, false
+		}
+
+		c.state = CircuitBreakerHalfOpen
+		c.halfOpenInFlight = 0
+		c.halfOpenSuccesses = 0
+	}
+
+	if c.state == CircuitBreakerHalfOpen {
+		if c.halfOpenInFlight >= config.HalfOpenRequests {
+			return from, c.state, false
+		}
+
+		c.halfOpenInFlight++
+	}
+
+	return from, c.state, true
+}
+
+func (c *circuit) record(success bool, now time.Time, config *CircuitBreakerTransportConfig) (CircuitBreakerState, CircuitBreakerState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	from := c.state
+
+	switch c.state {
+	case CircuitBreakerClosed:
+		if success {
+			c.failures = 0
+		} else {
+			c.failures++
+			if c.failures >= config.FailureThreshold {
+				c.open(now)
+			}
+		}
+	case CircuitBreakerHalfOpen:
+		c.halfOpenInFlight = max(c.halfOpenInFlight-1, 0)
+
+		if success {
+			c.halfOpenSuccesses++
+			if c.halfOpenSuccesses >= config.HalfOpenRequests {
+				c.state = CircuitBreakerClosed
+				c.failures = 0
+			}
+		} else {
+			c.open(now)
+		}
+	case CircuitBreakerOpen:
+	}
+
+	return from, c.state
+}
+
+func (c *circuit) release() (CircuitBreakerState, CircuitBreakerState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state == CircuitBreakerHalfOpen {
+		c.halfOpenInFlight = max(c.halfOpenInFlight-1, 0)
+	}
+
+	return c.state, c.state
+}
+
+func (c *circuit) open(now time.Time) {
+	c.state = CircuitBreakerOpen
+	c.openedAt = now
+	c.failures = 0
+}
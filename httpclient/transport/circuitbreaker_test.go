@@ -0,0 +1,249 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCircuitBreakerState(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "closed", transport.CircuitBreakerClosed.String())
+	assert.Equal(t, "half-open", transport.CircuitBreakerHalfOpen.String())
+	assert.Equal(t, "open", transport.CircuitBreakerOpen.String())
+}
+
+func TestCircuitBreakerTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	trans := transport.NewCircuitBreakerTransport(nil)
+	assert.IsType(t, &transport.CircuitBreakerTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	resp, err := trans.RoundTrip(httptest.NewRequest(http.MethodGet, server.URL, nil))
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State(server.URL))
+}
+
+//nolint:maintidx
+func TestCircuitBreakerTransportRoundTripWithConfig(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	var failing atomic.Bool
+	var calls atomic.Int32
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+
+		if req.URL.Host == "other.example.com" {
+			return nil, errors.New("connection refused")
+		}
+
+		if failing.Load() {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	trans := transport.NewCircuitBreakerTransportWithConfig(
+		base,
+		&transport.CircuitBreakerTransportConfig{
+			FailureThreshold:   2,
+			OpenTimeout:        50 * time.Millisecond,
+			HalfOpenRequests:   1,
+			FailureStatusCodes: transport.DefaultCircuitBreakerFailureStatusCodes,
+			Registry:           registry,
+			Namespace:          "foo",
+			Subsystem:          "bar",
+		},
+	)
+
+	host := "https://example.com"
+
+	roundTrip := func(ctx context.Context, target string) (*http.Response, error) {
+		//nolint:noctx
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		assert.NoError(t, err)
+
+		return trans.RoundTrip(req.WithContext(ctx))
+	}
+
+	// closed: failures below threshold
+	failing.Store(true)
+
+	//nolint:bodyclose
+	resp, err := roundTrip(context.Background(), host)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State(host))
+
+	// closed: success resets the failures
+	failing.Store(false)
+
+	//nolint:bodyclose
+	_, err = roundTrip(context.Background(), host)
+	assert.NoError(t, err)
+
+	failing.Store(true)
+
+	//nolint:bodyclose
+	_, err = roundTrip(context.Background(), host)
+	assert.NoError(t, err)
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State(host))
+
+	// open: threshold reached
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "test")
+
+	//nolint:bodyclose
+	_, err = roundTrip(ctx, host)
+	assert.NoError(t, err)
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(host))
+
+	calls.Store(0)
+
+	//nolint:bodyclose
+	_, err = roundTrip(ctx, host)
+	assert.ErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+	assert.Equal(t, "circuit breaker is open for host https://example.com", err.Error())
+	assert.Equal(t, int32(0), calls.Load())
+
+	span.End()
+
+	// other hosts have their own circuit
+	//nolint:bodyclose
+	_, err = roundTrip(context.Background(), "https://other.example.com")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State("https://other.example.com"))
+
+	// half-open: failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, transport.CircuitBreakerHalfOpen, trans.State(host))
+
+	//nolint:bodyclose
+	_, err = roundTrip(context.Background(), host)
+	assert.NoError(t, err)
+	assert.Equal(t, transport.CircuitBreakerOpen, trans.State(host))
+
+	// half-open: successful probe closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	failing.Store(false)
+
+	//nolint:bodyclose
+	resp, err = roundTrip(context.Background(), host)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State(host))
+
+	// cancelled requests are not counted as failures
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for range 3 {
+		//nolint:bodyclose
+		_, err = roundTrip(cancelledCtx, "https://other.example.com")
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, transport.CircuitBreakerClosed, trans.State("https://other.example.com"))
+
+	// metrics assertions
+	expectedMetric := `
+		# HELP foo_bar_http_client_circuit_breaker_rejections_total Number of HTTP requests rejected by open circuit breakers
+		# TYPE foo_bar_http_client_circuit_breaker_rejections_total counter
+		foo_bar_http_client_circuit_breaker_rejections_total{host="https://example.com"} 1
+		# HELP foo_bar_http_client_circuit_breaker_state State of the HTTP client circuit breakers (0 closed, 1 half-open, 2 open)
+		# TYPE foo_bar_http_client_circuit_breaker_state gauge
+		foo_bar_http_client_circuit_breaker_state{host="https://example.com"} 0
+		foo_bar_http_client_circuit_breaker_state{host="https://other.example.com"} 0
+	`
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"foo_bar_http_client_circuit_breaker_rejections_total",
+		"foo_bar_http_client_circuit_breaker_state",
+	)
+	assert.NoError(t, err)
+
+	// span events assertions
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Len(t, spans[0].Events, 2)
+
+	assert.Equal(t, transport.CircuitBreakerTransitionSpanEventName, spans[0].Events[0].Name)
+	assert.Contains(t, spans[0].Events[0].Attributes, sdkAttribute("http.circuit_breaker.from", "closed"))
+	assert.Contains(t, spans[0].Events[0].Attributes, sdkAttribute("http.circuit_breaker.to", "open"))
+
+	assert.Equal(t, transport.CircuitBreakerRejectionSpanEventName, spans[0].Events[1].Name)
+	assert.Contains(t, spans[0].Events[1].Attributes, sdkAttribute("http.circuit_breaker.host", host))
+}
+
+func TestCircuitBreakerTransportWithHttpClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: transport.NewRetryTransport(
+			transport.NewCircuitBreakerTransportWithConfig(
+				nil,
+				&transport.CircuitBreakerTransportConfig{
+					FailureThreshold:   1,
+					OpenTimeout:        time.Minute,
+					FailureStatusCodes: transport.DefaultCircuitBreakerFailureStatusCodes,
+				},
+			),
+		),
+	}
+
+	//nolint:noctx
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	//nolint:noctx,bodyclose
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+
+	var urlErr *url.Error
+	assert.True(t, errors.As(err, &urlErr))
+	assert.Equal(t, fmt.Sprintf("circuit breaker is open for host %s", server.URL), urlErr.Err.Error())
+}
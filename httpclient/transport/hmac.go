@@ -0,0 +1,237 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultHMACSignatureHeader = "X-Signature"
+	DefaultHMACTimestampHeader = "X-Signature-Timestamp"
+	DefaultHMACKeyIDHeader     = "X-Signature-Key-Id"
+	HMACComponentMethod        = "method"
+	HMACComponentHost          = "host"
+	HMACComponentPath          = "path"
+	HMACComponentQuery         = "query"
+	HMACComponentHeaders       = "headers"
+	HMACComponentTimestamp     = "timestamp"
+	HMACComponentBody          = "body"
+)
+
+// DefaultHMACComponents are the request components signed by default by the [HMACTransport], in this order.
+var DefaultHMACComponents = []string{
+	HMACComponentMethod,
+	HMACComponentPath,
+	HMACComponentQuery,
+	HMACComponentHeaders,
+	HMACComponentTimestamp,
+	HMACComponentBody,
+}
+
+// HMACCanonicalizer returns the canonical string of a request to sign, for its body and signature timestamp.
+type HMACCanonicalizer func(req *http.Request, body []byte, timestamp time.Time) string
+
+// NewHMACCanonicalizer returns a [HMACCanonicalizer] joining with new lines the provided request components, in the
+// provided order:
+//   - method: the request method
+//   - host: the request host
+//   - path: the request escaped path
+//   - query: the request query, with sorted parameters
+//   - headers: the provided signed headers, as lowercase name:value lines, in the provided order
+//   - timestamp: the signature unix timestamp, in seconds
+//   - body: the hex encoded SHA-256 hash of the request body
+func NewHMACCanonicalizer(components []string, signedHeaders []string) (HMACCanonicalizer, error) {
+	for _, component := range components {
+		switch component {
+		case HMACComponentMethod,
+			HMACComponentHost,
+			HMACComponentPath,
+			HMACComponentQuery,
+			HMACComponentHeaders,
+			HMACComponentTimestamp,
+			HMACComponentBody:
+		default:
+			return nil, fmt.Errorf("invalid hmac component %s", component)
+		}
+	}
+
+	return func(req *http.Request, body []byte, timestamp time.Time) string {
+		lines := make([]string, 0, len(components))
+
+		for _, component := range components {
+			switch component {
+			case HMACComponentMethod:
+				lines = append(lines, strings.ToUpper(req.Method))
+			case HMACComponentHost:
+				lines = append(lines, requestHostHeader(req))
+			case HMACComponentPath:
+				path := req.URL.EscapedPath()
+				if path == "" {
+					path = "/"
+				}
+
+				lines = append(lines, path)
+			case HMACComponentQuery:
+				lines = append(lines, req.URL.Query().Encode())
+			case HMACComponentHeaders:
+				for _, name := range signedHeaders {
+					name = strings.ToLower(name)
+
+					value := strings.Join(req.Header.Values(name), ",")
+					if name == "host" {
+						value = requestHostHeader(req)
+					}
+
+					lines = append(lines, fmt.Sprintf("%s:%s", name, strings.TrimSpace(value)))
+				}
+			case HMACComponentTimestamp:
+				lines = append(lines, strconv.FormatInt(timestamp.Unix(), 10))
+			case HMACComponentBody:
+				bodyHash := sha256.Sum256(body)
+				lines = append(lines, hex.EncodeToString(bodyHash[:]))
+			}
+		}
+
+		return strings.Join(lines, "\n")
+	}, nil
+}
+
+// HMACTransport is a wrapper around [http.RoundTripper] with some [HMACTransportConfig] configuration, signing the
+// requests with HMAC.
+type HMACTransport struct {
+	transport http.RoundTripper
+	config    *HMACTransportConfig
+}
+
+// HMACTransportConfig is the configuration of the [HMACTransport].
+type HMACTransportConfig struct {
+	KeyID           string
+	Secret          SecretFunc
+	Hash            func() hash.Hash
+	Canonicalizer   HMACCanonicalizer
+	SignatureHeader string
+	TimestampHeader string
+	KeyIDHeader     string
+}
+
+// NewHMACTransport returns a [HMACTransport] instance with default [HMACTransportConfig] configuration, signing the
+// requests with HMAC-SHA256 and the provided secret.
+func NewHMACTransport(base http.RoundTripper, secret SecretFunc) *HMACTransport {
+	//nolint:errcheck
+	canonicalizer, _ := NewHMACCanonicalizer(DefaultHMACComponents, nil)
+
+	return NewHMACTransportWithConfig(
+		base,
+		&HMACTransportConfig{
+			Secret:          secret,
+			Hash:            sha256.New,
+			Canonicalizer:   canonicalizer,
+			SignatureHeader: DefaultHMACSignatureHeader,
+			TimestampHeader: DefaultHMACTimestampHeader,
+			KeyIDHeader:     DefaultHMACKeyIDHeader,
+		},
+	)
+}
+
+// NewHMACTransportWithConfig returns a [HMACTransport] instance for a provided [HMACTransportConfig] configuration.
+func NewHMACTransportWithConfig(base http.RoundTripper, config *HMACTransportConfig) *HMACTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.Secret == nil {
+		config.Secret = StaticSecret("")
+	}
+
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+
+	if config.Canonicalizer == nil {
+		//nolint:errcheck
+		config.Canonicalizer, _ = NewHMACCanonicalizer(DefaultHMACComponents, nil)
+	}
+
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultHMACSignatureHeader
+	}
+
+	if config.TimestampHeader == "" {
+		config.TimestampHeader = DefaultHMACTimestampHeader
+	}
+
+	if config.KeyIDHeader == "" {
+		config.KeyIDHeader = DefaultHMACKeyIDHeader
+	}
+
+	return &HMACTransport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *HMACTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The request canonical string, built by the Canonicalizer, is signed with the secret, and the base64 encoded
+// signature is sent in the SignatureHeader, with the signature timestamp in the TimestampHeader, and the KeyID (if
+// any) in the KeyIDHeader.
+func (t *HMACTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	secret, err := t.config.Secret(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve secret: %w", err)
+	}
+
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+
+	req = req.Clone(req.Context())
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read request body: %w", err)
+		}
+
+		err = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot close request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	timestamp := time.Now()
+
+	req.Header.Set(t.config.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+
+	if t.config.KeyID != "" {
+		req.Header.Set(t.config.KeyIDHeader, t.config.KeyID)
+	}
+
+	mac := hmac.New(t.config.Hash, []byte(secret))
+	mac.Write([]byte(t.config.Canonicalizer(req, body, timestamp)))
+
+	req.Header.Set(t.config.SignatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	return t.transport.RoundTrip(req)
+}
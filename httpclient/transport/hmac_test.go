@@ -0,0 +1,166 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/httpclienttest"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHMACCanonicalizer(t *testing.T) {
+	t.Parallel()
+
+	canonicalizer, err := transport.NewHMACCanonicalizer(
+		[]string{"method", "host", "path", "query", "headers", "timestamp", "body"},
+		[]string{"Content-Type", "host", "X-Missing"},
+	)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://example.com/foo%20bar?b=2&a=1", nil)
+	assert.NoError(t, err)
+
+	req.Header.Set("Content-Type", " application/json ")
+
+	expected := "POST\n" +
+		"example.com\n" +
+		"/foo%20bar\n" +
+		"a=1&b=2\n" +
+		"content-type:application/json\n" +
+		"host:example.com\n" +
+		"x-missing:\n" +
+		"1700000000\n" +
+		"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	assert.Equal(t, expected, canonicalizer(req, []byte("hello"), time.Unix(1700000000, 0)))
+
+	_, err = transport.NewHMACCanonicalizer([]string{"method", "invalid"}, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid hmac component invalid", err.Error())
+}
+
+func TestHMACTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	canonicalizer, err := transport.NewHMACCanonicalizer(transport.DefaultHMACComponents, nil)
+	assert.NoError(t, err)
+
+	server := httpclienttest.NewTestHTTPServer(
+		t,
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				body, err := io.ReadAll(req.Body)
+				assert.NoError(tb, err)
+				assert.Equal(tb, "payload", string(body))
+
+				timestamp, err := strconv.ParseInt(req.Header.Get(transport.DefaultHMACTimestampHeader), 10, 64)
+				assert.NoError(tb, err)
+				assert.InDelta(tb, time.Now().Unix(), timestamp, 5)
+
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write([]byte(canonicalizer(req, body, time.Unix(timestamp, 0))))
+
+				assert.Equal(tb, base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get(transport.DefaultHMACSignatureHeader))
+				assert.Empty(tb, req.Header.Get(transport.DefaultHMACKeyIDHeader))
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			},
+		),
+	)
+	defer server.Close()
+
+	trans := transport.NewHMACTransport(nil, transport.StaticSecret("secret"))
+	assert.IsType(t, &transport.HMACTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, server.URL+"/foo?bar=baz", io.NopCloser(bytes.NewBufferString("payload")))
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, req.Header.Get(transport.DefaultHMACSignatureHeader))
+}
+
+func TestHMACTransportRoundTripWithConfig(t *testing.T) {
+	t.Parallel()
+
+	canonicalizer, err := transport.NewHMACCanonicalizer([]string{"method", "path", "headers"}, []string{"X-Sig-Key", "X-Sig-Time"})
+	assert.NoError(t, err)
+
+	server := httpclienttest.NewTestHTTPServer(
+		t,
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				assert.Equal(tb, "key-id", req.Header.Get("X-Sig-Key"))
+				assert.NotEmpty(tb, req.Header.Get("X-Sig-Time"))
+
+				mac := hmac.New(sha512.New, []byte("secret"))
+				mac.Write([]byte(canonicalizer(req, nil, time.Time{})))
+
+				assert.Equal(tb, base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Sig"))
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusNoContent)
+
+				return nil
+			},
+		),
+	)
+	defer server.Close()
+
+	trans := transport.NewHMACTransportWithConfig(
+		nil,
+		&transport.HMACTransportConfig{
+			KeyID:           "key-id",
+			Secret:          transport.StaticSecret("secret"),
+			Hash:            sha512.New,
+			Canonicalizer:   canonicalizer,
+			SignatureHeader: "X-Sig",
+			TimestampHeader: "X-Sig-Time",
+			KeyIDHeader:     "X-Sig-Key",
+		},
+	)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// missing secret
+	//nolint:bodyclose
+	_, err = transport.NewHMACTransportWithConfig(nil, &transport.HMACTransportConfig{}).RoundTrip(req)
+	assert.ErrorIs(t, err, transport.ErrMissingSecret)
+}
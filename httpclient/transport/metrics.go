@@ -31,6 +31,7 @@ type MetricsTransportConfig struct {
 	NormalizeRequestPath      bool
 	NormalizeRequestPathMasks map[string]string
 	NormalizeResponseStatus   bool
+	CollectCacheStatus        bool
 }
 
 // NewMetricsTransport returns a [MetricsTransport] instance with default [MetricsTransportConfig] configuration.
@@ -45,11 +46,14 @@ func NewMetricsTransport(base http.RoundTripper) *MetricsTransport {
 			NormalizeRequestPath:      false,
 			NormalizeRequestPathMasks: map[string]string{},
 			NormalizeResponseStatus:   true,
+			CollectCacheStatus:        false,
 		},
 	)
 }
 
 // NewMetricsTransportWithConfig returns a [MetricsTransport] instance for a provided [MetricsTransportConfig] configuration.
+// If CollectCacheStatus is enabled, the requests counter is labelled by the X-Cache-Status response header, provided
+// by a decorated [CacheTransport].
 func NewMetricsTransportWithConfig(base http.RoundTripper, config *MetricsTransportConfig) *MetricsTransport {
 	if base == nil {
 		base = NewBaseTransport()
@@ -59,6 +63,17 @@ func NewMetricsTransportWithConfig(base http.RoundTripper, config *MetricsTransp
 		config.Registry = prometheus.DefaultRegisterer
 	}
 
+	requestsCounterLabels := []string{
+		"status",
+		"method",
+		"host",
+		"path",
+	}
+
+	if config.CollectCacheStatus {
+		requestsCounterLabels = append(requestsCounterLabels, "cache")
+	}
+
 	requestsCounter := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: config.Namespace,
@@ -66,12 +81,7 @@ func NewMetricsTransportWithConfig(base http.RoundTripper, config *MetricsTransp
 			Name:      HttpClientMetricsRequestsCount,
 			Help:      "Number of performed HTTP requests",
 		},
-		[]string{
-			"status",
-			"method",
-			"host",
-			"path",
-		},
+		requestsCounterLabels,
 	)
 
 	requestsDuration := prometheus.NewHistogramVec(
@@ -125,6 +135,7 @@ func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	timer.ObserveDuration()
 
 	respStatus := ""
+	respCacheStatus := ""
 
 	if err != nil {
 		respStatus = "error"
@@ -134,9 +145,15 @@ func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		} else {
 			respStatus = strconv.Itoa(resp.StatusCode)
 		}
+
+		respCacheStatus = resp.Header.Get(HeaderCacheStatus)
 	}
 
-	t.requestsCounter.WithLabelValues(respStatus, req.Method, host, path).Inc()
+	if t.config.CollectCacheStatus {
+		t.requestsCounter.WithLabelValues(respStatus, req.Method, host, path, respCacheStatus).Inc()
+	} else {
+		t.requestsCounter.WithLabelValues(respStatus, req.Method, host, path).Inc()
+	}
 
 	return resp, err
 }
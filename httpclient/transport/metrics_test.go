@@ -126,6 +126,69 @@ func TestMetricsTransportRoundTripWithBaseAndConfig(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMetricsTransportRoundTripWithCacheStatus(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	trans := transport.NewMetricsTransportWithConfig(
+		transport.NewCacheTransport(nil),
+		&transport.MetricsTransportConfig{
+			Registry:                registry,
+			Namespace:               "foo",
+			Subsystem:               "bar",
+			Buckets:                 []float64{1, 2, 3},
+			NormalizeResponseStatus: true,
+			CollectCacheStatus:      true,
+		},
+	)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, server.URL+"/foo", nil)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, server.URL+"/foo", nil)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	// requests counter assertions
+	expectedCounterMetric := fmt.Sprintf(
+		`
+			# HELP foo_bar_http_client_requests_total Number of performed HTTP requests
+			# TYPE foo_bar_http_client_requests_total counter
+			foo_bar_http_client_requests_total{cache="",host="%s",method="POST",path="/foo",status="2xx"} 1
+			foo_bar_http_client_requests_total{cache="hit",host="%s",method="GET",path="/foo",status="2xx"} 2
+			foo_bar_http_client_requests_total{cache="miss",host="%s",method="GET",path="/foo",status="2xx"} 1
+		`,
+		server.URL,
+		server.URL,
+		server.URL,
+	)
+
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedCounterMetric),
+		"foo_bar_http_client_requests_total",
+	)
+	assert.NoError(t, err)
+}
+
 func TestMetricsTransportRoundTripWithFailure(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,254 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OAuth2AuthStyleHeader = "header"
+	OAuth2AuthStyleParams = "params"
+)
+
+// ErrOAuth2TokenRequest is returned by the [OAuth2Transport] when the token cannot be fetched.
+var ErrOAuth2TokenRequest = errors.New("oauth2 token request failed")
+
+// OAuth2Transport is a wrapper around [http.RoundTripper] with some [OAuth2TransportConfig] configuration,
+// authenticating the requests with tokens fetched with the OAuth2 client credentials grant.
+type OAuth2Transport struct {
+	transport http.RoundTripper
+	config    *OAuth2TransportConfig
+	mutex     sync.Mutex
+	token     *oauth2Token
+}
+
+// OAuth2TransportConfig is the configuration of the [OAuth2Transport].
+type OAuth2TransportConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret SecretFunc
+	Scopes       []string
+	Params       url.Values
+	AuthStyle    string
+	ExpiryDelta  time.Duration
+	Client       *http.Client
+}
+
+// NewOAuth2Transport returns an [OAuth2Transport] instance with default [OAuth2TransportConfig] configuration, for
+// the provided token url and client credentials.
+func NewOAuth2Transport(base http.RoundTripper, tokenURL string, clientID string, clientSecret SecretFunc) *OAuth2Transport {
+	return NewOAuth2TransportWithConfig(
+		base,
+		&OAuth2TransportConfig{
+			TokenURL:     tokenURL,
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			AuthStyle:    OAuth2AuthStyleHeader,
+			ExpiryDelta:  10 * time.Second,
+		},
+	)
+}
+
+// NewOAuth2TransportWithConfig returns an [OAuth2Transport] instance for a provided [OAuth2TransportConfig]
+// configuration. The tokens are fetched with the provided Client, or with a client based on a [BaseTransport].
+func NewOAuth2TransportWithConfig(base http.RoundTripper, config *OAuth2TransportConfig) *OAuth2Transport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.ClientSecret == nil {
+		config.ClientSecret = StaticSecret("")
+	}
+
+	if config.AuthStyle == "" {
+		config.AuthStyle = OAuth2AuthStyleHeader
+	}
+
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Transport: NewBaseTransport(),
+			Timeout:   30 * time.Second,
+		}
+	}
+
+	return &OAuth2Transport{
+		transport: base,
+		config:    config,
+	}
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *OAuth2Transport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The token is cached, and fetched again ExpiryDelta before its expiration. If the response status code is 401, the
+// token is invalidated, and the request is sent again once with a new token (if its body can be replayed). The
+// requests already having an Authorization header are sent untouched.
+func (t *OAuth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(HeaderAuthorization) != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	token, err := t.fetchToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transport.RoundTrip(t.authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !replayable(req) {
+		return resp, err
+	}
+
+	drainBody(resp.Body)
+	t.invalidateToken(token)
+
+	token, err = t.fetchToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	retryReq, err := rewindRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.transport.RoundTrip(t.authorize(retryReq, token))
+}
+
+func (t *OAuth2Transport) authorize(req *http.Request, token *oauth2Token) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderAuthorization, fmt.Sprintf("%s %s", token.tokenType, token.accessToken))
+
+	return req
+}
+
+func (t *OAuth2Transport) fetchToken(ctx context.Context) (*oauth2Token, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.token != nil && t.token.valid(time.Now(), t.config.ExpiryDelta) {
+		return t.token, nil
+	}
+
+	token, err := t.requestToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.token = token
+
+	return token, nil
+}
+
+func (t *OAuth2Transport) invalidateToken(token *oauth2Token) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.token == token {
+		t.token = nil
+	}
+}
+
+//nolint:cyclop
+func (t *OAuth2Transport) requestToken(ctx context.Context) (*oauth2Token, error) {
+	clientSecret, err := t.config.ClientSecret(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot resolve client secret: %w", ErrOAuth2TokenRequest, err)
+	}
+
+	params := url.Values{}
+	for name, values := range t.config.Params {
+		params[name] = values
+	}
+
+	params.Set("grant_type", "client_credentials")
+
+	if len(t.config.Scopes) > 0 {
+		params.Set("scope", strings.Join(t.config.Scopes, " "))
+	}
+
+	if t.config.AuthStyle == OAuth2AuthStyleParams {
+		params.Set("client_id", t.config.ClientID)
+		params.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOAuth2TokenRequest, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if t.config.AuthStyle != OAuth2AuthStyleParams {
+		req.SetBasicAuth(url.QueryEscape(t.config.ClientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOAuth2TokenRequest, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read response: %w", ErrOAuth2TokenRequest, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", ErrOAuth2TokenRequest, resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	err = json.Unmarshal(body, &tokenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode response: %w", ErrOAuth2TokenRequest, err)
+	}
+
+	if tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("%w: missing access token", ErrOAuth2TokenRequest)
+	}
+
+	token := &oauth2Token{
+		accessToken: tokenResponse.AccessToken,
+		tokenType:   tokenResponse.TokenType,
+	}
+
+	if token.tokenType == "" || strings.EqualFold(token.tokenType, DefaultBearerScheme) {
+		token.tokenType = DefaultBearerScheme
+	}
+
+	if tokenResponse.ExpiresIn > 0 {
+		token.expiresAt = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// oauth2Token is a cached OAuth2 token, without expiration if expiresAt is zero.
+type oauth2Token struct {
+	accessToken string
+	tokenType   string
+	expiresAt   time.Time
+}
+
+func (t *oauth2Token) valid(now time.Time, expiryDelta time.Duration) bool {
+	return t.expiresAt.IsZero() || now.Add(expiryDelta).Before(t.expiresAt)
+}
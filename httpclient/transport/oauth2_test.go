@@ -0,0 +1,244 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/httpclienttest"
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func tokenRoundTrip(token string, expiresIn int, assertions func(tb testing.TB, req *http.Request)) httpclienttest.TestHTTPServerOptionFunc {
+	return httpclienttest.WithTestHTTPRoundTrip(
+		func(tb testing.TB, req *http.Request) error {
+			tb.Helper()
+
+			assert.Equal(tb, http.MethodPost, req.Method)
+			assert.Equal(tb, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+
+			err := req.ParseForm()
+			assert.NoError(tb, err)
+
+			assert.Equal(tb, "client_credentials", req.PostForm.Get("grant_type"))
+
+			if assertions != nil {
+				assertions(tb, req)
+			}
+
+			return nil
+		},
+		func(tb testing.TB, w http.ResponseWriter) error {
+			tb.Helper()
+
+			w.Header().Set("Content-Type", "application/json")
+
+			_, err := fmt.Fprintf(w, `{"access_token":"%s","token_type":"bearer","expires_in":%d}`, token, expiresIn)
+
+			return err
+		},
+	)
+}
+
+func resourceRoundTrip(expectedAuthorization string, expectedBody string, status int) httpclienttest.TestHTTPServerOptionFunc {
+	return httpclienttest.WithTestHTTPRoundTrip(
+		func(tb testing.TB, req *http.Request) error {
+			tb.Helper()
+
+			assert.Equal(tb, expectedAuthorization, req.Header.Get("Authorization"))
+
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(tb, err)
+			assert.Equal(tb, expectedBody, string(body))
+
+			return nil
+		},
+		func(tb testing.TB, w http.ResponseWriter) error {
+			tb.Helper()
+
+			w.WriteHeader(status)
+
+			return nil
+		},
+	)
+}
+
+func TestOAuth2TransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tokenServer := httpclienttest.NewTestHTTPServer(
+		t,
+		tokenRoundTrip("token-1", 3600, func(tb testing.TB, req *http.Request) {
+			tb.Helper()
+
+			clientID, clientSecret, ok := req.BasicAuth()
+			assert.True(tb, ok)
+			assert.Equal(tb, "client%40id", clientID)
+			assert.Equal(tb, "secret", clientSecret)
+			assert.Equal(tb, "read write", req.PostForm.Get("scope"))
+			assert.Equal(tb, "https://api.example.com", req.PostForm.Get("audience"))
+		}),
+		tokenRoundTrip("token-2", 3600, nil),
+	)
+	defer tokenServer.Close()
+
+	resourceServer := httpclienttest.NewTestHTTPServer(
+		t,
+		resourceRoundTrip("Bearer token-1", "", http.StatusNoContent),
+		resourceRoundTrip("Bearer token-1", "payload", http.StatusUnauthorized),
+		resourceRoundTrip("Bearer token-2", "payload", http.StatusOK),
+		resourceRoundTrip("Bearer token-2", "", http.StatusUnauthorized),
+	)
+	defer resourceServer.Close()
+
+	trans := transport.NewOAuth2TransportWithConfig(
+		nil,
+		&transport.OAuth2TransportConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "client@id",
+			ClientSecret: transport.StaticSecret("secret"),
+			Scopes:       []string{"read", "write"},
+			Params:       url.Values{"audience": {"https://api.example.com"}},
+			ExpiryDelta:  10 * time.Second,
+		},
+	)
+	assert.IsType(t, &transport.OAuth2Transport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	// fetched token
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, resourceServer.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// cached token, refreshed on unauthorized response
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, resourceServer.URL, bytes.NewBufferString("payload"))
+	assert.NoError(t, err)
+
+	resp, err = trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// not replayable request on unauthorized response
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, resourceServer.URL, io.NopCloser(bytes.NewBufferString("")))
+	assert.NoError(t, err)
+
+	resp, err = trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestOAuth2TransportRoundTripWithExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	tokenServer := httpclienttest.NewTestHTTPServer(
+		t,
+		tokenRoundTrip("token-1", 5, func(tb testing.TB, req *http.Request) {
+			tb.Helper()
+
+			_, _, ok := req.BasicAuth()
+			assert.False(tb, ok)
+			assert.Equal(tb, "client", req.PostForm.Get("client_id"))
+			assert.Equal(tb, "secret", req.PostForm.Get("client_secret"))
+		}),
+		tokenRoundTrip("token-2", 5, nil),
+	)
+	defer tokenServer.Close()
+
+	resourceServer := httpclienttest.NewTestHTTPServer(
+		t,
+		resourceRoundTrip("Bearer token-1", "", http.StatusNoContent),
+		resourceRoundTrip("Bearer token-2", "", http.StatusNoContent),
+	)
+	defer resourceServer.Close()
+
+	trans := transport.NewOAuth2TransportWithConfig(
+		nil,
+		&transport.OAuth2TransportConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "client",
+			ClientSecret: transport.StaticSecret("secret"),
+			AuthStyle:    transport.OAuth2AuthStyleParams,
+			ExpiryDelta:  10 * time.Second,
+		},
+	)
+
+	for range 2 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, resourceServer.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestOAuth2TransportRoundTripWithTokenRequestFailure(t *testing.T) {
+	t.Parallel()
+
+	tokenServer := httpclienttest.NewTestHTTPServer(
+		t,
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				w.WriteHeader(http.StatusBadRequest)
+
+				return nil
+			},
+		),
+		httpclienttest.WithTestHTTPRoundTrip(
+			func(tb testing.TB, req *http.Request) error {
+				tb.Helper()
+
+				return nil
+			},
+			func(tb testing.TB, w http.ResponseWriter) error {
+				tb.Helper()
+
+				_, err := w.Write([]byte(`{"token_type":"bearer"}`))
+
+				return err
+			},
+		),
+	)
+	defer tokenServer.Close()
+
+	trans := transport.NewOAuth2Transport(nil, tokenServer.URL, "client", transport.StaticSecret("secret"))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com", nil)
+	assert.NoError(t, err)
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(req)
+	assert.ErrorIs(t, err, transport.ErrOAuth2TokenRequest)
+	assert.Equal(t, "oauth2 token request failed: status 400", err.Error())
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(req)
+	assert.ErrorIs(t, err, transport.ErrOAuth2TokenRequest)
+	assert.Equal(t, "oauth2 token request failed: missing access token", err.Error())
+}
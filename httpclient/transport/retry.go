@@ -0,0 +1,340 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HttpClientMetricsRetriesCount = "http_client_retries_total"
+	HeaderIdempotencyKey          = "Idempotency-Key"
+	RetryReasonError              = "error"
+	RetrySpanEventName            = "http client retry"
+)
+
+// DefaultRetryMethods are the idempotent methods retried by default by the [RetryTransport].
+var DefaultRetryMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodDelete,
+	http.MethodTrace,
+}
+
+// DefaultRetryStatusCodes are the response status codes retried by default by the [RetryTransport].
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryTransport is a wrapper around [http.RoundTripper] with some [RetryTransportConfig] configuration.
+type RetryTransport struct {
+	transport      http.RoundTripper
+	config         *RetryTransportConfig
+	retriesCounter *prometheus.CounterVec
+}
+
+// RetryTransportConfig is the configuration of the [RetryTransport].
+type RetryTransportConfig struct {
+	MaxAttempts       int
+	Methods           []string
+	StatusCodes       []int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Multiplier        float64
+	Jitter            float64
+	RespectRetryAfter bool
+	MaxRetryAfter     time.Duration
+	MaxBodySize       int64
+	Registry          prometheus.Registerer
+	Namespace         string
+	Subsystem         string
+}
+
+// NewRetryTransport returns a [RetryTransport] instance with default [RetryTransportConfig] configuration.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return NewRetryTransportWithConfig(
+		base,
+		&RetryTransportConfig{
+			MaxAttempts:       3,
+			Methods:           DefaultRetryMethods,
+			StatusCodes:       DefaultRetryStatusCodes,
+			InitialBackoff:    100 * time.Millisecond,
+			MaxBackoff:        5 * time.Second,
+			Multiplier:        2,
+			Jitter:            0.2,
+			RespectRetryAfter: true,
+			MaxRetryAfter:     30 * time.Second,
+			MaxBodySize:       1 << 20,
+		},
+	)
+}
+
+// NewRetryTransportWithConfig returns a [RetryTransport] instance for a provided [RetryTransportConfig] configuration.
+// The retries are counted in the http_client_retries_total metric if a Registry is provided.
+func NewRetryTransportWithConfig(base http.RoundTripper, config *RetryTransportConfig) *RetryTransport {
+	if base == nil {
+		base = NewBaseTransport()
+	}
+
+	if config.Multiplier < 1 {
+		config.Multiplier = 1
+	}
+
+	config.Jitter = math.Min(math.Max(config.Jitter, 0), 1)
+
+	retryTransport := &RetryTransport{
+		transport: base,
+		config:    config,
+	}
+
+	if config.Registry != nil {
+		retryTransport.retriesCounter = RegisterCollector(
+			config.Registry,
+			prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: config.Namespace,
+					Subsystem: config.Subsystem,
+					Name:      HttpClientMetricsRetriesCount,
+					Help:      "Number of retried HTTP requests",
+				},
+				[]string{
+					"method",
+					"host",
+					"reason",
+				},
+			),
+		)
+	}
+
+	return retryTransport
+}
+
+// Base returns the wrapped [http.RoundTripper].
+func (t *RetryTransport) Base() http.RoundTripper {
+	return t.transport
+}
+
+// RoundTrip performs a request / response round trip, based on the wrapped [http.RoundTripper].
+//
+// The requests with a retryable method (or with an Idempotency-Key header) are retried on transport errors and on
+// retryable response status codes, up to MaxAttempts attempts, with an exponential backoff with jitter (or the
+// response Retry-After delay). Their body is buffered to be replayed, unless larger than MaxBodySize.
+//
+//nolint:cyclop
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.config.MaxAttempts <= 1 || !t.retryableRequest(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	req, replayable, err := replayableRequest(req, t.config.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	if !replayable {
+		return t.transport.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq, err = rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.transport.RoundTrip(attemptReq)
+
+		reason, retryable := t.retryableResult(ctx, resp, err)
+		if !retryable || attempt >= t.config.MaxAttempts {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if t.config.RespectRetryAfter && resp != nil {
+			if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if t.config.MaxRetryAfter > 0 && retryAfter > t.config.MaxRetryAfter {
+					return resp, err
+				}
+
+				delay = retryAfter
+			}
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		if resp != nil {
+			drainBody(resp.Body)
+		}
+
+		t.observeRetry(ctx, req, attempt, reason, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *RetryTransport) retryableRequest(req *http.Request) bool {
+	return slices.Contains(t.config.Methods, req.Method) || req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+func (t *RetryTransport) retryableResult(ctx context.Context, resp *http.Response, err error) (string, bool) {
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrCircuitBreakerOpen) {
+			return "", false
+		}
+
+		return RetryReasonError, true
+	}
+
+	if slices.Contains(t.config.StatusCodes, resp.StatusCode) {
+		return strconv.Itoa(resp.StatusCode), true
+	}
+
+	return "", false
+}
+
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := float64(t.config.InitialBackoff) * math.Pow(t.config.Multiplier, float64(attempt-1))
+	if t.config.MaxBackoff > 0 {
+		delay = math.Min(delay, float64(t.config.MaxBackoff))
+	}
+
+	if t.config.Jitter > 0 {
+		//nolint:gosec
+		delay = delay * (1 - t.config.Jitter + 2*t.config.Jitter*rand.Float64())
+	}
+
+	return time.Duration(delay)
+}
+
+func (t *RetryTransport) observeRetry(ctx context.Context, req *http.Request, attempt int, reason string, delay time.Duration) {
+	host := requestHost(req)
+
+	if t.retriesCounter != nil {
+		t.retriesCounter.WithLabelValues(req.Method, host, reason).Inc()
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(
+		RetrySpanEventName,
+		trace.WithAttributes(
+			attribute.Int("http.retry.attempt", attempt),
+			attribute.String("http.retry.reason", reason),
+			attribute.String("http.retry.delay", delay.String()),
+			attribute.String("http.retry.host", host),
+		),
+	)
+}
+
+// ParseRetryAfter parses a Retry-After header value, in seconds or as an HTTP date.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// replayableRequest returns a clone of the request whose body can be replayed, buffering it if needed, and false if
+// the body is larger than maxBodySize.
+func replayableRequest(req *http.Request, maxBodySize int64) (*http.Request, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, true, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read request body: %w", err)
+	}
+
+	clone := req.Clone(req.Context())
+
+	if int64(len(buf)) > maxBodySize {
+		clone.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), req.Body), Closer: req.Body}
+
+		return clone, false, nil
+	}
+
+	err = req.Body.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot close request body: %w", err)
+	}
+
+	clone.Body = io.NopCloser(bytes.NewReader(buf))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+
+	return clone, true, nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("cannot replay request body: %w", err)
+		}
+
+		clone.Body = body
+	}
+
+	return clone, nil
+}
+
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+
+	//nolint:errcheck
+	io.Copy(io.Discard, io.LimitReader(body, 4096))
+	//nolint:errcheck
+	body.Close()
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
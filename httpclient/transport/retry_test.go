@@ -0,0 +1,315 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestRetryTransport(registry prometheus.Registerer) *transport.RetryTransport {
+	return transport.NewRetryTransportWithConfig(
+		nil,
+		&transport.RetryTransportConfig{
+			MaxAttempts:       3,
+			Methods:           transport.DefaultRetryMethods,
+			StatusCodes:       transport.DefaultRetryStatusCodes,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        10 * time.Millisecond,
+			Multiplier:        2,
+			Jitter:            0.5,
+			RespectRetryAfter: true,
+			MaxRetryAfter:     2 * time.Second,
+			MaxBodySize:       16,
+			Registry:          registry,
+			Namespace:         "foo",
+			Subsystem:         "bar",
+		},
+	)
+}
+
+func TestRetryTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	trans := transport.NewRetryTransport(nil)
+	assert.IsType(t, &transport.RetryTransport{}, trans)
+	assert.Implements(t, (*http.RoundTripper)(nil), trans)
+	assert.IsType(t, &transport.BaseTransport{}, trans.Base())
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryTransportRoundTripWithBodyReplay(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		bodies = append(bodies, string(body))
+
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	trans := newTestRetryTransport(registry)
+
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "test")
+
+	req := httptest.NewRequest(http.MethodPut, server.URL, io.NopCloser(strings.NewReader("payload"))).WithContext(ctx)
+	req.RequestURI = ""
+
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+
+	err = resp.Body.Close()
+	assert.NoError(t, err)
+
+	span.End()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+
+	// metrics assertions
+	expectedMetric := fmt.Sprintf(
+		`
+			# HELP foo_bar_http_client_retries_total Number of retried HTTP requests
+			# TYPE foo_bar_http_client_retries_total counter
+			foo_bar_http_client_retries_total{host="%s",method="PUT",reason="502"} 2
+		`,
+		server.URL,
+	)
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expectedMetric), "foo_bar_http_client_retries_total")
+	assert.NoError(t, err)
+
+	// span events assertions
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Len(t, spans[0].Events, 2)
+
+	for i, event := range spans[0].Events {
+		assert.Equal(t, transport.RetrySpanEventName, event.Name)
+		assert.Contains(t, event.Attributes, sdkAttribute("http.retry.attempt", i+1))
+		assert.Contains(t, event.Attributes, sdkAttribute("http.retry.reason", "502"))
+	}
+
+	// metrics registration is shared between transports
+	assert.NotPanics(t, func() {
+		newTestRetryTransport(registry)
+	})
+}
+
+func TestRetryTransportRoundTripWithPolicies(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Retry-After sent on the first attempt only
+		if attempt := calls.Add(1); attempt == 1 && r.URL.Query().Get("retry-after") != "" {
+			w.Header().Set("Retry-After", r.URL.Query().Get("retry-after"))
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	trans := newTestRetryTransport(nil)
+
+	roundTrip := func(req *http.Request) int32 {
+		calls.Store(0)
+
+		req.RequestURI = ""
+
+		resp, err := trans.RoundTrip(req)
+		assert.NoError(t, err)
+
+		err = resp.Body.Close()
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		return calls.Load()
+	}
+
+	// retryable method, until max attempts
+	assert.Equal(t, int32(3), roundTrip(httptest.NewRequest(http.MethodGet, server.URL, nil)))
+
+	// not retryable method
+	assert.Equal(t, int32(1), roundTrip(httptest.NewRequest(http.MethodPost, server.URL, nil)))
+
+	// not retryable method with idempotency key
+	req := httptest.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString("body"))
+	req.Header.Set(transport.HeaderIdempotencyKey, "key")
+	assert.Equal(t, int32(3), roundTrip(req))
+
+	// body larger than max body size
+	req = httptest.NewRequest(http.MethodPut, server.URL, io.NopCloser(strings.NewReader(strings.Repeat("a", 32))))
+	assert.Equal(t, int32(1), roundTrip(req))
+
+	// retry after within max retry after
+	start := time.Now()
+	assert.Equal(t, int32(3), roundTrip(httptest.NewRequest(http.MethodGet, server.URL+"?retry-after=1", nil)))
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	// retry after exceeding max retry after
+	assert.Equal(t, int32(1), roundTrip(httptest.NewRequest(http.MethodGet, server.URL+"?retry-after=10", nil)))
+
+	// retry after exceeding context deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, int32(1), roundTrip(httptest.NewRequest(http.MethodGet, server.URL+"?retry-after=1", nil).WithContext(ctx)))
+}
+
+func TestRetryTransportRoundTripWithErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+
+		return nil, errors.New("connection refused")
+	})
+
+	trans := transport.NewRetryTransportWithConfig(
+		base,
+		&transport.RetryTransportConfig{
+			MaxAttempts:    2,
+			Methods:        transport.DefaultRetryMethods,
+			InitialBackoff: time.Millisecond,
+		},
+	)
+
+	// transport errors are retried
+	//nolint:bodyclose
+	_, err := trans.RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+	assert.Error(t, err)
+	assert.Equal(t, "connection refused", err.Error())
+	assert.Equal(t, int32(2), calls.Load())
+
+	// circuit breaker errors are not retried
+	calls.Store(0)
+
+	trans = transport.NewRetryTransportWithConfig(
+		roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls.Add(1)
+
+			return nil, transport.ErrCircuitBreakerOpen
+		}),
+		&transport.RetryTransportConfig{
+			MaxAttempts: 2,
+			Methods:     transport.DefaultRetryMethods,
+		},
+	)
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+	assert.ErrorIs(t, err, transport.ErrCircuitBreakerOpen)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// cancelled context during backoff
+	ctx, cancel := context.WithCancel(context.Background())
+
+	trans = transport.NewRetryTransportWithConfig(
+		roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			cancel()
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		}),
+		&transport.RetryTransportConfig{
+			MaxAttempts:    2,
+			Methods:        transport.DefaultRetryMethods,
+			StatusCodes:    transport.DefaultRetryStatusCodes,
+			InitialBackoff: time.Second,
+		},
+	)
+
+	//nolint:bodyclose
+	_, err = trans.RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com", nil).WithContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func sdkAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case int:
+		return attribute.Int(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	delay, ok := transport.ParseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	delay, ok = transport.ParseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, delay, float64(2*time.Second))
+
+	delay, ok = transport.ParseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	for _, value := range []string{"", "-1", "invalid"} {
+		_, ok = transport.ParseRetryAfter(value)
+		assert.False(t, ok)
+	}
+}
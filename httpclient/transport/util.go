@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterCollector registers a collector, or returns the already registered one, to allow several transports or
+// middlewares instances (for example per client or per server) to share their metrics.
+func RegisterCollector[C prometheus.Collector](registry prometheus.Registerer, collector C) C {
+	err := registry.Register(collector)
+	if err != nil {
+		var alreadyRegisteredError prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegisteredError) {
+			if existing, ok := alreadyRegisteredError.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+
+		panic(err)
+	}
+
+	return collector
+}
+
+// requestHost returns the request host, prefixed by its scheme if any.
+func requestHost(req *http.Request) string {
+	if req.URL.Scheme != "" {
+		return fmt.Sprintf("%s://%s", req.URL.Scheme, req.URL.Host)
+	}
+
+	return req.URL.Host
+}
+
+// requestHostHeader returns the request host, as sent in the Host header.
+func requestHostHeader(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+
+	return req.URL.Host
+}
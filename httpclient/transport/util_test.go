@@ -0,0 +1,33 @@
+package transport_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai/httpclient/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterCollector(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	newCounter := func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "Test"}, []string{"label"})
+	}
+
+	first := transport.RegisterCollector(registry, newCounter())
+	second := transport.RegisterCollector(registry, newCounter())
+
+	// the already registered collector is shared
+	assert.Same(t, first, second)
+
+	// other registration errors are not recovered
+	assert.Panics(t, func() {
+		transport.RegisterCollector(
+			registry,
+			prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Other"}),
+		)
+	})
+}
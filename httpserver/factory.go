@@ -1,7 +1,12 @@
 package httpserver
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/bytes"
 )
 
 // HttpServerFactory is the interface for [echo.Echo] factories.
@@ -31,6 +36,13 @@ func NewDefaultHttpServerFactory() HttpServerFactory {
 //		httpserver.WithBinder(&echo.DefaultBinder{}),                 // echo default binder
 //		httpserver.WithJsonSerializer(&echo.DefaultJSONSerializer{}), // echo default json serializer
 //		httpserver.WithHttpErrorHandler(nil),                         // echo default error handler
+//		httpserver.WithReadTimeout(0),                                // no read timeout by default
+//		httpserver.WithReadHeaderTimeout(10 * time.Second),           // 10 seconds read header timeout by default
+//		httpserver.WithWriteTimeout(0),                               // no write timeout by default
+//		httpserver.WithIdleTimeout(0),                                // no idle timeout by default
+//		httpserver.WithMaxHeaderBytes(0),                             // net/http default max header bytes
+//		httpserver.WithBodyLimit(""),                                 // no body limit by default
+//		httpserver.WithH2C(false),                                    // h2c disabled by default
 //	)
 func (f *DefaultHttpServerFactory) Create(options ...HttpServerOption) (*echo.Echo, error) {
 	appliedOpts := DefaultHttpServerOptions()
@@ -55,5 +67,29 @@ func (f *DefaultHttpServerFactory) Create(options ...HttpServerOption) (*echo.Ec
 		httpServer.Renderer = appliedOpts.Renderer
 	}
 
+	for _, server := range []*http.Server{httpServer.Server, httpServer.TLSServer} {
+		server.ReadTimeout = appliedOpts.ReadTimeout
+		server.ReadHeaderTimeout = appliedOpts.ReadHeaderTimeout
+		server.WriteTimeout = appliedOpts.WriteTimeout
+		server.IdleTimeout = appliedOpts.IdleTimeout
+		server.MaxHeaderBytes = appliedOpts.MaxHeaderBytes
+	}
+
+	if appliedOpts.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+
+		httpServer.Server.Protocols = protocols
+	}
+
+	if appliedOpts.BodyLimit != "" {
+		if _, err := bytes.Parse(appliedOpts.BodyLimit); err != nil {
+			return nil, fmt.Errorf("invalid body limit %s: %w", appliedOpts.BodyLimit, err)
+		}
+
+		httpServer.Pre(middleware.BodyLimit(appliedOpts.BodyLimit))
+	}
+
 	return httpServer, nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/ankorstore/yokai/httpserver/middleware"
@@ -71,6 +72,74 @@ func TestCreate(t *testing.T) {
 	assert.NotNil(t, httpServer.Renderer)
 }
 
+func TestCreateWithServerLimits(t *testing.T) {
+	t.Parallel()
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(
+		httpserver.WithReadTimeout(1*time.Second),
+		httpserver.WithReadHeaderTimeout(2*time.Second),
+		httpserver.WithWriteTimeout(3*time.Second),
+		httpserver.WithIdleTimeout(4*time.Second),
+		httpserver.WithMaxHeaderBytes(1024),
+		httpserver.WithBodyLimit("10B"),
+	)
+	assert.NoError(t, err)
+
+	for _, server := range []*http.Server{httpServer.Server, httpServer.TLSServer} {
+		assert.Equal(t, 1*time.Second, server.ReadTimeout)
+		assert.Equal(t, 2*time.Second, server.ReadHeaderTimeout)
+		assert.Equal(t, 3*time.Second, server.WriteTimeout)
+		assert.Equal(t, 4*time.Second, server.IdleTimeout)
+		assert.Equal(t, 1024, server.MaxHeaderBytes)
+	}
+
+	httpServer.POST("/test", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("small"))
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("this body is too large"))
+	rec = httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestCreateWithDefaultServerLimits(t *testing.T) {
+	t.Parallel()
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create()
+	assert.NoError(t, err)
+
+	for _, server := range []*http.Server{httpServer.Server, httpServer.TLSServer} {
+		assert.Equal(t, httpserver.DefaultReadHeaderTimeout, server.ReadHeaderTimeout)
+	}
+
+	assert.Nil(t, httpServer.Server.Protocols)
+}
+
+func TestCreateWithH2C(t *testing.T) {
+	t.Parallel()
+
+	httpServer, err := httpserver.NewDefaultHttpServerFactory().Create(httpserver.WithH2C(true))
+	assert.NoError(t, err)
+
+	assert.True(t, httpServer.Server.Protocols.HTTP1())
+	assert.True(t, httpServer.Server.Protocols.UnencryptedHTTP2())
+	assert.Nil(t, httpServer.TLSServer.Protocols)
+}
+
+func TestCreateWithInvalidBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	_, err := httpserver.NewDefaultHttpServerFactory().Create(httpserver.WithBodyLimit("invalid"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid body limit invalid")
+}
+
 func TestCreateWithRequestLoggerAndTracerAndErrorHandlerOn2xx(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,339 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	HeaderIdempotencyKey             = "Idempotency-Key"
+	HeaderIdempotentReplayed         = "Idempotent-Replayed"
+	IdempotencyKeyMaxLength          = 255
+	DefaultIdempotencyTtl            = 24 * time.Hour
+	DefaultIdempotencyReservationTtl = time.Minute
+	DefaultIdempotencyLockTimeout    = 10 * time.Second
+	DefaultIdempotencyPollInterval   = 50 * time.Millisecond
+)
+
+var idempotencyExcludedHeaders = []string{
+	echo.HeaderContentEncoding,
+	echo.HeaderContentLength,
+	echo.HeaderVary,
+}
+
+// IdempotencyRecord is the record of an idempotent request, and of its response once completed.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyKeyScope returns the scope of the idempotency keys of a request, like the authenticated principal or the
+// client: a stored response is only replayed to the requests of the same scope.
+type IdempotencyKeyScope func(c echo.Context) string
+
+// IdempotencyKeyScopeByIp returns a [IdempotencyKeyScope] using the request client IP.
+func IdempotencyKeyScopeByIp() IdempotencyKeyScope {
+	return func(c echo.Context) string {
+		return c.RealIP()
+	}
+}
+
+// IdempotencyKeyScopeByHeader returns a [IdempotencyKeyScope] using a request header value (like an API key).
+func IdempotencyKeyScopeByHeader(name string) IdempotencyKeyScope {
+	return func(c echo.Context) string {
+		return c.Request().Header.Get(name)
+	}
+}
+
+// IdempotencyStore is the interface for the [IdempotencyMiddleware] records storage.
+type IdempotencyStore interface {
+	// Reserve atomically reserves a key for a request fingerprint, until the ttl expires.
+	// If the key is already reserved or completed, it returns the existing record and false.
+	Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved key, until the ttl expires.
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release removes the reservation of a key.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddlewareConfig is the configuration for the [IdempotencyMiddleware].
+type IdempotencyMiddlewareConfig struct {
+	Skipper        middleware.Skipper
+	Store          IdempotencyStore
+	Methods        []string
+	KeyHeader      string
+	KeyScope       IdempotencyKeyScope
+	Required       bool
+	Ttl            time.Duration
+	ReservationTtl time.Duration
+	LockTimeout    time.Duration
+	PollInterval   time.Duration
+}
+
+// DefaultIdempotencyMiddlewareConfig is the default configuration for the [IdempotencyMiddleware].
+var DefaultIdempotencyMiddlewareConfig = IdempotencyMiddlewareConfig{
+	Skipper:        middleware.DefaultSkipper,
+	Methods:        []string{http.MethodPost, http.MethodPatch},
+	KeyHeader:      HeaderIdempotencyKey,
+	KeyScope:       IdempotencyKeyScopeByIp(),
+	Required:       false,
+	Ttl:            DefaultIdempotencyTtl,
+	ReservationTtl: DefaultIdempotencyReservationTtl,
+	LockTimeout:    DefaultIdempotencyLockTimeout,
+	PollInterval:   DefaultIdempotencyPollInterval,
+}
+
+// IdempotencyMiddleware returns a [IdempotencyMiddleware] with the [DefaultIdempotencyMiddlewareConfig] and a [MemoryIdempotencyStore].
+func IdempotencyMiddleware() echo.MiddlewareFunc {
+	return IdempotencyMiddlewareWithConfig(DefaultIdempotencyMiddlewareConfig)
+}
+
+// IdempotencyMiddlewareWithConfig returns a [IdempotencyMiddleware] for a provided [IdempotencyMiddlewareConfig].
+//
+// For requests providing an idempotency key, the response is stored for the configured ttl, and replayed on retries
+// of the same key scope (falling back to the client IP if the scope is empty).
+// Reusing a key with a different payload gets a 422 response, and concurrent duplicates wait for the first request
+// completion (up to the lock timeout, then get a 409 response).
+// The reservation ttl bounds the processing duration, after which a duplicate can be processed again (for example
+// if the instance processing the first request crashed).
+// Failed requests (handler error or 5xx response) are not stored, to allow retries.
+func IdempotencyMiddlewareWithConfig(config IdempotencyMiddlewareConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultIdempotencyMiddlewareConfig.Skipper
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryIdempotencyStore()
+	}
+
+	if len(config.Methods) == 0 {
+		config.Methods = DefaultIdempotencyMiddlewareConfig.Methods
+	}
+
+	if config.KeyHeader == "" {
+		config.KeyHeader = DefaultIdempotencyMiddlewareConfig.KeyHeader
+	}
+
+	if config.KeyScope == nil {
+		config.KeyScope = DefaultIdempotencyMiddlewareConfig.KeyScope
+	}
+
+	if config.Ttl <= 0 {
+		config.Ttl = DefaultIdempotencyMiddlewareConfig.Ttl
+	}
+
+	if config.ReservationTtl <= 0 {
+		config.ReservationTtl = DefaultIdempotencyMiddlewareConfig.ReservationTtl
+	}
+
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultIdempotencyMiddlewareConfig.LockTimeout
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultIdempotencyMiddlewareConfig.PollInterval
+	}
+
+	methods := make([]string, len(config.Methods))
+	for i, method := range config.Methods {
+		methods[i] = strings.ToUpper(method)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if config.Skipper(c) || !slices.Contains(methods, req.Method) {
+				return next(c)
+			}
+
+			key := req.Header.Get(config.KeyHeader)
+			if key == "" {
+				if config.Required {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing %s header", config.KeyHeader))
+				}
+
+				return next(c)
+			}
+
+			if len(key) > IdempotencyKeyMaxLength {
+				return echo.NewHTTPError(
+					http.StatusBadRequest,
+					fmt.Sprintf("%s header cannot exceed %d characters", config.KeyHeader, IdempotencyKeyMaxLength),
+				)
+			}
+
+			ctx := req.Context()
+			logger := log.CtxLogger(ctx)
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return fmt.Errorf("cannot read request body: %w", err)
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := config.KeyScope(c)
+			if scope == "" {
+				scope = c.RealIP()
+			}
+
+			storeKey := IdempotencyScopedKey(scope, key)
+			fingerprint := IdempotencyFingerprint(req.Method, req.URL.RequestURI(), body)
+			deadline := time.Now().Add(config.LockTimeout)
+
+			for {
+				record, reserved, err := config.Store.Reserve(ctx, storeKey, fingerprint, config.ReservationTtl)
+				if err != nil {
+					logger.Error().Err(err).Str("idempotencyKey", key).Msg("idempotency store failure")
+
+					return fmt.Errorf("cannot reserve idempotency key: %w", err)
+				}
+
+				if reserved {
+					break
+				}
+
+				if record.Fingerprint != fingerprint {
+					logger.Warn().Str("idempotencyKey", key).Msg("idempotency key reused with a different payload")
+
+					return echo.NewHTTPError(
+						http.StatusUnprocessableEntity,
+						fmt.Sprintf("%s already used with a different payload", config.KeyHeader),
+					)
+				}
+
+				if record.Completed {
+					logger.Debug().Str("idempotencyKey", key).Msg("idempotent request replay")
+
+					return replayIdempotencyRecord(c, record)
+				}
+
+				if time.Now().After(deadline) {
+					return echo.NewHTTPError(
+						http.StatusConflict,
+						fmt.Sprintf("a request with the same %s is being processed", config.KeyHeader),
+					)
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(config.PollInterval):
+				}
+			}
+
+			resp := c.Response()
+
+			recorder := &idempotencyResponseRecorder{ResponseWriter: resp.Writer}
+			resp.Writer = recorder
+
+			err = next(c)
+
+			storeCtx := context.WithoutCancel(ctx)
+
+			if err != nil || resp.Status >= http.StatusInternalServerError {
+				if releaseErr := config.Store.Release(storeCtx, storeKey); releaseErr != nil {
+					logger.Error().Err(releaseErr).Str("idempotencyKey", key).Msg("idempotency store failure")
+				}
+
+				return err
+			}
+
+			// the representation headers set by the outer middlewares (like the compression one) do not match the
+			// recorded body, they are set again on replay
+			header := resp.Header().Clone()
+			for _, name := range idempotencyExcludedHeaders {
+				header.Del(name)
+			}
+
+			completeErr := config.Store.Complete(
+				storeCtx,
+				storeKey,
+				&IdempotencyRecord{
+					Fingerprint: fingerprint,
+					Completed:   true,
+					Status:      resp.Status,
+					Header:      header,
+					Body:        recorder.body.Bytes(),
+				},
+				config.Ttl,
+			)
+			if completeErr != nil {
+				logger.Error().Err(completeErr).Str("idempotencyKey", key).Msg("idempotency store failure")
+			}
+
+			return nil
+		}
+	}
+}
+
+// IdempotencyFingerprint returns the fingerprint of a request, from its method, uri and body.
+func IdempotencyFingerprint(method string, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(uri))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IdempotencyScopedKey returns the store key of an idempotency key, within its scope.
+func IdempotencyScopedKey(scope string, key string) string {
+	hash := sha256.New()
+	hash.Write([]byte(scope))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayIdempotencyRecord(c echo.Context, record *IdempotencyRecord) error {
+	header := c.Response().Header()
+
+	for name, values := range record.Header {
+		if header.Get(name) == "" {
+			header[name] = values
+		}
+	}
+
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	c.Response().WriteHeader(record.Status)
+
+	_, err := c.Response().Write(record.Body)
+
+	return err
+}
+
+type idempotencyResponseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyResponseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *idempotencyResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	IdempotencySqlDialectSqlite   = "sqlite"
+	IdempotencySqlDialectMysql    = "mysql"
+	IdempotencySqlDialectPostgres = "postgres"
+	IdempotencySqlTable           = "idempotency_keys"
+)
+
+// IdempotencyMigrations contains the [Goose] migrations creating the [SqlIdempotencyStore] table, in a directory per
+// dialect (sqlite, mysql and postgres).
+//
+// [Goose]: https://github.com/pressly/goose
+//
+//go:embed migrations
+var IdempotencyMigrations embed.FS
+
+// MemoryIdempotencyStore is an in memory [IdempotencyStore] implementation, for single instance deployments.
+type MemoryIdempotencyStore struct {
+	mutex   sync.Mutex
+	records map[string]*memoryIdempotencyRecord
+}
+
+type memoryIdempotencyRecord struct {
+	record    *IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore returns a new [MemoryIdempotencyStore] instance.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]*memoryIdempotencyRecord),
+	}
+}
+
+// Reserve atomically reserves a key for a request fingerprint, until the ttl expires.
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key string, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		return cloneIdempotencyRecord(existing.record), false, nil
+	}
+
+	s.records[key] = &memoryIdempotencyRecord{
+		record:    &IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+
+	s.purge(now)
+
+	return nil, true, nil
+}
+
+// Complete stores the response of a reserved key, until the ttl expires.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[key] = &memoryIdempotencyRecord{
+		record:    cloneIdempotencyRecord(record),
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	return nil
+}
+
+// Release removes the reservation of a key.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) purge(now time.Time) {
+	for key, record := range s.records {
+		if !now.Before(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// SqlIdempotencyStore is a SQL table [IdempotencyStore] implementation, for multiple instances deployments.
+// The table can be created with the provided [IdempotencyMigrations].
+type SqlIdempotencyStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// NewSqlIdempotencyStore returns a new [SqlIdempotencyStore] instance, for a provided dialect (sqlite, mysql or postgres).
+func NewSqlIdempotencyStore(db *sql.DB, dialect string) *SqlIdempotencyStore {
+	return &SqlIdempotencyStore{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// Reserve atomically reserves a key for a request fingerprint, until the ttl expires.
+func (s *SqlIdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	now := time.Now()
+
+	_, err := s.db.ExecContext(
+		ctx,
+		s.query("DELETE FROM %s WHERE idempotency_key = ? AND expires_at <= ?"),
+		key,
+		now.UnixNano(),
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot delete expired idempotency key: %w", err)
+	}
+
+	_, insertErr := s.db.ExecContext(
+		ctx,
+		s.query("INSERT INTO %s (idempotency_key, fingerprint, completed, status, headers, body, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		key,
+		fingerprint,
+		false,
+		0,
+		"",
+		nil,
+		now.Add(ttl).UnixNano(),
+	)
+	if insertErr == nil {
+		return nil, true, nil
+	}
+
+	// the insertion failure is a key conflict if the key exists
+	record, err := s.find(ctx, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, fmt.Errorf("cannot insert idempotency key: %w", insertErr)
+		}
+
+		return nil, false, err
+	}
+
+	return record, false, nil
+}
+
+// Complete stores the response of a reserved key, until the ttl expires.
+func (s *SqlIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	headers, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("cannot encode idempotency response headers: %w", err)
+	}
+
+	_, err = s.db.ExecContext(
+		ctx,
+		s.query("UPDATE %s SET fingerprint = ?, completed = ?, status = ?, headers = ?, body = ?, expires_at = ? WHERE idempotency_key = ?"),
+		record.Fingerprint,
+		record.Completed,
+		record.Status,
+		string(headers),
+		record.Body,
+		time.Now().Add(ttl).UnixNano(),
+		key,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes the reservation of a key.
+func (s *SqlIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.query("DELETE FROM %s WHERE idempotency_key = ?"), key)
+	if err != nil {
+		return fmt.Errorf("cannot release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SqlIdempotencyStore) find(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	var headers string
+
+	err := s.db.QueryRowContext(
+		ctx,
+		s.query("SELECT fingerprint, completed, status, headers, body FROM %s WHERE idempotency_key = ?"),
+		key,
+	).Scan(&record.Fingerprint, &record.Completed, &record.Status, &headers, &record.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if headers != "" {
+		err = json.Unmarshal([]byte(headers), &record.Header)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode idempotency response headers: %w", err)
+		}
+	}
+
+	return &record, nil
+}
+
+func (s *SqlIdempotencyStore) query(query string) string {
+	query = fmt.Sprintf(query, IdempotencySqlTable)
+
+	if s.dialect != IdempotencySqlDialectPostgres {
+		return query
+	}
+
+	var buf []byte
+	position := 0
+
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			position++
+			buf = fmt.Appendf(buf, "$%d", position)
+
+			continue
+		}
+
+		buf = append(buf, query[i])
+	}
+
+	return string(buf)
+}
+
+func cloneIdempotencyRecord(record *IdempotencyRecord) *IdempotencyRecord {
+	var header http.Header
+	if record.Header != nil {
+		header = record.Header.Clone()
+	}
+
+	return &IdempotencyRecord{
+		Fingerprint: record.Fingerprint,
+		Completed:   record.Completed,
+		Status:      record.Status,
+		Header:      header,
+		Body:        slices.Clone(record.Body),
+	}
+}
@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSqliteIdempotencyStore(t *testing.T) *middleware.SqlIdempotencyStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	migration, err := middleware.IdempotencyMigrations.ReadFile(
+		"migrations/sqlite/20241019000000_create_idempotency_keys_table.sql",
+	)
+	assert.NoError(t, err)
+
+	up, _, _ := strings.Cut(strings.TrimPrefix(string(migration), "-- +goose Up"), "-- +goose Down")
+
+	_, err = db.Exec(up)
+	assert.NoError(t, err)
+
+	return middleware.NewSqlIdempotencyStore(db, middleware.IdempotencySqlDialectSqlite)
+}
+
+func testIdempotencyStore(t *testing.T, store middleware.IdempotencyStore) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	// reservation
+	record, reserved, err := store.Reserve(ctx, "key", "fingerprint", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, record)
+
+	record, reserved, err = store.Reserve(ctx, "key", "other", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "fingerprint", record.Fingerprint)
+	assert.False(t, record.Completed)
+
+	// completion
+	err = store.Complete(
+		ctx,
+		"key",
+		&middleware.IdempotencyRecord{
+			Fingerprint: "fingerprint",
+			Completed:   true,
+			Status:      http.StatusCreated,
+			Header:      http.Header{"X-Foo": []string{"bar"}},
+			Body:        []byte("body"),
+		},
+		time.Minute,
+	)
+	assert.NoError(t, err)
+
+	record, reserved, err = store.Reserve(ctx, "key", "fingerprint", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.True(t, record.Completed)
+	assert.Equal(t, http.StatusCreated, record.Status)
+	assert.Equal(t, "bar", record.Header.Get("X-Foo"))
+	assert.Equal(t, []byte("body"), record.Body)
+
+	// release
+	err = store.Release(ctx, "key")
+	assert.NoError(t, err)
+
+	_, reserved, err = store.Reserve(ctx, "key", "fingerprint", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	// expiration
+	_, reserved, err = store.Reserve(ctx, "expiring", "fingerprint", 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, reserved, err = store.Reserve(ctx, "expiring", "other", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	t.Parallel()
+
+	testIdempotencyStore(t, middleware.NewMemoryIdempotencyStore())
+}
+
+func TestSqlIdempotencyStore(t *testing.T) {
+	t.Parallel()
+
+	testIdempotencyStore(t, newSqliteIdempotencyStore(t))
+}
+
+func TestIdempotencyMiddlewareWithSqlStore(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			Store: newSqliteIdempotencyStore(t),
+		},
+		&calls,
+		0,
+	)
+
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created 100", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("x-payment-call"))
+	assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key", "200")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyMigrations(t *testing.T) {
+	t.Parallel()
+
+	for _, dialect := range []string{
+		middleware.IdempotencySqlDialectSqlite,
+		middleware.IdempotencySqlDialectMysql,
+		middleware.IdempotencySqlDialectPostgres,
+	} {
+		entries, err := middleware.IdempotencyMigrations.ReadDir("migrations/" + dialect)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	}
+}
@@ -0,0 +1,337 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai/httpserver/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotencyTestServer(config middleware.IdempotencyMiddlewareConfig, calls *atomic.Int32, delay time.Duration) *echo.Echo {
+	httpServer := echo.New()
+	httpServer.Use(middleware.IdempotencyMiddlewareWithConfig(config))
+
+	httpServer.POST("/payments", func(c echo.Context) error {
+		count := calls.Add(1)
+
+		time.Sleep(delay)
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+
+		if string(body) == "fail" {
+			return errors.New("payment failure")
+		}
+
+		c.Response().Header().Set("x-payment-call", string(rune('0'+count)))
+
+		return c.String(http.StatusCreated, "created "+string(body))
+	})
+
+	httpServer.GET("/payments", func(c echo.Context) error {
+		calls.Add(1)
+
+		return c.String(http.StatusOK, "list")
+	})
+
+	return httpServer
+}
+
+func sendIdempotentRequest(httpServer *echo.Echo, method string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/payments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotencyMiddlewareReplay(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(middleware.IdempotencyMiddlewareConfig{}, &calls, 0)
+
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "key-1", "100")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created 100", rec.Body.String())
+	assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	// retry is replayed
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key-1", "100")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created 100", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("x-payment-call"))
+	assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// key reuse with a different payload is rejected
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key-1", "200")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Idempotency-Key already used with a different payload")
+	assert.Equal(t, int32(1), calls.Load())
+
+	// other key is processed
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key-2", "200")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created 200", rec.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+
+	// requests without key or with not handled method are not idempotent
+	sendIdempotentRequest(httpServer, http.MethodPost, "", "300")
+	sendIdempotentRequest(httpServer, http.MethodPost, "", "300")
+	sendIdempotentRequest(httpServer, http.MethodGet, "key-1", "")
+	sendIdempotentRequest(httpServer, http.MethodGet, "key-1", "")
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithFailure(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(middleware.IdempotencyMiddlewareConfig{}, &calls, 0)
+
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "key", "fail")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// failures are not stored, to allow retries
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key", "fail")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithRequiredKey(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			Required: true,
+		},
+		&calls,
+		0,
+	)
+
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "", "100")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing Idempotency-Key header")
+
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, strings.Repeat("k", 256), "100")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Idempotency-Key header cannot exceed 255 characters")
+
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithConcurrentDuplicates(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			PollInterval: 10 * time.Millisecond,
+		},
+		&calls,
+		100*time.Millisecond,
+	)
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 5)
+
+	for i := range recs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			recs[i] = sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	for _, rec := range recs {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "created 100", rec.Body.String())
+	}
+}
+
+func TestIdempotencyMiddlewareWithLockTimeout(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			LockTimeout:  50 * time.Millisecond,
+			PollInterval: 10 * time.Millisecond,
+		},
+		&calls,
+		200*time.Millisecond,
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "a request with the same Idempotency-Key is being processed")
+
+	<-done
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithSkipper(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			Skipper: func(echo.Context) bool {
+				return true
+			},
+		},
+		&calls,
+		0,
+	)
+
+	sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	rec := sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+
+	assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithKeyScope(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := newIdempotencyTestServer(
+		middleware.IdempotencyMiddlewareConfig{
+			KeyScope: middleware.IdempotencyKeyScopeByHeader("x-client"),
+		},
+		&calls,
+		0,
+	)
+
+	send := func(client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader("100"))
+		req.Header.Set(middleware.HeaderIdempotencyKey, "key")
+		req.Header.Set("x-client", client)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := send("client-a")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// same key from another client is not replayed
+	rec = send("client-b")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// same key from the same client is replayed
+	rec = send("client-a")
+	assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// empty scope falls back to the client IP
+	sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	rec = sendIdempotentRequest(httpServer, http.MethodPost, "key", "100")
+	assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestIdempotencyMiddlewareWithRepresentationHeaders(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	httpServer := echo.New()
+
+	// simulates an outer compression middleware, encoding the response after the idempotency one
+	httpServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAcceptEncoding) == "gzip" {
+				c.Response().Header().Set(echo.HeaderContentEncoding, "gzip")
+				c.Response().Header().Set(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			}
+
+			return next(c)
+		}
+	})
+	httpServer.Use(middleware.IdempotencyMiddleware())
+
+	httpServer.POST("/payments", func(c echo.Context) error {
+		calls.Add(1)
+		c.Response().Header().Set(echo.HeaderContentLength, "7")
+
+		return c.String(http.StatusCreated, "created")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+	req.Header.Set(middleware.HeaderIdempotencyKey, "key")
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	httpServer.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/payments", nil)
+	req.Header.Set(middleware.HeaderIdempotencyKey, "key")
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, "true", rec.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, "created", rec.Body.String())
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentLength))
+	assert.Empty(t, rec.Header().Get(echo.HeaderVary))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := middleware.IdempotencyFingerprint(http.MethodPost, "/payments", []byte("100"))
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, middleware.IdempotencyFingerprint(http.MethodPost, "/payments", []byte("100")))
+	assert.NotEqual(t, fingerprint, middleware.IdempotencyFingerprint(http.MethodPost, "/payments", []byte("200")))
+	assert.NotEqual(t, fingerprint, middleware.IdempotencyFingerprint(http.MethodPatch, "/payments", []byte("100")))
+	assert.NotEqual(t, fingerprint, middleware.IdempotencyFingerprint(http.MethodPost, "/refunds", []byte("100")))
+}
+
+func TestIdempotencyScopedKey(t *testing.T) {
+	t.Parallel()
+
+	key := middleware.IdempotencyScopedKey("client-a", "key")
+
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, middleware.IdempotencyScopedKey("client-a", "key"))
+	assert.NotEqual(t, key, middleware.IdempotencyScopedKey("client-b", "key"))
+	assert.NotEqual(t, middleware.IdempotencyScopedKey("a:b", "c"), middleware.IdempotencyScopedKey("a", "b:c"))
+}
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    idempotency_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    fingerprint     VARCHAR(64)  NOT NULL,
+    completed       BOOLEAN      NOT NULL DEFAULT FALSE,
+    status          INTEGER      NOT NULL DEFAULT 0,
+    headers         TEXT         NOT NULL DEFAULT '',
+    body            BLOB,
+    expires_at      BIGINT       NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -12,9 +12,15 @@ import (
 )
 
 const (
-	HttpServerMetricsRequestsCount    = "http_server_requests_total"
-	HttpServerMetricsRequestsDuration = "http_server_requests_duration_seconds"
-	HttpServerMetricsNotFoundPath     = "/not-found"
+	HttpServerMetricsRequestsCount            = "http_server_requests_total"
+	HttpServerMetricsRequestsDuration         = "http_server_requests_duration_seconds"
+	HttpServerMetricsCompressionRatio         = "http_server_response_compression_ratio"
+	HttpServerMetricsConditionalRequestsCount = "http_server_conditional_requests_total"
+	HttpServerMetricsNotFoundPath             = "/not-found"
+	ConditionalRequestResultHit               = "hit"
+	ConditionalRequestResultMiss              = "miss"
+	ctxResponseCompressionKey                 = "request-metrics-response-compression"
+	ctxConditionalRequestKey                  = "request-metrics-conditional-request"
 )
 
 // RequestMetricsMiddlewareConfig is the configuration for the [RequestMetricsMiddleware].
@@ -26,6 +32,8 @@ type RequestMetricsMiddlewareConfig struct {
 	Subsystem               string
 	NormalizeRequestPath    bool
 	NormalizeResponseStatus bool
+	CollectCompressionRatio bool
+	CollectConditional      bool
 }
 
 // DefaultRequestMetricsMiddlewareConfig is the default configuration for the [RequestMetricsMiddleware].
@@ -37,6 +45,8 @@ var DefaultRequestMetricsMiddlewareConfig = RequestMetricsMiddlewareConfig{
 	Buckets:                 prometheus.DefBuckets,
 	NormalizeRequestPath:    true,
 	NormalizeResponseStatus: true,
+	CollectCompressionRatio: false,
+	CollectConditional:      false,
 }
 
 // RequestMetricsMiddleware returns a [RequestMetricsMiddleware] with the [DefaultRequestMetricsMiddlewareConfig].
@@ -45,6 +55,12 @@ func RequestMetricsMiddleware() echo.MiddlewareFunc {
 }
 
 // RequestMetricsMiddlewareWithConfig returns a [RequestMetricsMiddleware] for a provided [RequestMetricsMiddlewareConfig].
+//
+// If CollectCompressionRatio is enabled, the responses compressions reported with [ObserveResponseCompression] are
+// observed by encoding, method and path. If CollectConditional is enabled, the conditional requests results reported
+// with [ObserveConditionalRequest] are counted by result (hit with a 304 response, or miss), method and path.
+//
+//nolint:cyclop,funlen
 func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) echo.MiddlewareFunc {
 	if config.Skipper == nil {
 		config.Skipper = DefaultRequestMetricsMiddlewareConfig.Skipper
@@ -96,6 +112,45 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 
 	config.Registry.MustRegister(httpRequestsCounter, httpRequestsDuration)
 
+	var httpResponsesCompressionRatio *prometheus.HistogramVec
+	if config.CollectCompressionRatio {
+		httpResponsesCompressionRatio = prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: config.Namespace,
+				Subsystem: config.Subsystem,
+				Name:      HttpServerMetricsCompressionRatio,
+				Help:      "Ratio between the compressed and the original HTTP responses sizes",
+				Buckets:   []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1},
+			},
+			[]string{
+				"encoding",
+				"method",
+				"path",
+			},
+		)
+
+		config.Registry.MustRegister(httpResponsesCompressionRatio)
+	}
+
+	var httpConditionalRequestsCounter *prometheus.CounterVec
+	if config.CollectConditional {
+		httpConditionalRequestsCounter = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: config.Namespace,
+				Subsystem: config.Subsystem,
+				Name:      HttpServerMetricsConditionalRequestsCount,
+				Help:      "Number of HTTP conditional requests, by result (hit with 304 response, or miss)",
+			},
+			[]string{
+				"result",
+				"method",
+				"path",
+			},
+		)
+
+		config.Registry.MustRegister(httpConditionalRequestsCounter)
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// skipper
@@ -137,7 +192,40 @@ func RequestMetricsMiddlewareWithConfig(config RequestMetricsMiddlewareConfig) e
 
 			httpRequestsCounter.WithLabelValues(status, req.Method, path).Inc()
 
+			if compression, ok := c.Get(ctxResponseCompressionKey).(responseCompression); ok && httpResponsesCompressionRatio != nil {
+				httpResponsesCompressionRatio.WithLabelValues(compression.encoding, req.Method, path).Observe(compression.ratio)
+			}
+
+			if result, ok := c.Get(ctxConditionalRequestKey).(string); ok && httpConditionalRequestsCounter != nil {
+				httpConditionalRequestsCounter.WithLabelValues(result, req.Method, path).Inc()
+			}
+
 			return err
 		}
 	}
 }
+
+// responseCompression is a response compression, reported with [ObserveResponseCompression].
+type responseCompression struct {
+	encoding string
+	ratio    float64
+}
+
+// ObserveResponseCompression reports the compression of a response (encoding, and compressed size / original size
+// ratio), to be observed by the [RequestMetricsMiddleware].
+func ObserveResponseCompression(c echo.Context, encoding string, ratio float64) {
+	c.Set(ctxResponseCompressionKey, responseCompression{
+		encoding: encoding,
+		ratio:    ratio,
+	})
+}
+
+// ObserveConditionalRequest reports the result of a conditional request (hit with a 304 response, or miss), to be
+// observed by the [RequestMetricsMiddleware].
+func ObserveConditionalRequest(c echo.Context, hit bool) {
+	if hit {
+		c.Set(ctxConditionalRequestKey, ConditionalRequestResultHit)
+	} else {
+		c.Set(ctxConditionalRequestKey, ConditionalRequestResultMiss)
+	}
+}
@@ -144,3 +144,83 @@ func TestRequestMetricsMiddlewareWithCustomOptions(t *testing.T) {
 	)
 	assert.NoError(t, err)
 }
+
+func TestRequestMetricsMiddlewareWithCompressionAndConditional(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewPedanticRegistry()
+
+	httpServer := echo.New()
+	httpServer.Use(middleware.RequestMetricsMiddlewareWithConfig(middleware.RequestMetricsMiddlewareConfig{
+		Registry:                registry,
+		Namespace:               "namespace",
+		Subsystem:               "subsystem",
+		NormalizeRequestPath:    true,
+		NormalizeResponseStatus: true,
+		CollectCompressionRatio: true,
+		CollectConditional:      true,
+	}))
+
+	httpServer.GET("/compressed/:id", func(c echo.Context) error {
+		middleware.ObserveResponseCompression(c, "gzip", 0.25)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	httpServer.GET("/conditional/:id", func(c echo.Context) error {
+		if c.Request().Header.Get("If-None-Match") == `"etag"` {
+			middleware.ObserveConditionalRequest(c, true)
+
+			return c.NoContent(http.StatusNotModified)
+		}
+
+		middleware.ObserveConditionalRequest(c, false)
+
+		return c.String(http.StatusOK, "ok")
+	})
+
+	serve := func(target string, ifNoneMatch string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		httpServer.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("/compressed/1", "")
+	serve("/compressed/2", "")
+	serve("/conditional/1", `"etag"`)
+	serve("/conditional/2", `"etag"`)
+	serve("/conditional/3", `"other"`)
+
+	expectedMetric := `
+		# HELP namespace_subsystem_http_server_conditional_requests_total Number of HTTP conditional requests, by result (hit with 304 response, or miss)
+		# TYPE namespace_subsystem_http_server_conditional_requests_total counter
+		namespace_subsystem_http_server_conditional_requests_total{method="GET",path="/conditional/:id",result="hit"} 2
+		namespace_subsystem_http_server_conditional_requests_total{method="GET",path="/conditional/:id",result="miss"} 1
+		# HELP namespace_subsystem_http_server_response_compression_ratio Ratio between the compressed and the original HTTP responses sizes
+		# TYPE namespace_subsystem_http_server_response_compression_ratio histogram
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.1"} 0
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.2"} 0
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.3"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.4"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.5"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.6"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.7"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.8"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="0.9"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="1"} 2
+		namespace_subsystem_http_server_response_compression_ratio_bucket{encoding="gzip",method="GET",path="/compressed/:id",le="+Inf"} 2
+		namespace_subsystem_http_server_response_compression_ratio_sum{encoding="gzip",method="GET",path="/compressed/:id"} 0.5
+		namespace_subsystem_http_server_response_compression_ratio_count{encoding="gzip",method="GET",path="/compressed/:id"} 2
+	`
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expectedMetric),
+		"namespace_subsystem_http_server_conditional_requests_total",
+		"namespace_subsystem_http_server_response_compression_ratio",
+	)
+	assert.NoError(t, err)
+}
@@ -1,31 +1,50 @@
 package httpserver
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 )
 
+// DefaultReadHeaderTimeout is the default server maximum duration for reading the request headers.
+const DefaultReadHeaderTimeout = 10 * time.Second
+
 // Options are options for the [HttpServerFactory] implementations.
 type Options struct {
-	Debug            bool
-	Banner           bool
-	Logger           echo.Logger
-	Binder           echo.Binder
-	JsonSerializer   echo.JSONSerializer
-	HttpErrorHandler echo.HTTPErrorHandler
-	Renderer         echo.Renderer
+	Debug             bool
+	Banner            bool
+	Logger            echo.Logger
+	Binder            echo.Binder
+	JsonSerializer    echo.JSONSerializer
+	HttpErrorHandler  echo.HTTPErrorHandler
+	Renderer          echo.Renderer
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	BodyLimit         string
+	H2C               bool
 }
 
 // DefaultHttpServerOptions are the default options used in the [DefaultHttpServerFactory].
 func DefaultHttpServerOptions() Options {
 	return Options{
-		Debug:            false,
-		Banner:           false,
-		Logger:           log.New("default"),
-		Binder:           &echo.DefaultBinder{},
-		JsonSerializer:   &echo.DefaultJSONSerializer{},
-		HttpErrorHandler: nil,
-		Renderer:         nil,
+		Debug:             false,
+		Banner:            false,
+		Logger:            log.New("default"),
+		Binder:            &echo.DefaultBinder{},
+		JsonSerializer:    &echo.DefaultJSONSerializer{},
+		HttpErrorHandler:  nil,
+		Renderer:          nil,
+		ReadTimeout:       0,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		WriteTimeout:      0,
+		IdleTimeout:       0,
+		MaxHeaderBytes:    0,
+		BodyLimit:         "",
+		H2C:               false,
 	}
 }
 
@@ -80,3 +99,52 @@ func WithRenderer(r echo.Renderer) HttpServerOption {
 		o.Renderer = r
 	}
 }
+
+// WithReadTimeout is used to specify the server maximum duration for reading the entire request, including the body.
+func WithReadTimeout(d time.Duration) HttpServerOption {
+	return func(o *Options) {
+		o.ReadTimeout = d
+	}
+}
+
+// WithReadHeaderTimeout is used to specify the server maximum duration for reading the request headers.
+func WithReadHeaderTimeout(d time.Duration) HttpServerOption {
+	return func(o *Options) {
+		o.ReadHeaderTimeout = d
+	}
+}
+
+// WithWriteTimeout is used to specify the server maximum duration before timing out writes of the response.
+func WithWriteTimeout(d time.Duration) HttpServerOption {
+	return func(o *Options) {
+		o.WriteTimeout = d
+	}
+}
+
+// WithIdleTimeout is used to specify the server maximum duration to wait for the next request on keep-alive connections.
+func WithIdleTimeout(d time.Duration) HttpServerOption {
+	return func(o *Options) {
+		o.IdleTimeout = d
+	}
+}
+
+// WithMaxHeaderBytes is used to specify the server maximum number of bytes read when parsing the request headers.
+func WithMaxHeaderBytes(b int) HttpServerOption {
+	return func(o *Options) {
+		o.MaxHeaderBytes = b
+	}
+}
+
+// WithBodyLimit is used to specify the server maximum request body size (ex: 4K, 2M, 1G).
+func WithBodyLimit(l string) HttpServerOption {
+	return func(o *Options) {
+		o.BodyLimit = l
+	}
+}
+
+// WithH2C is used to activate HTTP/2 without TLS (h2c) on the server, the TLS server negotiating HTTP/2 on its own.
+func WithH2C(h bool) HttpServerOption {
+	return func(o *Options) {
+		o.H2C = h
+	}
+}
@@ -2,6 +2,7 @@ package httpserver_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai/httpserver"
 	"github.com/labstack/echo/v4"
@@ -75,3 +76,26 @@ func TestWithRenderer(t *testing.T) {
 
 	assert.NotNil(t, opt.Renderer)
 }
+
+func TestWithServerLimits(t *testing.T) {
+	t.Parallel()
+
+	opt := httpserver.DefaultHttpServerOptions()
+	assert.Equal(t, httpserver.DefaultReadHeaderTimeout, opt.ReadHeaderTimeout)
+
+	httpserver.WithReadTimeout(1 * time.Second)(&opt)
+	httpserver.WithReadHeaderTimeout(2 * time.Second)(&opt)
+	httpserver.WithWriteTimeout(3 * time.Second)(&opt)
+	httpserver.WithIdleTimeout(4 * time.Second)(&opt)
+	httpserver.WithMaxHeaderBytes(1024)(&opt)
+	httpserver.WithBodyLimit("2M")(&opt)
+	httpserver.WithH2C(true)(&opt)
+
+	assert.Equal(t, 1*time.Second, opt.ReadTimeout)
+	assert.Equal(t, 2*time.Second, opt.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, opt.WriteTimeout)
+	assert.Equal(t, 4*time.Second, opt.IdleTimeout)
+	assert.Equal(t, 1024, opt.MaxHeaderBytes)
+	assert.Equal(t, "2M", opt.BodyLimit)
+	assert.True(t, opt.H2C)
+}
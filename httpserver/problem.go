@@ -0,0 +1,273 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ankorstore/yokai/log"
+	goerrors "github.com/go-errors/errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	DefaultProblemType         = "about:blank"
+)
+
+// ProblemDetails is a RFC 9457 problem details response.
+type ProblemDetails struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"`
+	RequestId string               `json:"requestId,omitempty"`
+	Errors    []ProblemDetailError `json:"errors,omitempty"`
+	Stack     string               `json:"stack,omitempty"`
+}
+
+// ProblemDetailError is a field level error of a [ProblemDetails].
+type ProblemDetailError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrorMapping maps an error to a problem type, title and HTTP status code.
+type ErrorMapping struct {
+	Type   string
+	Title  string
+	Status int
+}
+
+type errorMappingEntry struct {
+	matcher func(error) bool
+	mapping ErrorMapping
+}
+
+// ErrorMapper is a registry of [ErrorMapping], resolved in registration order.
+type ErrorMapper struct {
+	mutex   sync.RWMutex
+	entries []errorMappingEntry
+}
+
+// NewErrorMapper returns a new [ErrorMapper] instance.
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{}
+}
+
+// Map registers an [ErrorMapping] for errors matching the target error (with [errors.Is]).
+func (m *ErrorMapper) Map(target error, mapping ErrorMapping) *ErrorMapper {
+	return m.MapFunc(
+		func(err error) bool {
+			return errors.Is(err, target)
+		},
+		mapping,
+	)
+}
+
+// MapFunc registers an [ErrorMapping] for errors satisfying the provided matcher.
+func (m *ErrorMapper) MapFunc(matcher func(error) bool, mapping ErrorMapping) *ErrorMapper {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.entries = append(m.entries, errorMappingEntry{
+		matcher: matcher,
+		mapping: mapping,
+	})
+
+	return m
+}
+
+// Resolve returns the [ErrorMapping] of an error, and if one was found.
+// When not explicitly mapped, [validator.ValidationErrors] resolve to a 400 mapping.
+func (m *ErrorMapper) Resolve(err error) (ErrorMapping, bool) {
+	if err == nil {
+		return ErrorMapping{}, false
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, entry := range m.entries {
+		if entry.matcher(err) {
+			return normalizeErrorMapping(entry.mapping), true
+		}
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return normalizeErrorMapping(ErrorMapping{Status: http.StatusBadRequest}), true
+	}
+
+	return ErrorMapping{}, false
+}
+
+// ResolveStatus returns the HTTP status code and title of an error, and if a mapping was found.
+func (m *ErrorMapper) ResolveStatus(err error) (int, string, bool) {
+	mapping, ok := m.Resolve(err)
+
+	return mapping.Status, mapping.Title, ok
+}
+
+// MatchErrorType returns an [ErrorMapper] matcher for errors of type T (with [errors.As]).
+func MatchErrorType[T error]() func(error) bool {
+	return func(err error) bool {
+		var target T
+
+		return errors.As(err, &target)
+	}
+}
+
+// ProblemDetailsErrorHandler provides a [echo.HTTPErrorHandler] that outputs errors as RFC 9457 problem details.
+// Errors are mapped to problem types and status codes with an [ErrorMapper], and [validator.ValidationErrors] are
+// rendered as a list of field errors. It can also be configured to obfuscate the problem detail (to avoid to leak
+// sensitive details), and to add the error stack to the response.
+type ProblemDetailsErrorHandler struct {
+	mapper    *ErrorMapper
+	obfuscate bool
+	stack     bool
+}
+
+// NewProblemDetailsErrorHandler returns a new ProblemDetailsErrorHandler instance.
+func NewProblemDetailsErrorHandler(mapper *ErrorMapper, obfuscate bool, stack bool) *ProblemDetailsErrorHandler {
+	if mapper == nil {
+		mapper = NewErrorMapper()
+	}
+
+	return &ProblemDetailsErrorHandler{
+		mapper:    mapper,
+		obfuscate: obfuscate,
+		stack:     stack,
+	}
+}
+
+// Handle handles errors.
+func (h *ProblemDetailsErrorHandler) Handle() echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		logger := log.CtxLogger(c.Request().Context())
+
+		if c.Response().Committed {
+			return
+		}
+
+		problem := h.problem(err, c)
+
+		logger.Error().Err(err).Interface("problem", problem).Msg("error handler")
+
+		if h.obfuscate {
+			problem.Detail = http.StatusText(problem.Status)
+		}
+
+		var httpRespErr error
+		if c.Request().Method == http.MethodHead {
+			httpRespErr = c.NoContent(problem.Status)
+		} else {
+			var body []byte
+			body, httpRespErr = json.Marshal(problem)
+			if httpRespErr == nil {
+				httpRespErr = c.Blob(problem.Status, MIMEApplicationProblemJSON, body)
+			}
+		}
+
+		if httpRespErr != nil {
+			logger.Error().Err(httpRespErr).Msg("error handler failure")
+		}
+	}
+}
+
+func (h *ProblemDetailsErrorHandler) problem(err error, c echo.Context) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:      DefaultProblemType,
+		Status:    http.StatusInternalServerError,
+		Instance:  c.Request().URL.Path,
+		RequestId: CtxRequestId(c),
+	}
+
+	if err == nil {
+		problem.Title = http.StatusText(problem.Status)
+
+		return problem
+	}
+
+	problem.Detail = err.Error()
+
+	var httpError *echo.HTTPError
+	if errors.As(err, &httpError) {
+		if httpError.Internal != nil {
+			var internalHttpError *echo.HTTPError
+			if errors.As(httpError.Internal, &internalHttpError) {
+				httpError = internalHttpError
+			}
+		}
+
+		problem.Status = httpError.Code
+
+		switch m := httpError.Message.(type) {
+		case error:
+			problem.Detail = m.Error()
+		case string:
+			problem.Detail = m
+		}
+	}
+
+	if mapping, ok := h.mapper.Resolve(err); ok {
+		problem.Type = mapping.Type
+		problem.Title = mapping.Title
+		problem.Status = mapping.Status
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		problem.Errors = ProblemDetailErrors(validationErrors)
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	if h.stack {
+		problem.Stack = goerrors.New(err).ErrorStack()
+	}
+
+	return problem
+}
+
+// ProblemDetailErrors converts [validator.ValidationErrors] into a list of [ProblemDetailError], with field paths
+// relative to the validated struct.
+func ProblemDetailErrors(validationErrors validator.ValidationErrors) []ProblemDetailError {
+	problemErrors := make([]ProblemDetailError, 0, len(validationErrors))
+
+	for _, fieldError := range validationErrors {
+		field := fieldError.Namespace()
+		if _, path, ok := strings.Cut(field, "."); ok {
+			field = path
+		}
+
+		problemErrors = append(problemErrors, ProblemDetailError{
+			Field:   field,
+			Message: fieldError.Error(),
+		})
+	}
+
+	return problemErrors
+}
+
+func normalizeErrorMapping(mapping ErrorMapping) ErrorMapping {
+	if mapping.Status == 0 {
+		mapping.Status = http.StatusInternalServerError
+	}
+
+	if mapping.Type == "" {
+		mapping.Type = DefaultProblemType
+	}
+
+	if mapping.Title == "" {
+		mapping.Title = http.StatusText(mapping.Status)
+	}
+
+	return mapping
+}
@@ -0,0 +1,272 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankorstore/yokai/httpserver"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestNotFound = errors.New("resource not found")
+
+type testProblemError struct{}
+
+func (e *testProblemError) Error() string {
+	return "conflict error"
+}
+
+type testProblemRequest struct {
+	Name    string `validate:"required"`
+	Address struct {
+		City string `validate:"required"`
+	}
+}
+
+func problemTestServer(t *testing.T, handler echo.HandlerFunc, mapper *httpserver.ErrorMapper, obfuscate bool) (*echo.Echo, logtest.TestLogBuffer, context.Context) {
+	t.Helper()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	httpServer := echo.New()
+	httpServer.Logger = httpserver.NewEchoLogger(logger)
+	httpServer.HTTPErrorHandler = httpserver.NewProblemDetailsErrorHandler(mapper, obfuscate, false).Handle()
+
+	httpServer.GET("/test", handler)
+	httpServer.HEAD("/test", handler)
+
+	return httpServer, logBuffer, logger.WithContext(context.Background())
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) httpserver.ProblemDetails {
+	t.Helper()
+
+	assert.Equal(t, httpserver.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var problem httpserver.ProblemDetails
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+
+	return problem
+}
+
+func TestProblemDetailsErrorHandling(t *testing.T) {
+	t.Parallel()
+
+	httpServer, logBuffer, ctx := problemTestServer(
+		t,
+		func(c echo.Context) error {
+			return fmt.Errorf("custom error")
+		},
+		nil,
+		false,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(echo.HeaderXRequestID, "test-request-id")
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Internal Server Error", problem.Title)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "custom error", problem.Detail)
+	assert.Equal(t, "/test", problem.Instance)
+	assert.Empty(t, problem.Stack)
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":   "error",
+		"error":   "custom error",
+		"message": "error handler",
+	})
+}
+
+func TestProblemDetailsErrorHandlingWithHttpError(t *testing.T) {
+	t.Parallel()
+
+	httpServer, _, ctx := problemTestServer(
+		t,
+		func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusForbidden, "access denied")
+		},
+		nil,
+		false,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, "Forbidden", problem.Title)
+	assert.Equal(t, http.StatusForbidden, problem.Status)
+	assert.Equal(t, "access denied", problem.Detail)
+}
+
+func TestProblemDetailsErrorHandlingWithErrorMapper(t *testing.T) {
+	t.Parallel()
+
+	mapper := httpserver.NewErrorMapper().
+		Map(errTestNotFound, httpserver.ErrorMapping{
+			Type:   "https://example.com/problems/not-found",
+			Title:  "Resource not found",
+			Status: http.StatusNotFound,
+		}).
+		MapFunc(httpserver.MatchErrorType[*testProblemError](), httpserver.ErrorMapping{
+			Status: http.StatusConflict,
+		})
+
+	t.Run("mapped by value", func(t *testing.T) {
+		t.Parallel()
+
+		httpServer, _, ctx := problemTestServer(
+			t,
+			func(c echo.Context) error {
+				return fmt.Errorf("cannot find user: %w", errTestNotFound)
+			},
+			mapper,
+			false,
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req = req.WithContext(ctx)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		problem := decodeProblem(t, rec)
+		assert.Equal(t, "https://example.com/problems/not-found", problem.Type)
+		assert.Equal(t, "Resource not found", problem.Title)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "cannot find user: resource not found", problem.Detail)
+	})
+
+	t.Run("mapped by type", func(t *testing.T) {
+		t.Parallel()
+
+		httpServer, _, ctx := problemTestServer(
+			t,
+			func(c echo.Context) error {
+				return fmt.Errorf("cannot save user: %w", &testProblemError{})
+			},
+			mapper,
+			true,
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req = req.WithContext(ctx)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		problem := decodeProblem(t, rec)
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, "Conflict", problem.Title)
+		assert.Equal(t, "Conflict", problem.Detail)
+	})
+
+	t.Run("head request", func(t *testing.T) {
+		t.Parallel()
+
+		httpServer, _, ctx := problemTestServer(
+			t,
+			func(c echo.Context) error {
+				return errTestNotFound
+			},
+			mapper,
+			false,
+		)
+
+		req := httptest.NewRequest(http.MethodHead, "/test", nil)
+		req = req.WithContext(ctx)
+		rec := httptest.NewRecorder()
+		httpServer.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
+
+func TestProblemDetailsErrorHandlingWithValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	httpServer, _, ctx := problemTestServer(
+		t,
+		func(c echo.Context) error {
+			return fmt.Errorf("invalid request: %w", validator.New().Struct(testProblemRequest{}))
+		},
+		nil,
+		false,
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	problem := decodeProblem(t, rec)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Len(t, problem.Errors, 2)
+	assert.Equal(t, "Name", problem.Errors[0].Field)
+	assert.Contains(t, problem.Errors[0].Message, "'required' tag")
+	assert.Equal(t, "Address.City", problem.Errors[1].Field)
+}
+
+func TestProblemDetailsErrorHandlingWithStack(t *testing.T) {
+	t.Parallel()
+
+	httpServer := echo.New()
+	httpServer.HTTPErrorHandler = httpserver.NewProblemDetailsErrorHandler(nil, false, true).Handle()
+
+	httpServer.GET("/test", func(c echo.Context) error {
+		return fmt.Errorf("custom error")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	httpServer.ServeHTTP(rec, req)
+
+	problem := decodeProblem(t, rec)
+	assert.Contains(t, problem.Stack, "custom error")
+}
+
+func TestErrorMapperResolveStatus(t *testing.T) {
+	t.Parallel()
+
+	mapper := httpserver.NewErrorMapper().Map(errTestNotFound, httpserver.ErrorMapping{Status: http.StatusNotFound})
+
+	status, title, ok := mapper.ResolveStatus(errTestNotFound)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Not Found", title)
+
+	_, _, ok = mapper.ResolveStatus(errors.New("other"))
+	assert.False(t, ok)
+
+	_, _, ok = mapper.ResolveStatus(nil)
+	assert.False(t, ok)
+}